@@ -0,0 +1,306 @@
+package abc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrExceptionOffset means that an exception of a method body can not be
+// remapped. Its offsets do not match the start of any instruction
+var ErrExceptionOffset = errors.New("exception offset does not match an instruction")
+
+// ErrJumpTarget means that a jump can not be remapped. The Target of one of
+// its offset operands does not match the start of any instruction
+var ErrJumpTarget = errors.New("jump target does not match an instruction")
+
+// Assemble encodes instructions into code.
+// The Offset of the instructions is their offset in the code they were
+// decoded from: jump offsets are recomputed by matching the Target of
+// OperandOffset operands against it. An instruction inserted by the caller
+// should reuse the Offset of the instruction it is inserted before.
+// It fails with ErrJumpTarget when a Target does not match any instruction
+// or the end of the code, such as when the target instruction was removed.
+// The case count of lookupswitch is recomputed from its case offsets.
+// It returns the code and the new offset of every instruction
+func Assemble(instructions []Instruction) ([]byte, []uint32, error) {
+	offsets := make([]uint32, len(instructions))
+	var size uint32
+	for i, ins := range instructions {
+		offsets[i] = size
+		size += instructionSize(ins)
+	}
+	remap := remapOffsets(instructions, offsets)
+
+	// Branches may target the end of the code, which follows the
+	// instruction with the last previous offset
+	var last, end uint32
+	for _, ins := range instructions {
+		if ins.Offset >= last {
+			last, end = ins.Offset, ins.Offset+instructionSize(ins)
+		}
+	}
+	if _, found := remap[end]; !found {
+		remap[end] = size
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for i, ins := range instructions {
+		// Branches are relative to the next instruction, lookupswitch
+		// is relative to itself
+		base := offsets[i] + instructionSize(ins)
+		if ins.Opcode == OpLookupSwitch {
+			base = offsets[i]
+		}
+		if err := w.WriteUInt8(ins.Opcode); err != nil {
+			return nil, nil, err
+		}
+		for j, operand := range ins.Operands {
+			value := operand.Value
+			if operand.Kind == OperandOffset {
+				target, found := remap[operand.Target]
+				if !found {
+					return nil, nil, fmt.Errorf("instruction at offset %d: %w", ins.Offset, ErrJumpTarget)
+				}
+				value = int32(int64(target) - int64(base))
+			}
+			if ins.Opcode == OpLookupSwitch && j == 1 {
+				value = int32(len(ins.Operands) - 3)
+			}
+			if err := writeOperand(w, operand.Kind, value); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), offsets, nil
+}
+
+// remapOffsets maps the previous offset of the instructions to the new one.
+// The first instruction wins when several share the same previous offset
+func remapOffsets(instructions []Instruction, offsets []uint32) map[uint32]uint32 {
+	remap := make(map[uint32]uint32, len(instructions)+1)
+	for i := len(instructions) - 1; i >= 0; i-- {
+		remap[instructions[i].Offset] = offsets[i]
+	}
+	return remap
+}
+
+func instructionSize(ins Instruction) uint32 {
+	size := uint32(1)
+	for j, operand := range ins.Operands {
+		value := operand.Value
+		if ins.Opcode == OpLookupSwitch && j == 1 {
+			value = int32(len(ins.Operands) - 3)
+		}
+		size += operandSize(operand.Kind, value)
+	}
+	return size
+}
+
+func operandSize(kind OperandKind, value int32) uint32 {
+	switch kind {
+	case OperandByte, OperandUByte:
+		return 1
+	case OperandOffset:
+		return 3
+	case OperandShort:
+		value = int32(uint16(value))
+	}
+	size := uint32(1)
+	for v := uint32(value) >> 7; v != 0; v >>= 7 {
+		size++
+	}
+	return size
+}
+
+func writeOperand(w Writer, kind OperandKind, value int32) error {
+	switch kind {
+	case OperandByte:
+		return w.WriteInt8(int8(value))
+	case OperandUByte:
+		return w.WriteUInt8(uint8(value))
+	case OperandOffset:
+		return w.WriteS24(value)
+	case OperandShort:
+		return w.WriteU30(uint32(uint16(value)))
+	}
+	return w.WriteU30(uint32(value))
+}
+
+// SetCode assembles instructions into the code of body, see Assemble.
+// The exceptions of body are remapped like jump targets.
+// MaxStack, LocalCount and MaxScopeDepth are recomputed from the new code
+func (f *File) SetCode(body *MethodBody, instructions []Instruction) error {
+	code, offsets, err := Assemble(instructions)
+	if err != nil {
+		return err
+	}
+
+	remap := remapOffsets(instructions, offsets)
+	remap[uint32(len(body.Code))] = uint32(len(code))
+
+	exceptions := make([]ExceptionInfo, len(body.Exceptions))
+	for i, e := range body.Exceptions {
+		fields := []*uint32{&e.From, &e.To, &e.Target}
+		for _, field := range fields {
+			offset, found := remap[*field]
+			if !found {
+				return ErrExceptionOffset
+			}
+			*field = offset
+		}
+		exceptions[i] = e
+	}
+
+	handlers := make([]uint32, 0, len(exceptions))
+	for _, e := range exceptions {
+		handlers = append(handlers, e.Target)
+	}
+
+	maxStack, maxScope := f.analyzeStack(instructions, offsets, handlers)
+	body.Code = code
+	body.Exceptions = exceptions
+	body.MaxStack = maxStack
+	body.MaxScopeDepth = body.InitScopeDepth + maxScope
+	body.LocalCount = f.localCount(body.Method, instructions)
+	return nil
+}
+
+// runtimeParts returns the number of values a multiname pops from the stack
+func (cp *ConstantPool) runtimeParts(i uint32) int {
+	if i >= uint32(len(cp.Multinames)) {
+		return 0
+	}
+	switch cp.Multinames[i].Kind {
+	case KindRTQName, KindRTQNameA, KindMultinameL, KindMultinameLA:
+		return 1
+	case KindRTQNameL, KindRTQNameLA:
+		return 2
+	}
+	return 0
+}
+
+// stackEffect returns the number of values an instruction pops and pushes
+func (f *File) stackEffect(ins Instruction) (int, int) {
+	info := opcodes[ins.Opcode]
+	if info == nil {
+		return 0, 0
+	}
+	pops := info.pops
+	for _, operand := range ins.Operands {
+		switch operand.Kind {
+		case OperandArgCount:
+			pops += int(operand.Value)
+			if ins.Opcode == OpNewObject {
+				pops += int(operand.Value)
+			}
+		case OperandMultiname:
+			pops += f.ConstantPool.runtimeParts(uint32(operand.Value))
+		}
+	}
+	return pops, info.pushes
+}
+
+// analyzeStack follows every path of the code, from the entry point and
+// from the exception handlers, to find the maximum stack and scope depths.
+// offsets are the offsets of the instructions in the assembled code
+func (f *File) analyzeStack(instructions []Instruction, offsets []uint32, handlers []uint32) (uint32, uint32) {
+	type state struct {
+		index, stack, scope int
+	}
+	indices := make(map[uint32]int, len(offsets))
+	for i, offset := range offsets {
+		indices[offset] = i
+	}
+	targets := make(map[uint32]int, len(instructions))
+	for i := len(instructions) - 1; i >= 0; i-- {
+		targets[instructions[i].Offset] = i
+	}
+
+	visited := make([]bool, len(instructions))
+	work := []state{{0, 0, 0}}
+	for _, handler := range handlers {
+		// Handlers start with the exception on the stack and an empty scope
+		if index, found := indices[handler]; found {
+			work = append(work, state{index, 1, 0})
+		}
+	}
+
+	maxStack, maxScope := 0, 0
+	for len(work) > 0 {
+		s := work[len(work)-1]
+		work = work[:len(work)-1]
+		if s.index >= len(instructions) || visited[s.index] {
+			continue
+		}
+		visited[s.index] = true
+
+		ins := instructions[s.index]
+		pops, pushes := f.stackEffect(ins)
+		if s.stack -= pops; s.stack < 0 {
+			s.stack = 0
+		}
+		if s.stack += pushes; s.stack > maxStack {
+			maxStack = s.stack
+		}
+		switch ins.Opcode {
+		case OpPushScope, OpPushWith:
+			if s.scope++; s.scope > maxScope {
+				maxScope = s.scope
+			}
+		case OpPopScope:
+			if s.scope > 0 {
+				s.scope--
+			}
+		}
+
+		for _, operand := range ins.Operands {
+			if index, found := targets[operand.Target]; found && operand.Kind == OperandOffset {
+				work = append(work, state{index, s.stack, s.scope})
+			}
+		}
+		switch ins.Opcode {
+		case OpJump, OpLookupSwitch, OpThrow, OpReturnVoid, OpReturnValue:
+		default:
+			work = append(work, state{s.index + 1, s.stack, s.scope})
+		}
+	}
+	return uint32(maxStack), uint32(maxScope)
+}
+
+// localCount returns the number of registers used by the parameters of a
+// method and by its code
+func (f *File) localCount(method uint32, instructions []Instruction) uint32 {
+	var count uint32
+	if method < uint32(len(f.Methods)) {
+		m := f.Methods[method]
+		count = uint32(len(m.ParamTypes)) + 1
+		if m.Flags&(MethodNeedRest|MethodNeedArguments) != 0 {
+			count++
+		}
+	}
+
+	for _, ins := range instructions {
+		var register uint32
+		switch {
+		case ins.Opcode >= OpGetLocal0 && ins.Opcode <= OpGetLocal3:
+			register = uint32(ins.Opcode - OpGetLocal0)
+		case ins.Opcode >= OpSetLocal0 && ins.Opcode <= OpSetLocal3:
+			register = uint32(ins.Opcode - OpSetLocal0)
+		default:
+			for _, operand := range ins.Operands {
+				if operand.Kind == OperandRegister && uint32(operand.Value) > register {
+					register = uint32(operand.Value)
+				}
+			}
+		}
+		if register+1 > count {
+			count = register + 1
+		}
+	}
+	return count
+}
@@ -0,0 +1,131 @@
+package abc
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	instructions, err := Decode(codeBytes, nil)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	code, offsets, err := Assemble(instructions)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(code, codeBytes) {
+		t.Errorf("expected %#v, got %#v", codeBytes, code)
+	}
+	for i, ins := range instructions {
+		if offsets[i] != ins.Offset {
+			t.Errorf("expected %v, got %v", ins.Offset, offsets[i])
+		}
+	}
+
+	// Use a two bytes string index and drop a lookupswitch case
+	instructions[2].Operands[0].Value = 200
+	instructions[6].Operands = instructions[6].Operands[:3]
+	code, _, err = Assemble(instructions)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := []byte{
+		0xd0,
+		0x30,
+		0x2c, 0xc8, 0x01,
+		0x11, 0x03, 0x00, 0x00,
+		0x24, 0xff,
+		0x29,
+		0x1b, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00,
+		0x46, 0x01, 0x01,
+		0x47,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Errorf("expected %#v, got %#v", expected, code)
+	}
+
+	// Remove the target of the jump
+	jump := instructions[3]
+	if _, _, err = Assemble([]Instruction{jump}); !errors.Is(err, ErrJumpTarget) {
+		t.Errorf("expected ErrJumpTarget, got %v", err)
+	}
+
+	// Jump to the end of the code, with a nop inserted before returnvoid
+	instructions, err = Decode([]byte{0x10, 0x01, 0x00, 0x00, 0x47}, nil)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	instructions = append(instructions[:1], Instruction{Offset: 4, Opcode: OpNop}, instructions[1])
+	code, _, err = Assemble(instructions)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if expected := []byte{0x10, 0x02, 0x00, 0x00, 0x02, 0x47}; !bytes.Equal(code, expected) {
+		t.Errorf("expected %#v, got %#v", expected, code)
+	}
+}
+
+func TestSetCode(t *testing.T) {
+	f := File{
+		ConstantPool: ConstantPool{
+			Multinames: []Multiname{{}, {Kind: KindQName}, {Kind: KindRTQNameL}},
+		},
+		Methods: []MethodInfo{{ParamTypes: []uint32{0}, Flags: MethodNeedRest}},
+	}
+	body := MethodBody{
+		InitScopeDepth: 1,
+		Code: []byte{
+			0xd0,
+			0x30,
+			0x24, 0x01,
+			0x10, 0x01, 0x00, 0x00,
+			0x02,
+			0x2c, 0x01,
+			0x2c, 0x01,
+			0x66, 0x02,
+			0x29,
+			0x29,
+			0x47,
+			0x2a,
+			0x63, 0x05,
+			0x03,
+		},
+		Exceptions: []ExceptionInfo{{0, 18, 18, 1, 0}},
+	}
+
+	instructions, err := Decode(body.Code, nil)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	// Remove the nop, so offsets after it move
+	instructions = append(instructions[:4], instructions[5:]...)
+	if err = f.SetCode(&body, instructions); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	if body.MaxStack != 3 {
+		t.Errorf("expected 3, got %v", body.MaxStack)
+	}
+	if body.MaxScopeDepth != 2 {
+		t.Errorf("expected 2, got %v", body.MaxScopeDepth)
+	}
+	if body.LocalCount != 6 {
+		t.Errorf("expected 6, got %v", body.LocalCount)
+	}
+	correct := []ExceptionInfo{{0, 17, 17, 1, 0}}
+	if !reflect.DeepEqual(body.Exceptions, correct) {
+		t.Errorf("expected %v, got %v", correct, body.Exceptions)
+	}
+	if len(body.Code) != 21 || body.Code[5] != 0x00 {
+		t.Errorf("expected the jump to be recomputed, got %#v", body.Code)
+	}
+
+	body.Exceptions = []ExceptionInfo{{1, 3, 18, 0, 0}}
+	if err = f.SetCode(&body, instructions); err != ErrExceptionOffset {
+		t.Errorf("expected ErrExceptionOffset, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+// Package abc contains utilities to read ActionScript Byte Code files,
+// as found in the ABCData of a DoABC tag.
+// It provides a Parser to parse an entire ABC file into typed structures.
+// It also provides a Reader implementation that can read the variable length
+// data types defined by the specification
+// (see http://wwwimages.adobe.com/content/dam/Adobe/en/devnet/actionscript/articles/avm2overview.pdf)
+package abc
@@ -0,0 +1,202 @@
+package abc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type dumper struct {
+	w *bufio.Writer
+	f *File
+}
+
+// Dump writes a textual representation of an entire ABC file, in the
+// spirit of abcdump: scripts, classes with their traits, and every method
+// with its disassembled body
+func Dump(w io.Writer, f *File) error {
+	d := &dumper{bufio.NewWriter(w), f}
+	d.dump()
+	return d.w.Flush()
+}
+
+// Disassemble writes the disassembled code of a method body.
+// Jump targets are replaced by labels
+func Disassemble(w io.Writer, f *File, body *MethodBody) error {
+	d := &dumper{bufio.NewWriter(w), f}
+	err := d.disassemble(body, "")
+	if flushErr := d.w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+func (d *dumper) printf(format string, args ...interface{}) {
+	fmt.Fprintf(d.w, format, args...)
+}
+
+func (d *dumper) dump() {
+	cp := &d.f.ConstantPool
+	d.printf("abc version %d.%d\n", d.f.MajorVersion, d.f.MinorVersion)
+
+	for i, script := range d.f.Scripts {
+		d.printf("\nscript %d init method %d\n", i, script.Init)
+		d.traits(script.Traits, "  ", false)
+	}
+
+	for i, instance := range d.f.Instances {
+		d.printf("\n%s %s", instanceKind(instance.Flags), cp.MultinameName(instance.Name))
+		if instance.SuperName != 0 {
+			d.printf(" extends %s", cp.MultinameName(instance.SuperName))
+		}
+		if len(instance.Interfaces) > 0 {
+			var names []string
+			for _, itf := range instance.Interfaces {
+				names = append(names, cp.MultinameName(itf))
+			}
+			d.printf(" implements %s", strings.Join(names, ", "))
+		}
+		d.printf("\n  instance init method %d\n", instance.IInit)
+		d.traits(instance.Traits, "  ", false)
+		if i < len(d.f.Classes) {
+			d.printf("  class init method %d\n", d.f.Classes[i].CInit)
+			d.traits(d.f.Classes[i].Traits, "  ", true)
+		}
+	}
+
+	bodies := make(map[uint32]*MethodBody, len(d.f.MethodBodies))
+	for i := range d.f.MethodBodies {
+		bodies[d.f.MethodBodies[i].Method] = &d.f.MethodBodies[i]
+	}
+	for i := range d.f.Methods {
+		d.printf("\nmethod %d %s\n", i, d.signature(uint32(i)))
+		body, found := bodies[uint32(i)]
+		if !found {
+			continue
+		}
+		d.printf("  maxStack %d localCount %d initScopeDepth %d maxScopeDepth %d\n",
+			body.MaxStack, body.LocalCount, body.InitScopeDepth, body.MaxScopeDepth)
+		if err := d.disassemble(body, "  "); err != nil {
+			d.printf("  ; %v\n", err)
+		}
+	}
+}
+
+func instanceKind(flags uint8) string {
+	if flags&ClassInterface != 0 {
+		return "interface"
+	}
+	if flags&ClassFinal != 0 {
+		return "final class"
+	}
+	return "class"
+}
+
+// signature returns the name and the prototype of a method
+func (d *dumper) signature(method uint32) string {
+	if method >= uint32(len(d.f.Methods)) {
+		return "#" + strconv.FormatUint(uint64(method), 10)
+	}
+	cp := &d.f.ConstantPool
+	m := d.f.Methods[method]
+	var params []string
+	for _, param := range m.ParamTypes {
+		params = append(params, cp.MultinameName(param))
+	}
+	if m.Flags&MethodNeedRest != 0 {
+		params = append(params, "...rest")
+	}
+	return fmt.Sprintf("%s(%s):%s", cp.string(m.Name), strings.Join(params, ", "), cp.MultinameName(m.ReturnType))
+}
+
+func (d *dumper) traits(traits []Trait, indent string, static bool) {
+	cp := &d.f.ConstantPool
+	prefix := indent
+	if static {
+		prefix += "static "
+	}
+	for _, t := range traits {
+		name := cp.MultinameName(t.Name)
+		switch t.Kind {
+		case TraitSlot, TraitConst:
+			keyword := "var"
+			if t.Kind == TraitConst {
+				keyword = "const"
+			}
+			d.printf("%s%s %s:%s slot %d\n", prefix, keyword, name, cp.MultinameName(t.TypeName), t.SlotID)
+		case TraitMethod:
+			d.printf("%sfunction %s method %d\n", prefix, name, t.Method)
+		case TraitGetter:
+			d.printf("%sfunction get %s method %d\n", prefix, name, t.Method)
+		case TraitSetter:
+			d.printf("%sfunction set %s method %d\n", prefix, name, t.Method)
+		case TraitFunction:
+			d.printf("%sfunction %s method %d slot %d\n", prefix, name, t.Method, t.SlotID)
+		case TraitClass:
+			d.printf("%sclass %s class %d slot %d\n", prefix, name, t.Class, t.SlotID)
+		}
+	}
+}
+
+func (d *dumper) disassemble(body *MethodBody, indent string) error {
+	instructions, decodeErr := Decode(body.Code, &d.f.ConstantPool)
+
+	// Labels are numbered in code order, only targets starting an
+	// instruction get one
+	targets := make(map[uint32]bool)
+	for _, ins := range instructions {
+		for _, operand := range ins.Operands {
+			if operand.Kind == OperandOffset {
+				targets[operand.Target] = true
+			}
+		}
+	}
+	labels := make(map[uint32]int)
+	for _, ins := range instructions {
+		if targets[ins.Offset] {
+			labels[ins.Offset] = len(labels)
+		}
+	}
+
+	for _, ins := range instructions {
+		if label, found := labels[ins.Offset]; found {
+			d.printf("%sL%d:\n", indent, label)
+		}
+		d.printf("%s%-6d %s", indent, ins.Offset, ins.Name())
+		for j, operand := range ins.Operands {
+			if j == 0 {
+				d.printf(" ")
+			} else {
+				d.printf(", ")
+			}
+			d.printf("%s", d.operand(operand, labels))
+		}
+		d.printf("\n")
+	}
+
+	for _, e := range body.Exceptions {
+		d.printf("%sexception from %d to %d target %d type %s name %s\n", indent,
+			e.From, e.To, e.Target, d.f.ConstantPool.MultinameName(e.ExcType), d.f.ConstantPool.MultinameName(e.VarName))
+	}
+	return decodeErr
+}
+
+func (d *dumper) operand(operand Operand, labels map[uint32]int) string {
+	switch operand.Kind {
+	case OperandOffset:
+		if label, found := labels[operand.Target]; found {
+			return "L" + strconv.Itoa(label)
+		}
+		return fmt.Sprintf("%+d", operand.Value)
+	case OperandMethod:
+		return d.signature(uint32(operand.Value))
+	case OperandRegister:
+		return "r" + strconv.Itoa(int(operand.Value))
+	}
+	if operand.Resolved != "" {
+		return operand.Resolved
+	}
+	return strconv.Itoa(int(operand.Value))
+}
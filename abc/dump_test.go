@@ -0,0 +1,61 @@
+package abc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	var buf bytes.Buffer
+	body := MethodBody{Code: codeBytes, Exceptions: []ExceptionInfo{{0, 8, 10, 1, 0}}}
+	if err := Disassemble(&buf, &abcFile, &body); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := `0      getlocal_0
+1      pushscope
+2      pushstring "Main"
+4      iftrue L2
+L0:
+8      pushbyte -1
+L1:
+10     pop
+L2:
+11     lookupswitch L1, 1, L2, L0
+22     callproperty Main, 1
+25     returnvoid
+exception from 0 to 8 target 10 type Main name *
+`
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+}
+
+func TestDump(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Dump(&buf, &abcFile); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := `abc version 46.16
+
+script 0 init method 0
+  class Main class 0 slot 1
+
+class Main extends {}::Sprite implements Main.<{}::Sprite>
+  instance init method 0
+  var Main:{}::Sprite slot 1
+  class init method 0
+  static function Main method 0
+
+method 0 Main({}::Sprite):*
+  maxStack 1 localCount 2 initScopeDepth 0 maxScopeDepth 1
+  0      getlocal_0
+  1      pushscope
+  2      returnvoid
+  exception from 0 to 1 target 2 type * name *
+`
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+}
@@ -0,0 +1,209 @@
+package abc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// ErrUnknownOpcode means that the code of a method body is malformed.
+// An opcode is not defined by the specification
+var ErrUnknownOpcode = errors.New("unknown opcode")
+
+// OperandKind represents the type of an instruction operand
+type OperandKind uint8
+
+// These represent possible operand kinds
+const (
+	OperandByte      OperandKind = iota // Signed byte immediate
+	OperandUByte                        // Unsigned byte immediate
+	OperandShort                        // u30 holding a signed short immediate
+	OperandU30                          // u30 immediate, such as a slot or a line number
+	OperandRegister                     // u30 local register
+	OperandArgCount                     // u30 argument count
+	OperandInt                          // u30 index in ConstantPool.Integers
+	OperandUInt                         // u30 index in ConstantPool.UIntegers
+	OperandDouble                       // u30 index in ConstantPool.Doubles
+	OperandString                       // u30 index in ConstantPool.Strings
+	OperandNamespace                    // u30 index in ConstantPool.Namespaces
+	OperandMultiname                    // u30 index in ConstantPool.Multinames
+	OperandMethod                       // u30 index in File.Methods
+	OperandClass                        // u30 index in File.Classes
+	OperandException                    // u30 index in MethodBody.Exceptions
+	OperandOffset                       // s24 jump offset
+)
+
+// Instruction represents a decoded AVM2 instruction
+type Instruction struct {
+	Offset   uint32 // Offset is the byte offset of the instruction in the code
+	Opcode   uint8
+	Operands []Operand
+}
+
+// Operand represents an instruction operand
+type Operand struct {
+	Kind OperandKind
+	// Value is the immediate, the index or the jump offset as stored in the code
+	Value int32
+	// Target is the absolute byte offset an OperandOffset jumps to
+	Target uint32
+	// Resolved is the textual value of a constant pool operand,
+	// set when the code is decoded with a ConstantPool
+	Resolved string
+}
+
+// Name returns the mnemonic of the instruction
+func (i Instruction) Name() string {
+	if info := opcodes[i.Opcode]; info != nil {
+		return info.name
+	}
+	return "op_" + strconv.FormatUint(uint64(i.Opcode), 16)
+}
+
+// Decode decodes the code of a method body into instructions.
+// Operands are resolved against cp when it is not nil.
+// On error, the instructions decoded so far are returned
+func Decode(code []byte, cp *ConstantPool) ([]Instruction, error) {
+	src := bytes.NewReader(code)
+	r := NewReader(src)
+
+	var instructions []Instruction
+	for src.Len() > 0 {
+		offset := uint32(len(code) - src.Len())
+		opcode, err := r.ReadUInt8()
+		if err != nil {
+			return instructions, err
+		}
+		info := opcodes[opcode]
+		if info == nil {
+			return instructions, ErrUnknownOpcode
+		}
+
+		ins := Instruction{Offset: offset, Opcode: opcode}
+		var operands []Operand
+		if opcode == OpLookupSwitch {
+			operands, err = decodeLookupSwitch(r)
+		} else {
+			operands, err = decodeOperands(r, info.operands)
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return instructions, err
+		}
+		ins.Operands = operands
+
+		// Branches are relative to the next instruction, lookupswitch
+		// is relative to itself
+		base := uint32(len(code) - src.Len())
+		if opcode == OpLookupSwitch {
+			base = offset
+		}
+		for i := range operands {
+			if operands[i].Kind == OperandOffset {
+				operands[i].Target = uint32(int64(base) + int64(operands[i].Value))
+			}
+		}
+
+		instructions = append(instructions, ins)
+	}
+
+	if cp != nil {
+		for i := range instructions {
+			for j := range instructions[i].Operands {
+				resolveOperand(cp, &instructions[i].Operands[j])
+			}
+		}
+	}
+	return instructions, nil
+}
+
+func decodeOperands(r Reader, kinds []OperandKind) ([]Operand, error) {
+	var operands []Operand
+	for _, kind := range kinds {
+		var value int32
+		switch kind {
+		case OperandByte:
+			v, err := r.ReadInt8()
+			if err != nil {
+				return nil, err
+			}
+			value = int32(v)
+		case OperandUByte:
+			v, err := r.ReadUInt8()
+			if err != nil {
+				return nil, err
+			}
+			value = int32(v)
+		case OperandShort:
+			v, err := r.ReadU30()
+			if err != nil {
+				return nil, err
+			}
+			value = int32(int16(v))
+		case OperandOffset:
+			v, err := r.ReadS24()
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		default:
+			v, err := r.ReadU30()
+			if err != nil {
+				return nil, err
+			}
+			value = int32(v)
+		}
+		operands = append(operands, Operand{Kind: kind, Value: value})
+	}
+	return operands, nil
+}
+
+// decodeLookupSwitch decodes the default offset, the case count
+// and the case count + 1 case offsets
+func decodeLookupSwitch(r Reader) ([]Operand, error) {
+	operands, err := decodeOperands(r, []OperandKind{OperandOffset, OperandU30})
+	if err != nil {
+		return nil, err
+	}
+	for i := int32(0); i <= operands[1].Value; i++ {
+		cases, err := decodeOperands(r, []OperandKind{OperandOffset})
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, cases...)
+	}
+	return operands, nil
+}
+
+func resolveOperand(cp *ConstantPool, operand *Operand) {
+	index := uint32(operand.Value)
+	switch operand.Kind {
+	case OperandInt:
+		if index < uint32(len(cp.Integers)) {
+			operand.Resolved = strconv.FormatInt(int64(cp.Integers[index]), 10)
+		}
+	case OperandUInt:
+		if index < uint32(len(cp.UIntegers)) {
+			operand.Resolved = strconv.FormatUint(uint64(cp.UIntegers[index]), 10)
+		}
+	case OperandDouble:
+		if index < uint32(len(cp.Doubles)) {
+			operand.Resolved = strconv.FormatFloat(cp.Doubles[index], 'g', -1, 64)
+		}
+	case OperandString:
+		if index < uint32(len(cp.Strings)) {
+			operand.Resolved = strconv.Quote(cp.Strings[index])
+		}
+	case OperandNamespace:
+		if index < uint32(len(cp.Namespaces)) {
+			operand.Resolved = cp.NamespaceName(index)
+		}
+	case OperandMultiname:
+		if index < uint32(len(cp.Multinames)) {
+			operand.Resolved = cp.MultinameName(index)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package abc
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+var codeBytes = []byte{
+	0xd0,
+	0x30,
+	0x2c, 0x01,
+	0x11, 0x03, 0x00, 0x00,
+	0x24, 0xff,
+	0x29,
+	0x1b, 0xff, 0xff, 0xff, 0x01, 0x00, 0x00, 0x00, 0xfd, 0xff, 0xff,
+	0x46, 0x01, 0x01,
+	0x47,
+}
+
+func TestDecode(t *testing.T) {
+	instructions, err := Decode(codeBytes, &abcFile.ConstantPool)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	correct := []Instruction{
+		{0, OpGetLocal0, nil},
+		{1, OpPushScope, nil},
+		{2, OpPushString, []Operand{{OperandString, 1, 0, `"Main"`}}},
+		{4, OpIfTrue, []Operand{{OperandOffset, 3, 11, ""}}},
+		{8, OpPushByte, []Operand{{OperandByte, -1, 0, ""}}},
+		{10, OpPop, nil},
+		{11, OpLookupSwitch, []Operand{
+			{OperandOffset, -1, 10, ""},
+			{OperandU30, 1, 0, ""},
+			{OperandOffset, 0, 11, ""},
+			{OperandOffset, -3, 8, ""},
+		}},
+		{22, OpCallProperty, []Operand{{OperandMultiname, 1, 0, "Main"}, {OperandArgCount, 1, 0, ""}}},
+		{25, OpReturnVoid, nil},
+	}
+	if !reflect.DeepEqual(instructions, correct) {
+		t.Errorf("expected %v, got %v", correct, instructions)
+	}
+	if name := instructions[6].Name(); name != "lookupswitch" {
+		t.Errorf("expected lookupswitch, got %v", name)
+	}
+
+	instructions, err = Decode(codeBytes[:3], nil)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if len(instructions) != 2 {
+		t.Errorf("expected 2 instructions, got %v", len(instructions))
+	}
+
+	if _, err = Decode([]byte{0x02, 0xff}, nil); err != ErrUnknownOpcode {
+		t.Errorf("expected ErrUnknownOpcode, got %v", err)
+	}
+}
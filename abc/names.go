@@ -0,0 +1,102 @@
+package abc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// string returns the string at index i, or a placeholder when i is out of range
+func (cp *ConstantPool) string(i uint32) string {
+	if i < uint32(len(cp.Strings)) {
+		return cp.Strings[i]
+	}
+	return "#" + strconv.FormatUint(uint64(i), 10)
+}
+
+// NamespaceName returns the textual representation of a namespace.
+// Private namespaces are named "private"
+func (cp *ConstantPool) NamespaceName(i uint32) string {
+	if i >= uint32(len(cp.Namespaces)) {
+		return "#" + strconv.FormatUint(uint64(i), 10)
+	}
+	ns := cp.Namespaces[i]
+	if ns.Kind == KindPrivateNs {
+		return "private"
+	}
+	return cp.string(ns.Name)
+}
+
+// NsSetName returns the textual representation of a namespace set,
+// such as "{flash.display,flash.events}"
+func (cp *ConstantPool) NsSetName(i uint32) string {
+	if i >= uint32(len(cp.NsSets)) {
+		return "#" + strconv.FormatUint(uint64(i), 10)
+	}
+	var names []string
+	for _, ns := range cp.NsSets[i] {
+		names = append(names, cp.NamespaceName(ns))
+	}
+	return "{" + strings.Join(names, ",") + "}"
+}
+
+// MultinameName returns the textual representation of a multiname,
+// such as "flash.display::Sprite" or "__AS3__.vec::Vector.<int>".
+// Index 0 is the any name "*", names resolved at runtime are written "[rt]"
+func (cp *ConstantPool) MultinameName(i uint32) string {
+	budget := maxMultinameName
+	return cp.multinameName(i, make(map[uint32]bool), &budget)
+}
+
+// maxMultinameName bounds the work spent rendering a multiname, counted in
+// multinames and in characters
+const maxMultinameName = 4096
+
+// multinameName returns the textual representation of a multiname.
+// A TypeName referring to a multiname being rendered, or exhausting the
+// budget, such as deeply nested type parameters, is written "#i"
+func (cp *ConstantPool) multinameName(i uint32, rendering map[uint32]bool, budget *int) string {
+	if i == 0 {
+		return "*"
+	}
+	*budget--
+	if i >= uint32(len(cp.Multinames)) || rendering[i] || *budget < 0 {
+		return "#" + strconv.FormatUint(uint64(i), 10)
+	}
+	var name string
+	m := cp.Multinames[i]
+	switch m.Kind {
+	default:
+		return "#" + strconv.FormatUint(uint64(i), 10)
+	case KindQName, KindQNameA:
+		name = qualify(cp.NamespaceName(m.Namespace), cp.string(m.Name))
+	case KindRTQName, KindRTQNameA:
+		name = qualify("[rt]", cp.string(m.Name))
+	case KindRTQNameL, KindRTQNameLA:
+		name = "[rt]::[rt]"
+	case KindMultiname, KindMultinameA:
+		name = qualify(cp.NsSetName(m.NsSet), cp.string(m.Name))
+	case KindMultinameL, KindMultinameLA:
+		name = qualify(cp.NsSetName(m.NsSet), "[rt]")
+	case KindTypeName:
+		rendering[i] = true
+		defer delete(rendering, i)
+		var params []string
+		for _, param := range m.Params {
+			params = append(params, cp.multinameName(param, rendering, budget))
+		}
+		name = cp.multinameName(m.QName, rendering, budget) + ".<" + strings.Join(params, ",") + ">"
+		if *budget < 0 {
+			return "#" + strconv.FormatUint(uint64(i), 10)
+		}
+		return name
+	}
+	*budget -= len(name)
+	return name
+}
+
+func qualify(ns, name string) string {
+	if ns == "" {
+		return name
+	}
+	return ns + "::" + name
+}
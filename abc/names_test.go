@@ -0,0 +1,77 @@
+package abc
+
+import "testing"
+
+func TestMultinameName(t *testing.T) {
+	cp := ConstantPool{
+		Strings: []string{"", "flash.display", "Sprite", "int", "__AS3__.vec", "Vector"},
+		Namespaces: []Namespace{
+			{},
+			{KindPackageNamespace, 1},
+			{KindPrivateNs, 0},
+			{KindPackageNamespace, 0},
+			{KindPackageNamespace, 4},
+		},
+		NsSets: []NsSet{nil, {1, 3}},
+		Multinames: []Multiname{
+			{},
+			{Kind: KindQName, Namespace: 1, Name: 2},
+			{Kind: KindQName, Namespace: 2, Name: 3},
+			{Kind: KindMultiname, NsSet: 1, Name: 2},
+			{Kind: KindMultinameL, NsSet: 1},
+			{Kind: KindRTQNameL},
+			{Kind: KindQName, Namespace: 4, Name: 5},
+			{Kind: KindQName, Namespace: 3, Name: 3},
+			{Kind: KindTypeName, QName: 6, Params: []uint32{7}},
+		},
+	}
+	expected := []string{
+		"*",
+		"flash.display::Sprite",
+		"private::int",
+		"{flash.display,}::Sprite",
+		"{flash.display,}::[rt]",
+		"[rt]::[rt]",
+		"__AS3__.vec::Vector",
+		"int",
+		"__AS3__.vec::Vector.<int>",
+		"#9",
+	}
+	for i, name := range expected {
+		if got := cp.MultinameName(uint32(i)); got != name {
+			t.Errorf("expected %v, got %v", name, got)
+		}
+	}
+}
+
+func TestMultinameNameCycle(t *testing.T) {
+	cp := ConstantPool{
+		Multinames: []Multiname{
+			{},
+			{Kind: KindTypeName, QName: 1},
+			{Kind: KindTypeName, QName: 3, Params: []uint32{2}},
+			{Kind: KindTypeName, QName: 2, Params: []uint32{0, 0}},
+		},
+	}
+	expected := []string{"*", "#1.<>", "#2.<*,*>.<#2>", "#3.<#2>.<*,*>"}
+	for i, name := range expected {
+		if got := cp.MultinameName(uint32(i)); got != name {
+			t.Errorf("expected %v, got %v", name, got)
+		}
+	}
+}
+
+func TestMultinameNameNesting(t *testing.T) {
+	// Each TypeName uses the previous one twice, its name doubles at
+	// every level
+	cp := ConstantPool{Multinames: []Multiname{{}, {Kind: KindTypeName}}}
+	for i := 2; i <= 40; i++ {
+		cp.Multinames = append(cp.Multinames, Multiname{Kind: KindTypeName, QName: 1, Params: []uint32{uint32(i - 1), uint32(i - 1)}})
+	}
+	if got := cp.MultinameName(40); got != "#40" {
+		t.Errorf("expected #40, got %v", got)
+	}
+	if got := cp.MultinameName(2); got != "*.<>.<*.<>,*.<>>" {
+		t.Errorf("expected *.<>.<*.<>,*.<>>, got %v", got)
+	}
+}
@@ -0,0 +1,351 @@
+package abc
+
+// These represent the opcodes of the AVM2 instructions
+const (
+	OpBkpt           = 0x01
+	OpNop            = 0x02
+	OpThrow          = 0x03
+	OpGetSuper       = 0x04
+	OpSetSuper       = 0x05
+	OpDxns           = 0x06
+	OpDxnsLate       = 0x07
+	OpKill           = 0x08
+	OpLabel          = 0x09
+	OpIfNlt          = 0x0c
+	OpIfNle          = 0x0d
+	OpIfNgt          = 0x0e
+	OpIfNge          = 0x0f
+	OpJump           = 0x10
+	OpIfTrue         = 0x11
+	OpIfFalse        = 0x12
+	OpIfEq           = 0x13
+	OpIfNe           = 0x14
+	OpIfLt           = 0x15
+	OpIfLe           = 0x16
+	OpIfGt           = 0x17
+	OpIfGe           = 0x18
+	OpIfStrictEq     = 0x19
+	OpIfStrictNe     = 0x1a
+	OpLookupSwitch   = 0x1b
+	OpPushWith       = 0x1c
+	OpPopScope       = 0x1d
+	OpNextName       = 0x1e
+	OpHasNext        = 0x1f
+	OpPushNull       = 0x20
+	OpPushUndefined  = 0x21
+	OpNextValue      = 0x23
+	OpPushByte       = 0x24
+	OpPushShort      = 0x25
+	OpPushTrue       = 0x26
+	OpPushFalse      = 0x27
+	OpPushNaN        = 0x28
+	OpPop            = 0x29
+	OpDup            = 0x2a
+	OpSwap           = 0x2b
+	OpPushString     = 0x2c
+	OpPushInt        = 0x2d
+	OpPushUInt       = 0x2e
+	OpPushDouble     = 0x2f
+	OpPushScope      = 0x30
+	OpPushNamespace  = 0x31
+	OpHasNext2       = 0x32
+	OpLi8            = 0x35
+	OpLi16           = 0x36
+	OpLi32           = 0x37
+	OpLf32           = 0x38
+	OpLf64           = 0x39
+	OpSi8            = 0x3a
+	OpSi16           = 0x3b
+	OpSi32           = 0x3c
+	OpSf32           = 0x3d
+	OpSf64           = 0x3e
+	OpNewFunction    = 0x40
+	OpCall           = 0x41
+	OpConstruct      = 0x42
+	OpCallMethod     = 0x43
+	OpCallStatic     = 0x44
+	OpCallSuper      = 0x45
+	OpCallProperty   = 0x46
+	OpReturnVoid     = 0x47
+	OpReturnValue    = 0x48
+	OpConstructSuper = 0x49
+	OpConstructProp  = 0x4a
+	OpCallPropLex    = 0x4c
+	OpCallSuperVoid  = 0x4e
+	OpCallPropVoid   = 0x4f
+	OpSxi1           = 0x50
+	OpSxi8           = 0x51
+	OpSxi16          = 0x52
+	OpApplyType      = 0x53
+	OpNewObject      = 0x55
+	OpNewArray       = 0x56
+	OpNewActivation  = 0x57
+	OpNewClass       = 0x58
+	OpGetDescendants = 0x59
+	OpNewCatch       = 0x5a
+	OpFindPropStrict = 0x5d
+	OpFindProperty   = 0x5e
+	OpFindDef        = 0x5f
+	OpGetLex         = 0x60
+	OpSetProperty    = 0x61
+	OpGetLocal       = 0x62
+	OpSetLocal       = 0x63
+	OpGetGlobalScope = 0x64
+	OpGetScopeObject = 0x65
+	OpGetProperty    = 0x66
+	OpGetOuterScope  = 0x67
+	OpInitProperty   = 0x68
+	OpDeleteProperty = 0x6a
+	OpGetSlot        = 0x6c
+	OpSetSlot        = 0x6d
+	OpGetGlobalSlot  = 0x6e
+	OpSetGlobalSlot  = 0x6f
+	OpConvertS       = 0x70
+	OpEscXElem       = 0x71
+	OpEscXAttr       = 0x72
+	OpConvertI       = 0x73
+	OpConvertU       = 0x74
+	OpConvertD       = 0x75
+	OpConvertB       = 0x76
+	OpConvertO       = 0x77
+	OpCheckFilter    = 0x78
+	OpCoerce         = 0x80
+	OpCoerceB        = 0x81
+	OpCoerceA        = 0x82
+	OpCoerceI        = 0x83
+	OpCoerceD        = 0x84
+	OpCoerceS        = 0x85
+	OpAsType         = 0x86
+	OpAsTypeLate     = 0x87
+	OpCoerceU        = 0x88
+	OpCoerceO        = 0x89
+	OpNegate         = 0x90
+	OpIncrement      = 0x91
+	OpIncLocal       = 0x92
+	OpDecrement      = 0x93
+	OpDecLocal       = 0x94
+	OpTypeOf         = 0x95
+	OpNot            = 0x96
+	OpBitNot         = 0x97
+	OpAdd            = 0xa0
+	OpSubtract       = 0xa1
+	OpMultiply       = 0xa2
+	OpDivide         = 0xa3
+	OpModulo         = 0xa4
+	OpLShift         = 0xa5
+	OpRShift         = 0xa6
+	OpURShift        = 0xa7
+	OpBitAnd         = 0xa8
+	OpBitOr          = 0xa9
+	OpBitXor         = 0xaa
+	OpEquals         = 0xab
+	OpStrictEquals   = 0xac
+	OpLessThan       = 0xad
+	OpLessEquals     = 0xae
+	OpGreaterThan    = 0xaf
+	OpGreaterEquals  = 0xb0
+	OpInstanceOf     = 0xb1
+	OpIsType         = 0xb2
+	OpIsTypeLate     = 0xb3
+	OpIn             = 0xb4
+	OpIncrementI     = 0xc0
+	OpDecrementI     = 0xc1
+	OpIncLocalI      = 0xc2
+	OpDecLocalI      = 0xc3
+	OpNegateI        = 0xc4
+	OpAddI           = 0xc5
+	OpSubtractI      = 0xc6
+	OpMultiplyI      = 0xc7
+	OpGetLocal0      = 0xd0
+	OpGetLocal1      = 0xd1
+	OpGetLocal2      = 0xd2
+	OpGetLocal3      = 0xd3
+	OpSetLocal0      = 0xd4
+	OpSetLocal1      = 0xd5
+	OpSetLocal2      = 0xd6
+	OpSetLocal3      = 0xd7
+	OpDebug          = 0xef
+	OpDebugLine      = 0xf0
+	OpDebugFile      = 0xf1
+	OpBkptLine       = 0xf2
+	OpTimestamp      = 0xf3
+)
+
+type opcodeInfo struct {
+	name     string
+	operands []OperandKind
+	pops     int // pops is the number of values popped, arguments and runtime names aside
+	pushes   int
+}
+
+// opcodes describes the operands and the stack effect of every known opcode.
+// The operands of lookupswitch are variable and decoded separately
+var opcodes = [256]*opcodeInfo{
+	OpBkpt:           {"bkpt", nil, 0, 0},
+	OpNop:            {"nop", nil, 0, 0},
+	OpThrow:          {"throw", nil, 1, 0},
+	OpGetSuper:       {"getsuper", []OperandKind{OperandMultiname}, 1, 1},
+	OpSetSuper:       {"setsuper", []OperandKind{OperandMultiname}, 2, 0},
+	OpDxns:           {"dxns", []OperandKind{OperandString}, 0, 0},
+	OpDxnsLate:       {"dxnslate", nil, 1, 0},
+	OpKill:           {"kill", []OperandKind{OperandRegister}, 0, 0},
+	OpLabel:          {"label", nil, 0, 0},
+	OpIfNlt:          {"ifnlt", []OperandKind{OperandOffset}, 2, 0},
+	OpIfNle:          {"ifnle", []OperandKind{OperandOffset}, 2, 0},
+	OpIfNgt:          {"ifngt", []OperandKind{OperandOffset}, 2, 0},
+	OpIfNge:          {"ifnge", []OperandKind{OperandOffset}, 2, 0},
+	OpJump:           {"jump", []OperandKind{OperandOffset}, 0, 0},
+	OpIfTrue:         {"iftrue", []OperandKind{OperandOffset}, 1, 0},
+	OpIfFalse:        {"iffalse", []OperandKind{OperandOffset}, 1, 0},
+	OpIfEq:           {"ifeq", []OperandKind{OperandOffset}, 2, 0},
+	OpIfNe:           {"ifne", []OperandKind{OperandOffset}, 2, 0},
+	OpIfLt:           {"iflt", []OperandKind{OperandOffset}, 2, 0},
+	OpIfLe:           {"ifle", []OperandKind{OperandOffset}, 2, 0},
+	OpIfGt:           {"ifgt", []OperandKind{OperandOffset}, 2, 0},
+	OpIfGe:           {"ifge", []OperandKind{OperandOffset}, 2, 0},
+	OpIfStrictEq:     {"ifstricteq", []OperandKind{OperandOffset}, 2, 0},
+	OpIfStrictNe:     {"ifstrictne", []OperandKind{OperandOffset}, 2, 0},
+	OpLookupSwitch:   {"lookupswitch", nil, 1, 0},
+	OpPushWith:       {"pushwith", nil, 1, 0},
+	OpPopScope:       {"popscope", nil, 0, 0},
+	OpNextName:       {"nextname", nil, 2, 1},
+	OpHasNext:        {"hasnext", nil, 2, 1},
+	OpPushNull:       {"pushnull", nil, 0, 1},
+	OpPushUndefined:  {"pushundefined", nil, 0, 1},
+	OpNextValue:      {"nextvalue", nil, 2, 1},
+	OpPushByte:       {"pushbyte", []OperandKind{OperandByte}, 0, 1},
+	OpPushShort:      {"pushshort", []OperandKind{OperandShort}, 0, 1},
+	OpPushTrue:       {"pushtrue", nil, 0, 1},
+	OpPushFalse:      {"pushfalse", nil, 0, 1},
+	OpPushNaN:        {"pushnan", nil, 0, 1},
+	OpPop:            {"pop", nil, 1, 0},
+	OpDup:            {"dup", nil, 1, 2},
+	OpSwap:           {"swap", nil, 2, 2},
+	OpPushString:     {"pushstring", []OperandKind{OperandString}, 0, 1},
+	OpPushInt:        {"pushint", []OperandKind{OperandInt}, 0, 1},
+	OpPushUInt:       {"pushuint", []OperandKind{OperandUInt}, 0, 1},
+	OpPushDouble:     {"pushdouble", []OperandKind{OperandDouble}, 0, 1},
+	OpPushScope:      {"pushscope", nil, 1, 0},
+	OpPushNamespace:  {"pushnamespace", []OperandKind{OperandNamespace}, 0, 1},
+	OpHasNext2:       {"hasnext2", []OperandKind{OperandRegister, OperandRegister}, 0, 1},
+	OpLi8:            {"li8", nil, 1, 1},
+	OpLi16:           {"li16", nil, 1, 1},
+	OpLi32:           {"li32", nil, 1, 1},
+	OpLf32:           {"lf32", nil, 1, 1},
+	OpLf64:           {"lf64", nil, 1, 1},
+	OpSi8:            {"si8", nil, 2, 0},
+	OpSi16:           {"si16", nil, 2, 0},
+	OpSi32:           {"si32", nil, 2, 0},
+	OpSf32:           {"sf32", nil, 2, 0},
+	OpSf64:           {"sf64", nil, 2, 0},
+	OpNewFunction:    {"newfunction", []OperandKind{OperandMethod}, 0, 1},
+	OpCall:           {"call", []OperandKind{OperandArgCount}, 2, 1},
+	OpConstruct:      {"construct", []OperandKind{OperandArgCount}, 1, 1},
+	OpCallMethod:     {"callmethod", []OperandKind{OperandU30, OperandArgCount}, 1, 1},
+	OpCallStatic:     {"callstatic", []OperandKind{OperandMethod, OperandArgCount}, 1, 1},
+	OpCallSuper:      {"callsuper", []OperandKind{OperandMultiname, OperandArgCount}, 1, 1},
+	OpCallProperty:   {"callproperty", []OperandKind{OperandMultiname, OperandArgCount}, 1, 1},
+	OpReturnVoid:     {"returnvoid", nil, 0, 0},
+	OpReturnValue:    {"returnvalue", nil, 1, 0},
+	OpConstructSuper: {"constructsuper", []OperandKind{OperandArgCount}, 1, 0},
+	OpConstructProp:  {"constructprop", []OperandKind{OperandMultiname, OperandArgCount}, 1, 1},
+	OpCallPropLex:    {"callproplex", []OperandKind{OperandMultiname, OperandArgCount}, 1, 1},
+	OpCallSuperVoid:  {"callsupervoid", []OperandKind{OperandMultiname, OperandArgCount}, 1, 0},
+	OpCallPropVoid:   {"callpropvoid", []OperandKind{OperandMultiname, OperandArgCount}, 1, 0},
+	OpSxi1:           {"sxi1", nil, 1, 1},
+	OpSxi8:           {"sxi8", nil, 1, 1},
+	OpSxi16:          {"sxi16", nil, 1, 1},
+	OpApplyType:      {"applytype", []OperandKind{OperandArgCount}, 1, 1},
+	OpNewObject:      {"newobject", []OperandKind{OperandArgCount}, 0, 1},
+	OpNewArray:       {"newarray", []OperandKind{OperandArgCount}, 0, 1},
+	OpNewActivation:  {"newactivation", nil, 0, 1},
+	OpNewClass:       {"newclass", []OperandKind{OperandClass}, 1, 1},
+	OpGetDescendants: {"getdescendants", []OperandKind{OperandMultiname}, 1, 1},
+	OpNewCatch:       {"newcatch", []OperandKind{OperandException}, 0, 1},
+	OpFindPropStrict: {"findpropstrict", []OperandKind{OperandMultiname}, 0, 1},
+	OpFindProperty:   {"findproperty", []OperandKind{OperandMultiname}, 0, 1},
+	OpFindDef:        {"finddef", []OperandKind{OperandMultiname}, 0, 1},
+	OpGetLex:         {"getlex", []OperandKind{OperandMultiname}, 0, 1},
+	OpSetProperty:    {"setproperty", []OperandKind{OperandMultiname}, 2, 0},
+	OpGetLocal:       {"getlocal", []OperandKind{OperandRegister}, 0, 1},
+	OpSetLocal:       {"setlocal", []OperandKind{OperandRegister}, 1, 0},
+	OpGetGlobalScope: {"getglobalscope", nil, 0, 1},
+	OpGetScopeObject: {"getscopeobject", []OperandKind{OperandU30}, 0, 1},
+	OpGetProperty:    {"getproperty", []OperandKind{OperandMultiname}, 1, 1},
+	OpGetOuterScope:  {"getouterscope", []OperandKind{OperandU30}, 0, 1},
+	OpInitProperty:   {"initproperty", []OperandKind{OperandMultiname}, 2, 0},
+	OpDeleteProperty: {"deleteproperty", []OperandKind{OperandMultiname}, 1, 1},
+	OpGetSlot:        {"getslot", []OperandKind{OperandU30}, 1, 1},
+	OpSetSlot:        {"setslot", []OperandKind{OperandU30}, 2, 0},
+	OpGetGlobalSlot:  {"getglobalslot", []OperandKind{OperandU30}, 0, 1},
+	OpSetGlobalSlot:  {"setglobalslot", []OperandKind{OperandU30}, 1, 0},
+	OpConvertS:       {"convert_s", nil, 1, 1},
+	OpEscXElem:       {"esc_xelem", nil, 1, 1},
+	OpEscXAttr:       {"esc_xattr", nil, 1, 1},
+	OpConvertI:       {"convert_i", nil, 1, 1},
+	OpConvertU:       {"convert_u", nil, 1, 1},
+	OpConvertD:       {"convert_d", nil, 1, 1},
+	OpConvertB:       {"convert_b", nil, 1, 1},
+	OpConvertO:       {"convert_o", nil, 1, 1},
+	OpCheckFilter:    {"checkfilter", nil, 1, 1},
+	OpCoerce:         {"coerce", []OperandKind{OperandMultiname}, 1, 1},
+	OpCoerceB:        {"coerce_b", nil, 1, 1},
+	OpCoerceA:        {"coerce_a", nil, 1, 1},
+	OpCoerceI:        {"coerce_i", nil, 1, 1},
+	OpCoerceD:        {"coerce_d", nil, 1, 1},
+	OpCoerceS:        {"coerce_s", nil, 1, 1},
+	OpAsType:         {"astype", []OperandKind{OperandMultiname}, 1, 1},
+	OpAsTypeLate:     {"astypelate", nil, 2, 1},
+	OpCoerceU:        {"coerce_u", nil, 1, 1},
+	OpCoerceO:        {"coerce_o", nil, 1, 1},
+	OpNegate:         {"negate", nil, 1, 1},
+	OpIncrement:      {"increment", nil, 1, 1},
+	OpIncLocal:       {"inclocal", []OperandKind{OperandRegister}, 0, 0},
+	OpDecrement:      {"decrement", nil, 1, 1},
+	OpDecLocal:       {"declocal", []OperandKind{OperandRegister}, 0, 0},
+	OpTypeOf:         {"typeof", nil, 1, 1},
+	OpNot:            {"not", nil, 1, 1},
+	OpBitNot:         {"bitnot", nil, 1, 1},
+	OpAdd:            {"add", nil, 2, 1},
+	OpSubtract:       {"subtract", nil, 2, 1},
+	OpMultiply:       {"multiply", nil, 2, 1},
+	OpDivide:         {"divide", nil, 2, 1},
+	OpModulo:         {"modulo", nil, 2, 1},
+	OpLShift:         {"lshift", nil, 2, 1},
+	OpRShift:         {"rshift", nil, 2, 1},
+	OpURShift:        {"urshift", nil, 2, 1},
+	OpBitAnd:         {"bitand", nil, 2, 1},
+	OpBitOr:          {"bitor", nil, 2, 1},
+	OpBitXor:         {"bitxor", nil, 2, 1},
+	OpEquals:         {"equals", nil, 2, 1},
+	OpStrictEquals:   {"strictequals", nil, 2, 1},
+	OpLessThan:       {"lessthan", nil, 2, 1},
+	OpLessEquals:     {"lessequals", nil, 2, 1},
+	OpGreaterThan:    {"greaterthan", nil, 2, 1},
+	OpGreaterEquals:  {"greaterequals", nil, 2, 1},
+	OpInstanceOf:     {"instanceof", nil, 2, 1},
+	OpIsType:         {"istype", []OperandKind{OperandMultiname}, 1, 1},
+	OpIsTypeLate:     {"istypelate", nil, 2, 1},
+	OpIn:             {"in", nil, 2, 1},
+	OpIncrementI:     {"increment_i", nil, 1, 1},
+	OpDecrementI:     {"decrement_i", nil, 1, 1},
+	OpIncLocalI:      {"inclocal_i", []OperandKind{OperandRegister}, 0, 0},
+	OpDecLocalI:      {"declocal_i", []OperandKind{OperandRegister}, 0, 0},
+	OpNegateI:        {"negate_i", nil, 1, 1},
+	OpAddI:           {"add_i", nil, 2, 1},
+	OpSubtractI:      {"subtract_i", nil, 2, 1},
+	OpMultiplyI:      {"multiply_i", nil, 2, 1},
+	OpGetLocal0:      {"getlocal_0", nil, 0, 1},
+	OpGetLocal1:      {"getlocal_1", nil, 0, 1},
+	OpGetLocal2:      {"getlocal_2", nil, 0, 1},
+	OpGetLocal3:      {"getlocal_3", nil, 0, 1},
+	OpSetLocal0:      {"setlocal_0", nil, 1, 0},
+	OpSetLocal1:      {"setlocal_1", nil, 1, 0},
+	OpSetLocal2:      {"setlocal_2", nil, 1, 0},
+	OpSetLocal3:      {"setlocal_3", nil, 1, 0},
+	OpDebug:          {"debug", []OperandKind{OperandUByte, OperandString, OperandUByte, OperandU30}, 0, 0},
+	OpDebugLine:      {"debugline", []OperandKind{OperandU30}, 0, 0},
+	OpDebugFile:      {"debugfile", []OperandKind{OperandString}, 0, 0},
+	OpBkptLine:       {"bkptline", []OperandKind{OperandU30}, 0, 0},
+	OpTimestamp:      {"timestamp", nil, 0, 0},
+}
@@ -0,0 +1,555 @@
+package abc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrUnknownKind means that the ABC file is malformed.
+// A multiname or a trait has a kind that is not defined by the specification
+var ErrUnknownKind = errors.New("unknown kind")
+
+// Parser is the minimal interface for parsing an ABC file
+type Parser interface {
+	Parse() (File, error)
+}
+
+type parser struct {
+	r Reader
+}
+
+func newParser(origin io.ReadSeeker) *parser {
+	return &parser{NewReader(origin)}
+}
+
+// Parse creates a Parser and parses the given input
+func Parse(origin io.ReadSeeker) (File, error) {
+	return newParser(origin).Parse()
+}
+
+// ParseBytes parses an ABC file held in memory, such as TagDoABC.ABCData
+func ParseBytes(b []byte) (File, error) {
+	return Parse(bytes.NewReader(b))
+}
+
+// NewParser provides a simple way to create an ABC file Parser
+func NewParser(origin io.ReadSeeker) Parser {
+	return newParser(origin)
+}
+
+func (p *parser) handleEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Parse parses an entire ABC file
+func (p *parser) Parse() (f File, err error) {
+	if f.MinorVersion, err = p.r.ReadUInt16(); err != nil {
+		return File{}, p.handleEOF(err)
+	}
+	if f.MajorVersion, err = p.r.ReadUInt16(); err != nil {
+		return File{}, p.handleEOF(err)
+	}
+	if f.ConstantPool, err = p.ParseConstantPool(); err != nil {
+		return File{}, err
+	}
+
+	count, err := p.readCount()
+	if err != nil {
+		return File{}, err
+	}
+	for i := uint32(0); i < count; i++ {
+		m, err := p.ParseMethodInfo()
+		if err != nil {
+			return File{}, err
+		}
+		f.Methods = append(f.Methods, m)
+	}
+
+	if count, err = p.readCount(); err != nil {
+		return File{}, err
+	}
+	for i := uint32(0); i < count; i++ {
+		m, err := p.ParseMetadataInfo()
+		if err != nil {
+			return File{}, err
+		}
+		f.Metadata = append(f.Metadata, m)
+	}
+
+	// Instances and classes share the same count
+	if count, err = p.readCount(); err != nil {
+		return File{}, err
+	}
+	for i := uint32(0); i < count; i++ {
+		instance, err := p.ParseInstanceInfo()
+		if err != nil {
+			return File{}, err
+		}
+		f.Instances = append(f.Instances, instance)
+	}
+	for i := uint32(0); i < count; i++ {
+		class, err := p.ParseClassInfo()
+		if err != nil {
+			return File{}, err
+		}
+		f.Classes = append(f.Classes, class)
+	}
+
+	if count, err = p.readCount(); err != nil {
+		return File{}, err
+	}
+	for i := uint32(0); i < count; i++ {
+		script, err := p.ParseScriptInfo()
+		if err != nil {
+			return File{}, err
+		}
+		f.Scripts = append(f.Scripts, script)
+	}
+
+	if count, err = p.readCount(); err != nil {
+		return File{}, err
+	}
+	for i := uint32(0); i < count; i++ {
+		body, err := p.ParseMethodBody()
+		if err != nil {
+			return File{}, err
+		}
+		f.MethodBodies = append(f.MethodBodies, body)
+	}
+	return f, nil
+}
+
+func (p *parser) readU30() (uint32, error) {
+	v, err := p.r.ReadU30()
+	return v, p.handleEOF(err)
+}
+
+func (p *parser) readU8() (uint8, error) {
+	v, err := p.r.ReadUInt8()
+	return v, p.handleEOF(err)
+}
+
+// readCount reads the u30 count of an array
+func (p *parser) readCount() (uint32, error) {
+	return p.readU30()
+}
+
+// readPoolCount reads the count of a constant pool array.
+// The count includes the implicit entry 0, so 0 and 1 both mean empty
+func (p *parser) readPoolCount() (uint32, error) {
+	count, err := p.readU30()
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	return count - 1, nil
+}
+
+// readU30Array reads count u30 values
+func (p *parser) readU30Array(count uint32) ([]uint32, error) {
+	var values []uint32
+	for i := uint32(0); i < count; i++ {
+		v, err := p.readU30()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// ParseConstantPool parses a cpool_info
+func (p *parser) ParseConstantPool() (cp ConstantPool, err error) {
+	count, err := p.readPoolCount()
+	if err != nil {
+		return
+	}
+	cp.Integers = []int32{0}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.r.ReadS32()
+		if err != nil {
+			return ConstantPool{}, p.handleEOF(err)
+		}
+		cp.Integers = append(cp.Integers, v)
+	}
+
+	if count, err = p.readPoolCount(); err != nil {
+		return
+	}
+	cp.UIntegers = []uint32{0}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.r.ReadU32()
+		if err != nil {
+			return ConstantPool{}, p.handleEOF(err)
+		}
+		cp.UIntegers = append(cp.UIntegers, v)
+	}
+
+	if count, err = p.readPoolCount(); err != nil {
+		return
+	}
+	cp.Doubles = []float64{0}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.r.ReadD64()
+		if err != nil {
+			return ConstantPool{}, p.handleEOF(err)
+		}
+		cp.Doubles = append(cp.Doubles, v)
+	}
+
+	if count, err = p.readPoolCount(); err != nil {
+		return
+	}
+	cp.Strings = []string{""}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.ParseString()
+		if err != nil {
+			return ConstantPool{}, err
+		}
+		cp.Strings = append(cp.Strings, v)
+	}
+
+	if count, err = p.readPoolCount(); err != nil {
+		return
+	}
+	cp.Namespaces = []Namespace{{}}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.ParseNamespace()
+		if err != nil {
+			return ConstantPool{}, err
+		}
+		cp.Namespaces = append(cp.Namespaces, v)
+	}
+
+	if count, err = p.readPoolCount(); err != nil {
+		return
+	}
+	cp.NsSets = []NsSet{nil}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.ParseNsSet()
+		if err != nil {
+			return ConstantPool{}, err
+		}
+		cp.NsSets = append(cp.NsSets, v)
+	}
+
+	if count, err = p.readPoolCount(); err != nil {
+		return
+	}
+	cp.Multinames = []Multiname{{}}
+	for i := uint32(0); i < count; i++ {
+		v, err := p.ParseMultiname()
+		if err != nil {
+			return ConstantPool{}, err
+		}
+		cp.Multinames = append(cp.Multinames, v)
+	}
+	return cp, nil
+}
+
+// ParseString parses a string_info, a u30 length followed by UTF-8 bytes
+func (p *parser) ParseString() (string, error) {
+	length, err := p.readU30()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if n, err := io.CopyN(&buf, p.r, int64(length)); err != nil {
+		return "", p.handleEOF(err)
+	} else if uint32(n) != length {
+		return "", io.ErrUnexpectedEOF
+	}
+	return buf.String(), nil
+}
+
+// ParseNamespace parses a namespace_info
+func (p *parser) ParseNamespace() (ns Namespace, err error) {
+	if ns.Kind, err = p.readU8(); err != nil {
+		return
+	}
+	ns.Name, err = p.readU30()
+	return
+}
+
+// ParseNsSet parses a ns_set_info
+func (p *parser) ParseNsSet() (NsSet, error) {
+	count, err := p.readCount()
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := p.readU30Array(count)
+	if err != nil {
+		return nil, err
+	}
+	return NsSet(namespaces), nil
+}
+
+// ParseMultiname parses a multiname_info
+func (p *parser) ParseMultiname() (m Multiname, err error) {
+	if m.Kind, err = p.readU8(); err != nil {
+		return
+	}
+	switch m.Kind {
+	default:
+		err = ErrUnknownKind
+	case KindQName, KindQNameA:
+		if m.Namespace, err = p.readU30(); err != nil {
+			return
+		}
+		m.Name, err = p.readU30()
+	case KindRTQName, KindRTQNameA:
+		m.Name, err = p.readU30()
+	case KindRTQNameL, KindRTQNameLA:
+		break
+	case KindMultiname, KindMultinameA:
+		if m.Name, err = p.readU30(); err != nil {
+			return
+		}
+		m.NsSet, err = p.readU30()
+	case KindMultinameL, KindMultinameLA:
+		m.NsSet, err = p.readU30()
+	case KindTypeName:
+		if m.QName, err = p.readU30(); err != nil {
+			return
+		}
+		var count uint32
+		if count, err = p.readCount(); err != nil {
+			return
+		}
+		m.Params, err = p.readU30Array(count)
+	}
+	return
+}
+
+// ParseMethodInfo parses a method_info
+func (p *parser) ParseMethodInfo() (m MethodInfo, err error) {
+	paramCount, err := p.readCount()
+	if err != nil {
+		return
+	}
+	if m.ReturnType, err = p.readU30(); err != nil {
+		return
+	}
+	if m.ParamTypes, err = p.readU30Array(paramCount); err != nil {
+		return
+	}
+	if m.Name, err = p.readU30(); err != nil {
+		return
+	}
+	if m.Flags, err = p.readU8(); err != nil {
+		return
+	}
+
+	if m.Flags&MethodHasOptional != 0 {
+		var count uint32
+		if count, err = p.readCount(); err != nil {
+			return
+		}
+		for i := uint32(0); i < count; i++ {
+			var option OptionDetail
+			if option.Value, err = p.readU30(); err != nil {
+				return
+			}
+			if option.Kind, err = p.readU8(); err != nil {
+				return
+			}
+			m.Options = append(m.Options, option)
+		}
+	}
+
+	if m.Flags&MethodHasParamNames != 0 {
+		m.ParamNames, err = p.readU30Array(paramCount)
+	}
+	return
+}
+
+// ParseMetadataInfo parses a metadata_info.
+// The AVM2 stores every key before the values, not as pairs as documented
+func (p *parser) ParseMetadataInfo() (m MetadataInfo, err error) {
+	if m.Name, err = p.readU30(); err != nil {
+		return
+	}
+	count, err := p.readCount()
+	if err != nil {
+		return
+	}
+	keys, err := p.readU30Array(count)
+	if err != nil {
+		return
+	}
+	values, err := p.readU30Array(count)
+	if err != nil {
+		return
+	}
+	for i := range keys {
+		m.Items = append(m.Items, ItemInfo{keys[i], values[i]})
+	}
+	return
+}
+
+// ParseInstanceInfo parses an instance_info
+func (p *parser) ParseInstanceInfo() (i InstanceInfo, err error) {
+	if i.Name, err = p.readU30(); err != nil {
+		return
+	}
+	if i.SuperName, err = p.readU30(); err != nil {
+		return
+	}
+	if i.Flags, err = p.readU8(); err != nil {
+		return
+	}
+	if i.Flags&ClassProtectedNs != 0 {
+		if i.ProtectedNs, err = p.readU30(); err != nil {
+			return
+		}
+	}
+	count, err := p.readCount()
+	if err != nil {
+		return
+	}
+	if i.Interfaces, err = p.readU30Array(count); err != nil {
+		return
+	}
+	if i.IInit, err = p.readU30(); err != nil {
+		return
+	}
+	i.Traits, err = p.ParseTraits()
+	return
+}
+
+// ParseClassInfo parses a class_info
+func (p *parser) ParseClassInfo() (c ClassInfo, err error) {
+	if c.CInit, err = p.readU30(); err != nil {
+		return
+	}
+	c.Traits, err = p.ParseTraits()
+	return
+}
+
+// ParseScriptInfo parses a script_info
+func (p *parser) ParseScriptInfo() (s ScriptInfo, err error) {
+	if s.Init, err = p.readU30(); err != nil {
+		return
+	}
+	s.Traits, err = p.ParseTraits()
+	return
+}
+
+// ParseTraits parses a u30 count followed by as many traits_info
+func (p *parser) ParseTraits() ([]Trait, error) {
+	count, err := p.readCount()
+	if err != nil {
+		return nil, err
+	}
+	var traits []Trait
+	for i := uint32(0); i < count; i++ {
+		t, err := p.ParseTrait()
+		if err != nil {
+			return nil, err
+		}
+		traits = append(traits, t)
+	}
+	return traits, nil
+}
+
+// ParseTrait parses a traits_info
+func (p *parser) ParseTrait() (t Trait, err error) {
+	if t.Name, err = p.readU30(); err != nil {
+		return
+	}
+	kind, err := p.readU8()
+	if err != nil {
+		return
+	}
+	t.Kind = kind & 0x0f
+	t.Attributes = kind >> 4
+	if t.SlotID, err = p.readU30(); err != nil {
+		return
+	}
+
+	switch t.Kind {
+	default:
+		return t, ErrUnknownKind
+	case TraitSlot, TraitConst:
+		if t.TypeName, err = p.readU30(); err != nil {
+			return
+		}
+		if t.VIndex, err = p.readU30(); err != nil {
+			return
+		}
+		if t.VIndex != 0 {
+			if t.VKind, err = p.readU8(); err != nil {
+				return
+			}
+		}
+	case TraitClass:
+		if t.Class, err = p.readU30(); err != nil {
+			return
+		}
+	case TraitMethod, TraitGetter, TraitSetter, TraitFunction:
+		if t.Method, err = p.readU30(); err != nil {
+			return
+		}
+	}
+
+	if t.Attributes&AttributeMetadata != 0 {
+		var count uint32
+		if count, err = p.readCount(); err != nil {
+			return
+		}
+		t.Metadata, err = p.readU30Array(count)
+	}
+	return
+}
+
+// ParseMethodBody parses a method_body_info
+func (p *parser) ParseMethodBody() (b MethodBody, err error) {
+	if b.Method, err = p.readU30(); err != nil {
+		return
+	}
+	if b.MaxStack, err = p.readU30(); err != nil {
+		return
+	}
+	if b.LocalCount, err = p.readU30(); err != nil {
+		return
+	}
+	if b.InitScopeDepth, err = p.readU30(); err != nil {
+		return
+	}
+	if b.MaxScopeDepth, err = p.readU30(); err != nil {
+		return
+	}
+
+	codeLength, err := p.readU30()
+	if err != nil {
+		return
+	}
+	var code bytes.Buffer
+	if n, copyErr := io.CopyN(&code, p.r, int64(codeLength)); copyErr != nil {
+		return b, p.handleEOF(copyErr)
+	} else if uint32(n) != codeLength {
+		return b, io.ErrUnexpectedEOF
+	}
+	b.Code = code.Bytes()
+
+	count, err := p.readCount()
+	if err != nil {
+		return
+	}
+	for i := uint32(0); i < count; i++ {
+		var e ExceptionInfo
+		fields := []*uint32{&e.From, &e.To, &e.Target, &e.ExcType, &e.VarName}
+		for _, field := range fields {
+			if *field, err = p.readU30(); err != nil {
+				return
+			}
+		}
+		b.Exceptions = append(b.Exceptions, e)
+	}
+
+	b.Traits, err = p.ParseTraits()
+	return
+}
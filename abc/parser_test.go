@@ -0,0 +1,135 @@
+package abc
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+var abcBytes = []byte{
+	0x10, 0x00, 0x2e, 0x00,
+	// Constant pool
+	0x02, 0x7f,
+	0x02, 0xac, 0x02,
+	0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x3f,
+	0x03, 0x04, 'M', 'a', 'i', 'n', 0x06, 'S', 'p', 'r', 'i', 't', 'e',
+	0x02, 0x16, 0x00,
+	0x02, 0x01, 0x01,
+	0x04, 0x07, 0x01, 0x01, 0x09, 0x02, 0x01, 0x1d, 0x01, 0x01, 0x02,
+	// Methods
+	0x01, 0x01, 0x00, 0x02, 0x01, 0x88, 0x01, 0x01, 0x03, 0x02,
+	// Metadata
+	0x01, 0x01, 0x02, 0x00, 0x01, 0x02, 0x01,
+	// Instances and classes
+	0x01,
+	0x01, 0x02, 0x09, 0x01, 0x01, 0x03, 0x00,
+	0x01, 0x01, 0x00, 0x01, 0x02, 0x01, 0x03,
+	0x00, 0x01, 0x01, 0x41, 0x00, 0x00, 0x01, 0x00,
+	// Scripts
+	0x01, 0x00, 0x01, 0x01, 0x04, 0x01, 0x00,
+	// Method bodies
+	0x01, 0x00, 0x01, 0x02, 0x00, 0x01,
+	0x03, 0xd0, 0x30, 0x47,
+	0x01, 0x00, 0x01, 0x02, 0x00, 0x00,
+	0x00,
+}
+
+var abcFile = File{
+	MinorVersion: 16,
+	MajorVersion: 46,
+	ConstantPool: ConstantPool{
+		Integers:   []int32{0, -1},
+		UIntegers:  []uint32{0, 300},
+		Doubles:    []float64{0, 1.5},
+		Strings:    []string{"", "Main", "Sprite"},
+		Namespaces: []Namespace{{}, {KindPackageNamespace, 0}},
+		NsSets:     []NsSet{nil, {1}},
+		Multinames: []Multiname{
+			{},
+			{Kind: KindQName, Namespace: 1, Name: 1},
+			{Kind: KindMultiname, Name: 2, NsSet: 1},
+			{Kind: KindTypeName, QName: 1, Params: []uint32{2}},
+		},
+	},
+	Methods: []MethodInfo{{
+		ParamTypes: []uint32{2},
+		ReturnType: 0,
+		Name:       1,
+		Flags:      MethodHasOptional | MethodHasParamNames,
+		Options:    []OptionDetail{{1, KindInt}},
+		ParamNames: []uint32{2},
+	}},
+	Metadata: []MetadataInfo{{1, []ItemInfo{{0, 2}, {1, 1}}}},
+	Instances: []InstanceInfo{{
+		Name:        1,
+		SuperName:   2,
+		Flags:       ClassSealed | ClassProtectedNs,
+		ProtectedNs: 1,
+		Interfaces:  []uint32{3},
+		IInit:       0,
+		Traits: []Trait{
+			{Name: 1, Kind: TraitSlot, SlotID: 1, TypeName: 2, VIndex: 1, VKind: KindInt},
+		},
+	}},
+	Classes: []ClassInfo{{
+		CInit: 0,
+		Traits: []Trait{
+			{Name: 1, Kind: TraitMethod, Attributes: AttributeMetadata, Method: 0, Metadata: []uint32{0}},
+		},
+	}},
+	Scripts: []ScriptInfo{{
+		Init: 0,
+		Traits: []Trait{
+			{Name: 1, Kind: TraitClass, SlotID: 1, Class: 0},
+		},
+	}},
+	MethodBodies: []MethodBody{{
+		Method:         0,
+		MaxStack:       1,
+		LocalCount:     2,
+		InitScopeDepth: 0,
+		MaxScopeDepth:  1,
+		Code:           []byte{0xd0, 0x30, 0x47},
+		Exceptions:     []ExceptionInfo{{0, 1, 2, 0, 0}},
+	}},
+}
+
+func TestParse(t *testing.T) {
+	f, err := NewParser(bytes.NewReader(abcBytes)).Parse()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !reflect.DeepEqual(f, abcFile) {
+		t.Errorf("expected %+v, got %+v", abcFile, f)
+	}
+
+	for i := 0; i < len(abcBytes); i++ {
+		if _, err := ParseBytes(abcBytes[:i]); err != io.ErrUnexpectedEOF {
+			t.Errorf("expected io.ErrUnexpectedEOF for %v bytes, got %v", i, err)
+		}
+	}
+}
+
+func TestParseConstantPoolEmpty(t *testing.T) {
+	p := newParser(bytes.NewReader([]byte{0, 1, 0, 0, 1, 0, 0}))
+	cp, err := p.ParseConstantPool()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if len(cp.Integers) != 1 || len(cp.Strings) != 1 || len(cp.Multinames) != 1 {
+		t.Errorf("expected only implicit entries, got %+v", cp)
+	}
+}
+
+func TestParseUnknownKind(t *testing.T) {
+	p := newParser(bytes.NewReader([]byte{0x42, 0x00}))
+	if _, err := p.ParseMultiname(); err != ErrUnknownKind {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+
+	p = newParser(bytes.NewReader([]byte{0x01, 0x07, 0x00, 0x00}))
+	if _, err := p.ParseTrait(); err != ErrUnknownKind {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+}
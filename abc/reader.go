@@ -0,0 +1,109 @@
+package abc
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/kelvyne/swf"
+)
+
+// ErrMalformedU30 means that a u30 value does not fit in 30 bits
+var ErrMalformedU30 = errors.New("malformed u30")
+
+// Reader is the minimal interface required to read an ABC file.
+// It extends swf.Reader with the variable length types of the ABC format
+type Reader interface {
+	swf.Reader
+	ReadU30() (uint32, error)
+	ReadU32() (uint32, error)
+	ReadS32() (int32, error)
+	ReadS24() (int32, error)
+	ReadD64() (float64, error)
+}
+
+type reader struct {
+	swf.Reader
+}
+
+// NewReader provides a simple way to create a Reader from a given io.Reader
+func NewReader(r io.ReadSeeker) Reader {
+	return &reader{swf.NewReader(r)}
+}
+
+// readVariable reads a variable length encoded integer of up to 5 bytes.
+// It returns the value and the number of bytes read
+func (r *reader) readVariable() (uint32, uint, error) {
+	var v uint32
+	var count uint
+	for {
+		b, err := r.ReadUInt8()
+		if err != nil {
+			if err == io.EOF && count != 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, 0, err
+		}
+
+		v = v | (uint32(b&0x7f) << (count * 7))
+
+		count++
+		if count == 5 || (b&0x80) == 0 {
+			break
+		}
+	}
+	return v, count, nil
+}
+
+// ReadU32 reads a variable length encoded unsigned int 32
+func (r *reader) ReadU32() (uint32, error) {
+	v, _, err := r.readVariable()
+	return v, err
+}
+
+// ReadU30 reads a variable length encoded unsigned int 30.
+// It fails with ErrMalformedU30 when the two high bits are set
+func (r *reader) ReadU30() (uint32, error) {
+	v, err := r.ReadU32()
+	if err != nil {
+		return 0, err
+	}
+	if v&0xc0000000 != 0 {
+		return 0, ErrMalformedU30
+	}
+	return v, nil
+}
+
+// ReadS32 reads a variable length encoded signed int 32.
+// The sign is extended from the last bit read, as the AVM2 does
+func (r *reader) ReadS32() (int32, error) {
+	v, count, err := r.readVariable()
+	if err != nil {
+		return 0, err
+	}
+	if count < 5 {
+		shift := 32 - count*7
+		return int32(v<<shift) >> shift, nil
+	}
+	return int32(v), nil
+}
+
+// ReadS24 reads a three bytes signed int 24
+func (r *reader) ReadS24() (int32, error) {
+	var b [3]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	v := uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+	return int32(v<<8) >> 8, nil
+}
+
+// ReadD64 reads a little endian IEEE 754 double precision number
+func (r *reader) ReadD64() (float64, error) {
+	var v uint64
+	if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(v), nil
+}
@@ -0,0 +1,94 @@
+package abc
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestReadU32(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0x5f, 0x8a, 0x89, 0x01, 0xff, 0xff, 0xff, 0xff, 0x0f, 0x8f}))
+	for _, expected := range []uint32{0x5f, 0x448a, 0xffffffff} {
+		v, err := reader.ReadU32()
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+		if v != expected {
+			t.Errorf("expected %#x, got %#x", expected, v)
+		}
+	}
+
+	if _, err := reader.ReadU32(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := reader.ReadU32(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadU30(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x03, 0xff, 0xff, 0xff, 0xff, 0x0f}))
+	v, err := reader.ReadU30()
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if v != 0x3fffffff {
+		t.Errorf("expected 0x3fffffff, got %#x", v)
+	}
+
+	if _, err = reader.ReadU30(); err != ErrMalformedU30 {
+		t.Errorf("expected ErrMalformedU30, got %v", err)
+	}
+}
+
+func TestReadS32(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0x7f, 0x3f, 0x80, 0x7f, 0xff, 0xff, 0xff, 0xff, 0x0f}))
+	for _, expected := range []int32{-1, 63, -128, -1} {
+		v, err := reader.ReadS32()
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+		if v != expected {
+			t.Errorf("expected %v, got %v", expected, v)
+		}
+	}
+
+	if _, err := reader.ReadS32(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadS24(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0x03, 0x00, 0x00, 0xfd, 0xff, 0xff, 0x01}))
+	for _, expected := range []int32{3, -3} {
+		v, err := reader.ReadS24()
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+		if v != expected {
+			t.Errorf("expected %v, got %v", expected, v)
+		}
+	}
+
+	if _, err := reader.ReadS24(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := reader.ReadS24(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadD64(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f, 0x00}))
+	v, err := reader.ReadD64()
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if v != 1.5 {
+		t.Errorf("expected 1.5, got %v", v)
+	}
+
+	if _, err = reader.ReadD64(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
@@ -0,0 +1,432 @@
+package abc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrClassCount means that the ABC file can not be serialized.
+// Instances and Classes do not have the same length
+var ErrClassCount = errors.New("instances and classes count mismatch")
+
+// ErrParamNamesCount means that the ABC file can not be serialized.
+// A method has param names but not one for every parameter
+var ErrParamNamesCount = errors.New("param names count mismatch")
+
+type serializer struct {
+	w Writer
+}
+
+func newSerializer(w io.Writer) *serializer {
+	return &serializer{NewWriter(w)}
+}
+
+// Write serializes an entire ABC file.
+// Array counts are computed from the slices, so they do not need
+// to be kept up to date
+func Write(w io.Writer, f File) error {
+	s := newSerializer(w)
+	if err := s.Serialize(f); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// Bytes serializes an ABC file in memory, for instance to replace
+// TagDoABC.ABCData
+func Bytes(f File) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Serialize serializes an entire ABC file
+func (s *serializer) Serialize(f File) error {
+	if err := s.w.WriteUInt16(f.MinorVersion); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt16(f.MajorVersion); err != nil {
+		return err
+	}
+	if err := s.SerializeConstantPool(f.ConstantPool); err != nil {
+		return err
+	}
+
+	if err := s.writeCount(len(f.Methods)); err != nil {
+		return err
+	}
+	for _, m := range f.Methods {
+		if err := s.SerializeMethodInfo(m); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writeCount(len(f.Metadata)); err != nil {
+		return err
+	}
+	for _, m := range f.Metadata {
+		if err := s.SerializeMetadataInfo(m); err != nil {
+			return err
+		}
+	}
+
+	// Instances and classes share the same count
+	if len(f.Instances) != len(f.Classes) {
+		return ErrClassCount
+	}
+	if err := s.writeCount(len(f.Instances)); err != nil {
+		return err
+	}
+	for _, instance := range f.Instances {
+		if err := s.SerializeInstanceInfo(instance); err != nil {
+			return err
+		}
+	}
+	for _, class := range f.Classes {
+		if err := s.SerializeClassInfo(class); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writeCount(len(f.Scripts)); err != nil {
+		return err
+	}
+	for _, script := range f.Scripts {
+		if err := s.SerializeScriptInfo(script); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writeCount(len(f.MethodBodies)); err != nil {
+		return err
+	}
+	for _, body := range f.MethodBodies {
+		if err := s.SerializeMethodBody(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *serializer) writeCount(count int) error {
+	return s.w.WriteU30(uint32(count))
+}
+
+// writePoolCount writes the count of a constant pool array, which includes
+// the implicit entry 0
+func (s *serializer) writePoolCount(count int) error {
+	if count <= 1 {
+		return s.writeCount(0)
+	}
+	return s.writeCount(count)
+}
+
+func (s *serializer) writeU30Array(values []uint32) error {
+	for _, v := range values {
+		if err := s.w.WriteU30(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeConstantPool serializes a cpool_info.
+// Entry 0 of every slice is implicit and not written
+func (s *serializer) SerializeConstantPool(cp ConstantPool) error {
+	if err := s.writePoolCount(len(cp.Integers)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.Integers); i++ {
+		if err := s.w.WriteS32(cp.Integers[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writePoolCount(len(cp.UIntegers)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.UIntegers); i++ {
+		if err := s.w.WriteU32(cp.UIntegers[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writePoolCount(len(cp.Doubles)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.Doubles); i++ {
+		if err := s.w.WriteD64(cp.Doubles[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writePoolCount(len(cp.Strings)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.Strings); i++ {
+		if err := s.SerializeString(cp.Strings[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writePoolCount(len(cp.Namespaces)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.Namespaces); i++ {
+		if err := s.SerializeNamespace(cp.Namespaces[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writePoolCount(len(cp.NsSets)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.NsSets); i++ {
+		if err := s.SerializeNsSet(cp.NsSets[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writePoolCount(len(cp.Multinames)); err != nil {
+		return err
+	}
+	for i := 1; i < len(cp.Multinames); i++ {
+		if err := s.SerializeMultiname(cp.Multinames[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeString serializes a string_info
+func (s *serializer) SerializeString(str string) error {
+	if err := s.w.WriteU30(uint32(len(str))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(s.w, str)
+	return err
+}
+
+// SerializeNamespace serializes a namespace_info
+func (s *serializer) SerializeNamespace(ns Namespace) error {
+	if err := s.w.WriteUInt8(ns.Kind); err != nil {
+		return err
+	}
+	return s.w.WriteU30(ns.Name)
+}
+
+// SerializeNsSet serializes a ns_set_info
+func (s *serializer) SerializeNsSet(set NsSet) error {
+	if err := s.writeCount(len(set)); err != nil {
+		return err
+	}
+	return s.writeU30Array(set)
+}
+
+// SerializeMultiname serializes a multiname_info
+func (s *serializer) SerializeMultiname(m Multiname) error {
+	if err := s.w.WriteUInt8(m.Kind); err != nil {
+		return err
+	}
+	switch m.Kind {
+	default:
+		return ErrUnknownKind
+	case KindQName, KindQNameA:
+		return s.writeU30Array([]uint32{m.Namespace, m.Name})
+	case KindRTQName, KindRTQNameA:
+		return s.w.WriteU30(m.Name)
+	case KindRTQNameL, KindRTQNameLA:
+		return nil
+	case KindMultiname, KindMultinameA:
+		return s.writeU30Array([]uint32{m.Name, m.NsSet})
+	case KindMultinameL, KindMultinameLA:
+		return s.w.WriteU30(m.NsSet)
+	case KindTypeName:
+		if err := s.writeU30Array([]uint32{m.QName, uint32(len(m.Params))}); err != nil {
+			return err
+		}
+		return s.writeU30Array(m.Params)
+	}
+}
+
+// SerializeMethodInfo serializes a method_info.
+// Options and ParamNames are written according to Flags
+func (s *serializer) SerializeMethodInfo(m MethodInfo) error {
+	if err := s.writeU30Array([]uint32{uint32(len(m.ParamTypes)), m.ReturnType}); err != nil {
+		return err
+	}
+	if err := s.writeU30Array(m.ParamTypes); err != nil {
+		return err
+	}
+	if err := s.w.WriteU30(m.Name); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt8(m.Flags); err != nil {
+		return err
+	}
+
+	if m.Flags&MethodHasOptional != 0 {
+		if err := s.writeCount(len(m.Options)); err != nil {
+			return err
+		}
+		for _, option := range m.Options {
+			if err := s.w.WriteU30(option.Value); err != nil {
+				return err
+			}
+			if err := s.w.WriteUInt8(option.Kind); err != nil {
+				return err
+			}
+		}
+	}
+
+	if m.Flags&MethodHasParamNames != 0 {
+		if len(m.ParamNames) != len(m.ParamTypes) {
+			return ErrParamNamesCount
+		}
+		return s.writeU30Array(m.ParamNames)
+	}
+	return nil
+}
+
+// SerializeMetadataInfo serializes a metadata_info, keys before values
+func (s *serializer) SerializeMetadataInfo(m MetadataInfo) error {
+	if err := s.writeU30Array([]uint32{m.Name, uint32(len(m.Items))}); err != nil {
+		return err
+	}
+	for _, item := range m.Items {
+		if err := s.w.WriteU30(item.Key); err != nil {
+			return err
+		}
+	}
+	for _, item := range m.Items {
+		if err := s.w.WriteU30(item.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeInstanceInfo serializes an instance_info
+func (s *serializer) SerializeInstanceInfo(i InstanceInfo) error {
+	if err := s.writeU30Array([]uint32{i.Name, i.SuperName}); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt8(i.Flags); err != nil {
+		return err
+	}
+	if i.Flags&ClassProtectedNs != 0 {
+		if err := s.w.WriteU30(i.ProtectedNs); err != nil {
+			return err
+		}
+	}
+	if err := s.writeCount(len(i.Interfaces)); err != nil {
+		return err
+	}
+	if err := s.writeU30Array(i.Interfaces); err != nil {
+		return err
+	}
+	if err := s.w.WriteU30(i.IInit); err != nil {
+		return err
+	}
+	return s.SerializeTraits(i.Traits)
+}
+
+// SerializeClassInfo serializes a class_info
+func (s *serializer) SerializeClassInfo(c ClassInfo) error {
+	if err := s.w.WriteU30(c.CInit); err != nil {
+		return err
+	}
+	return s.SerializeTraits(c.Traits)
+}
+
+// SerializeScriptInfo serializes a script_info
+func (s *serializer) SerializeScriptInfo(script ScriptInfo) error {
+	if err := s.w.WriteU30(script.Init); err != nil {
+		return err
+	}
+	return s.SerializeTraits(script.Traits)
+}
+
+// SerializeTraits serializes a u30 count followed by as many traits_info
+func (s *serializer) SerializeTraits(traits []Trait) error {
+	if err := s.writeCount(len(traits)); err != nil {
+		return err
+	}
+	for _, t := range traits {
+		if err := s.SerializeTrait(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeTrait serializes a traits_info.
+// Metadata is written according to Attributes
+func (s *serializer) SerializeTrait(t Trait) error {
+	if err := s.w.WriteU30(t.Name); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt8(t.Attributes<<4 | t.Kind&0x0f); err != nil {
+		return err
+	}
+	if err := s.w.WriteU30(t.SlotID); err != nil {
+		return err
+	}
+
+	switch t.Kind {
+	default:
+		return ErrUnknownKind
+	case TraitSlot, TraitConst:
+		if err := s.writeU30Array([]uint32{t.TypeName, t.VIndex}); err != nil {
+			return err
+		}
+		if t.VIndex != 0 {
+			if err := s.w.WriteUInt8(t.VKind); err != nil {
+				return err
+			}
+		}
+	case TraitClass:
+		if err := s.w.WriteU30(t.Class); err != nil {
+			return err
+		}
+	case TraitMethod, TraitGetter, TraitSetter, TraitFunction:
+		if err := s.w.WriteU30(t.Method); err != nil {
+			return err
+		}
+	}
+
+	if t.Attributes&AttributeMetadata != 0 {
+		if err := s.writeCount(len(t.Metadata)); err != nil {
+			return err
+		}
+		return s.writeU30Array(t.Metadata)
+	}
+	return nil
+}
+
+// SerializeMethodBody serializes a method_body_info
+func (s *serializer) SerializeMethodBody(b MethodBody) error {
+	header := []uint32{
+		b.Method, b.MaxStack, b.LocalCount, b.InitScopeDepth, b.MaxScopeDepth,
+		uint32(len(b.Code)),
+	}
+	if err := s.writeU30Array(header); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(b.Code); err != nil {
+		return err
+	}
+
+	if err := s.writeCount(len(b.Exceptions)); err != nil {
+		return err
+	}
+	for _, e := range b.Exceptions {
+		if err := s.writeU30Array([]uint32{e.From, e.To, e.Target, e.ExcType, e.VarName}); err != nil {
+			return err
+		}
+	}
+	return s.SerializeTraits(b.Traits)
+}
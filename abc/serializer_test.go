@@ -0,0 +1,28 @@
+package abc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	b, err := Bytes(abcFile)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(b, abcBytes) {
+		t.Errorf("expected %#v, got %#v", abcBytes, b)
+	}
+
+	f := abcFile
+	f.Classes = nil
+	if err = Write(&bytes.Buffer{}, f); err != ErrClassCount {
+		t.Errorf("expected ErrClassCount, got %v", err)
+	}
+
+	f = abcFile
+	f.Methods = []MethodInfo{{ParamTypes: []uint32{1}, Flags: MethodHasParamNames}}
+	if err = Write(&bytes.Buffer{}, f); err != ErrParamNamesCount {
+		t.Errorf("expected ErrParamNamesCount, got %v", err)
+	}
+}
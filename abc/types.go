@@ -0,0 +1,211 @@
+package abc
+
+// These represent possible Namespace kinds
+const (
+	KindPrivateNs          = 0x05
+	KindNamespace          = 0x08
+	KindPackageNamespace   = 0x16
+	KindPackageInternalNs  = 0x17
+	KindProtectedNamespace = 0x18
+	KindExplicitNamespace  = 0x19
+	KindStaticProtectedNs  = 0x1a
+)
+
+// These represent possible Multiname kinds
+const (
+	KindQName       = 0x07
+	KindQNameA      = 0x0d
+	KindRTQName     = 0x0f
+	KindRTQNameA    = 0x10
+	KindRTQNameL    = 0x11
+	KindRTQNameLA   = 0x12
+	KindMultiname   = 0x09
+	KindMultinameA  = 0x0e
+	KindMultinameL  = 0x1b
+	KindMultinameLA = 0x1c
+	KindTypeName    = 0x1d
+)
+
+// These represent possible constant kinds of optional parameters and slots.
+// Namespace kinds are also valid constant kinds
+const (
+	KindUndefined = 0x00
+	KindUtf8      = 0x01
+	KindInt       = 0x03
+	KindUInt      = 0x04
+	KindDouble    = 0x06
+	KindFalse     = 0x0a
+	KindTrue      = 0x0b
+	KindNull      = 0x0c
+)
+
+// These represent MethodInfo flags
+const (
+	MethodNeedArguments  = 0x01
+	MethodNeedActivation = 0x02
+	MethodNeedRest       = 0x04
+	MethodHasOptional    = 0x08
+	MethodSetDxns        = 0x40
+	MethodHasParamNames  = 0x80
+)
+
+// These represent InstanceInfo flags
+const (
+	ClassSealed      = 0x01
+	ClassFinal       = 0x02
+	ClassInterface   = 0x04
+	ClassProtectedNs = 0x08
+)
+
+// These represent possible Trait kinds
+const (
+	TraitSlot     = 0
+	TraitMethod   = 1
+	TraitGetter   = 2
+	TraitSetter   = 3
+	TraitClass    = 4
+	TraitFunction = 5
+	TraitConst    = 6
+)
+
+// These represent Trait attributes
+const (
+	AttributeFinal    = 0x1
+	AttributeOverride = 0x2
+	AttributeMetadata = 0x4
+)
+
+// File represents an ABC file deserialized
+type File struct {
+	MinorVersion uint16
+	MajorVersion uint16
+	ConstantPool ConstantPool
+	Methods      []MethodInfo
+	Metadata     []MetadataInfo
+	Instances    []InstanceInfo
+	Classes      []ClassInfo
+	Scripts      []ScriptInfo
+	MethodBodies []MethodBody
+}
+
+// ConstantPool represents the constant pool of an ABC file.
+// Entry 0 of every slice is the implicit default entry that is not stored
+// in the file, so that indices found in the file can be used as is
+type ConstantPool struct {
+	Integers   []int32
+	UIntegers  []uint32
+	Doubles    []float64
+	Strings    []string
+	Namespaces []Namespace
+	NsSets     []NsSet
+	Multinames []Multiname
+}
+
+// Namespace represents a namespace_info entry
+type Namespace struct {
+	Kind uint8
+	Name uint32
+}
+
+// NsSet represents a ns_set_info entry, a list of namespace indices
+type NsSet []uint32
+
+// Multiname represents a multiname_info entry.
+// Only the fields relevant to Kind are set
+type Multiname struct {
+	Kind      uint8
+	Namespace uint32   // QName, QNameA
+	Name      uint32   // QName, QNameA, RTQName, RTQNameA, Multiname, MultinameA
+	NsSet     uint32   // Multiname, MultinameA, MultinameL, MultinameLA
+	QName     uint32   // TypeName, the generic type
+	Params    []uint32 // TypeName, the type parameters
+}
+
+// MethodInfo represents a method_info entry
+type MethodInfo struct {
+	ParamTypes []uint32
+	ReturnType uint32
+	Name       uint32
+	Flags      uint8
+	Options    []OptionDetail // Present when Flags has MethodHasOptional
+	ParamNames []uint32       // Present when Flags has MethodHasParamNames
+}
+
+// OptionDetail represents the default value of an optional parameter
+type OptionDetail struct {
+	Value uint32
+	Kind  uint8
+}
+
+// MetadataInfo represents a metadata_info entry
+type MetadataInfo struct {
+	Name  uint32
+	Items []ItemInfo
+}
+
+// ItemInfo represents a key/value pair of a metadata entry.
+// Key is 0 for keyless items
+type ItemInfo struct {
+	Key   uint32
+	Value uint32
+}
+
+// InstanceInfo represents an instance_info entry
+type InstanceInfo struct {
+	Name        uint32
+	SuperName   uint32
+	Flags       uint8
+	ProtectedNs uint32 // Present when Flags has ClassProtectedNs
+	Interfaces  []uint32
+	IInit       uint32
+	Traits      []Trait
+}
+
+// ClassInfo represents a class_info entry
+type ClassInfo struct {
+	CInit  uint32
+	Traits []Trait
+}
+
+// ScriptInfo represents a script_info entry
+type ScriptInfo struct {
+	Init   uint32
+	Traits []Trait
+}
+
+// Trait represents a traits_info entry.
+// Only the fields relevant to Kind are set
+type Trait struct {
+	Name       uint32
+	Kind       uint8  // Kind is the lower 4 bits of the kind byte
+	Attributes uint8  // Attributes is the upper 4 bits of the kind byte
+	SlotID     uint32 // SlotID is the slot_id, or the disp_id of methods
+	TypeName   uint32 // Slot, Const
+	VIndex     uint32 // Slot, Const
+	VKind      uint8  // Slot, Const, present when VIndex is not 0
+	Class      uint32 // Class
+	Method     uint32 // Method, Getter, Setter, Function
+	Metadata   []uint32
+}
+
+// MethodBody represents a method_body_info entry
+type MethodBody struct {
+	Method         uint32
+	MaxStack       uint32
+	LocalCount     uint32
+	InitScopeDepth uint32
+	MaxScopeDepth  uint32
+	Code           []byte
+	Exceptions     []ExceptionInfo
+	Traits         []Trait
+}
+
+// ExceptionInfo represents an exception_info entry.
+// From, To and Target are byte offsets in the code of the method body
+type ExceptionInfo struct {
+	From    uint32
+	To      uint32
+	Target  uint32
+	ExcType uint32
+	VarName uint32
+}
@@ -0,0 +1,92 @@
+package abc
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/kelvyne/swf"
+)
+
+// Writer is the minimal interface required to write an ABC file.
+// It extends swf.Writer with the variable length types of the ABC format
+type Writer interface {
+	swf.Writer
+	WriteU30(v uint32) error
+	WriteU32(v uint32) error
+	WriteS32(v int32) error
+	WriteS24(v int32) error
+	WriteD64(v float64) error
+}
+
+type writer struct {
+	swf.Writer
+}
+
+// NewWriter provides a simple way to create a Writer from a given io.Writer
+func NewWriter(w io.Writer) Writer {
+	return &writer{swf.NewWriter(w)}
+}
+
+// WriteU32 writes a variable length encoded unsigned int 32
+func (w *writer) WriteU32(v uint32) error {
+	for {
+		b := uint8(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		if err := w.WriteUInt8(b); err != nil {
+			return err
+		}
+		if v == 0 {
+			return nil
+		}
+	}
+}
+
+// WriteU30 writes a variable length encoded unsigned int 30.
+// It fails with ErrMalformedU30 when v does not fit in 30 bits
+func (w *writer) WriteU30(v uint32) error {
+	if v&0xc0000000 != 0 {
+		return ErrMalformedU30
+	}
+	return w.WriteU32(v)
+}
+
+// WriteS32 writes a variable length encoded signed int 32.
+// It stops as soon as the sign can be extended from the last bit written
+func (w *writer) WriteS32(v int32) error {
+	for count := 1; ; count++ {
+		b := uint8(v & 0x7f)
+		v >>= 7
+		done := count == 5 || (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0)
+		if !done {
+			b |= 0x80
+		}
+		if err := w.WriteUInt8(b); err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// WriteS24 writes a three bytes signed int 24
+func (w *writer) WriteS24(v int32) error {
+	if v < -0x800000 || v > 0x7fffff {
+		return errors.New("s24 value overflows")
+	}
+	_, err := w.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16)})
+	return err
+}
+
+// WriteD64 writes a little endian IEEE 754 double precision number
+func (w *writer) WriteD64(v float64) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, math.Float64bits(v))
+}
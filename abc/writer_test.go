@@ -0,0 +1,74 @@
+package abc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteVariable(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WriteU32(0xffffffff); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.WriteU30(0x448a); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.WriteU30(0x40000000); err != ErrMalformedU30 {
+		t.Errorf("expected ErrMalformedU30, got %v", err)
+	}
+	expected := []byte{0xff, 0xff, 0xff, 0xff, 0x0f, 0x8a, 0x89, 0x01}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, buf.Bytes())
+	}
+}
+
+func TestWriteS32(t *testing.T) {
+	values := []int32{-1, 63, 64, -64, -65, -128, 0x7fffffff, -0x80000000}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, v := range values {
+		if err := w.WriteS32(v); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0x7f, 0x3f, 0xc0, 0x00, 0x40, 0xbf, 0x7f, 0x80, 0x7f}) {
+		t.Errorf("unexpected encoding %#v", buf.Bytes())
+	}
+
+	r := NewReader(bytes.NewReader(buf.Bytes()))
+	for _, v := range values {
+		if got, err := r.ReadS32(); err != nil || got != v {
+			t.Errorf("expected %v, got %v (%v)", v, got, err)
+		}
+	}
+}
+
+func TestWriteS24(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WriteS24(3); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.WriteS24(-3); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.WriteS24(0x800000); err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+	expected := []byte{0x03, 0x00, 0x00, 0xfd, 0xff, 0xff}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, buf.Bytes())
+	}
+}
+
+func TestWriteD64(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).WriteD64(1.5); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, buf.Bytes())
+	}
+}
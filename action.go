@@ -0,0 +1,578 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ErrPushType means that an ActionPush holds a value of an unknown type
+var ErrPushType = errors.New("unknown push value type")
+
+// These represent the codes of the AVM1 actions. Actions whose code is
+// 0x80 or more have a payload
+const (
+	ActionCodeEnd             = 0x00
+	ActionCodeNextFrame       = 0x04
+	ActionCodePreviousFrame   = 0x05
+	ActionCodePlay            = 0x06
+	ActionCodeStop            = 0x07
+	ActionCodeToggleQuality   = 0x08
+	ActionCodeStopSounds      = 0x09
+	ActionCodeAdd             = 0x0a
+	ActionCodeSubtract        = 0x0b
+	ActionCodeMultiply        = 0x0c
+	ActionCodeDivide          = 0x0d
+	ActionCodeEquals          = 0x0e
+	ActionCodeLess            = 0x0f
+	ActionCodeAnd             = 0x10
+	ActionCodeOr              = 0x11
+	ActionCodeNot             = 0x12
+	ActionCodeStringEquals    = 0x13
+	ActionCodeStringLength    = 0x14
+	ActionCodeStringExtract   = 0x15
+	ActionCodePop             = 0x17
+	ActionCodeToInteger       = 0x18
+	ActionCodeGetVariable     = 0x1c
+	ActionCodeSetVariable     = 0x1d
+	ActionCodeSetTarget2      = 0x20
+	ActionCodeStringAdd       = 0x21
+	ActionCodeGetProperty     = 0x22
+	ActionCodeSetProperty     = 0x23
+	ActionCodeCloneSprite     = 0x24
+	ActionCodeRemoveSprite    = 0x25
+	ActionCodeTrace           = 0x26
+	ActionCodeStartDrag       = 0x27
+	ActionCodeEndDrag         = 0x28
+	ActionCodeStringLess      = 0x29
+	ActionCodeThrow           = 0x2a
+	ActionCodeCastOp          = 0x2b
+	ActionCodeImplementsOp    = 0x2c
+	ActionCodeRandomNumber    = 0x30
+	ActionCodeMBStringLength  = 0x31
+	ActionCodeCharToAscii     = 0x32
+	ActionCodeAsciiToChar     = 0x33
+	ActionCodeGetTime         = 0x34
+	ActionCodeMBStringExtract = 0x35
+	ActionCodeMBCharToAscii   = 0x36
+	ActionCodeMBAsciiToChar   = 0x37
+	ActionCodeDelete          = 0x3a
+	ActionCodeDelete2         = 0x3b
+	ActionCodeDefineLocal     = 0x3c
+	ActionCodeCallFunction    = 0x3d
+	ActionCodeReturn          = 0x3e
+	ActionCodeModulo          = 0x3f
+	ActionCodeNewObject       = 0x40
+	ActionCodeDefineLocal2    = 0x41
+	ActionCodeInitArray       = 0x42
+	ActionCodeInitObject      = 0x43
+	ActionCodeTypeOf          = 0x44
+	ActionCodeTargetPath      = 0x45
+	ActionCodeEnumerate       = 0x46
+	ActionCodeAdd2            = 0x47
+	ActionCodeLess2           = 0x48
+	ActionCodeEquals2         = 0x49
+	ActionCodeToNumber        = 0x4a
+	ActionCodeToString        = 0x4b
+	ActionCodePushDuplicate   = 0x4c
+	ActionCodeStackSwap       = 0x4d
+	ActionCodeGetMember       = 0x4e
+	ActionCodeSetMember       = 0x4f
+	ActionCodeIncrement       = 0x50
+	ActionCodeDecrement       = 0x51
+	ActionCodeCallMethod      = 0x52
+	ActionCodeNewMethod       = 0x53
+	ActionCodeInstanceOf      = 0x54
+	ActionCodeEnumerate2      = 0x55
+	ActionCodeBitAnd          = 0x60
+	ActionCodeBitOr           = 0x61
+	ActionCodeBitXor          = 0x62
+	ActionCodeBitLShift       = 0x63
+	ActionCodeBitRShift       = 0x64
+	ActionCodeBitURShift      = 0x65
+	ActionCodeStrictEquals    = 0x66
+	ActionCodeGreater         = 0x67
+	ActionCodeStringGreater   = 0x68
+	ActionCodeExtends         = 0x69
+	ActionCodeGotoFrame       = 0x81
+	ActionCodeGetURL          = 0x83
+	ActionCodeStoreRegister   = 0x87
+	ActionCodeConstantPool    = 0x88
+	ActionCodeWaitForFrame    = 0x8a
+	ActionCodeSetTarget       = 0x8b
+	ActionCodeGoToLabel       = 0x8c
+	ActionCodeWaitForFrame2   = 0x8d
+	ActionCodeDefineFunction2 = 0x8e
+	ActionCodeTry             = 0x8f
+	ActionCodeWith            = 0x94
+	ActionCodePush            = 0x96
+	ActionCodeJump            = 0x99
+	ActionCodeGetURL2         = 0x9a
+	ActionCodeDefineFunction  = 0x9b
+	ActionCodeIf              = 0x9d
+	ActionCodeCall            = 0x9e
+	ActionCodeGotoFrame2      = 0x9f
+)
+
+// actionNames holds the mnemonic of the known actions
+var actionNames = map[uint8]string{
+	ActionCodeEnd:             "end",
+	ActionCodeNextFrame:       "nextFrame",
+	ActionCodePreviousFrame:   "prevFrame",
+	ActionCodePlay:            "play",
+	ActionCodeStop:            "stop",
+	ActionCodeToggleQuality:   "toggleQuality",
+	ActionCodeStopSounds:      "stopSounds",
+	ActionCodeAdd:             "oldAdd",
+	ActionCodeSubtract:        "subtract",
+	ActionCodeMultiply:        "multiply",
+	ActionCodeDivide:          "divide",
+	ActionCodeEquals:          "oldEquals",
+	ActionCodeLess:            "oldLessThan",
+	ActionCodeAnd:             "and",
+	ActionCodeOr:              "or",
+	ActionCodeNot:             "not",
+	ActionCodeStringEquals:    "stringEq",
+	ActionCodeStringLength:    "stringLength",
+	ActionCodeStringExtract:   "substring",
+	ActionCodePop:             "pop",
+	ActionCodeToInteger:       "int",
+	ActionCodeGetVariable:     "getVariable",
+	ActionCodeSetVariable:     "setVariable",
+	ActionCodeSetTarget2:      "setTargetExpr",
+	ActionCodeStringAdd:       "concat",
+	ActionCodeGetProperty:     "getProperty",
+	ActionCodeSetProperty:     "setProperty",
+	ActionCodeCloneSprite:     "duplicateClip",
+	ActionCodeRemoveSprite:    "removeMovieClip",
+	ActionCodeTrace:           "trace",
+	ActionCodeStartDrag:       "startDrag",
+	ActionCodeEndDrag:         "stopDrag",
+	ActionCodeStringLess:      "stringLess",
+	ActionCodeThrow:           "throw",
+	ActionCodeCastOp:          "cast",
+	ActionCodeImplementsOp:    "implements",
+	ActionCodeRandomNumber:    "random",
+	ActionCodeMBStringLength:  "mbLength",
+	ActionCodeCharToAscii:     "ord",
+	ActionCodeAsciiToChar:     "chr",
+	ActionCodeGetTime:         "getTimer",
+	ActionCodeMBStringExtract: "mbSubstring",
+	ActionCodeMBCharToAscii:   "mbOrd",
+	ActionCodeMBAsciiToChar:   "mbChr",
+	ActionCodeDelete:          "delete",
+	ActionCodeDelete2:         "delete2",
+	ActionCodeDefineLocal:     "varEquals",
+	ActionCodeCallFunction:    "callFunction",
+	ActionCodeReturn:          "return",
+	ActionCodeModulo:          "modulo",
+	ActionCodeNewObject:       "new",
+	ActionCodeDefineLocal2:    "var",
+	ActionCodeInitArray:       "initArray",
+	ActionCodeInitObject:      "initObject",
+	ActionCodeTypeOf:          "typeof",
+	ActionCodeTargetPath:      "targetPath",
+	ActionCodeEnumerate:       "enumerate",
+	ActionCodeAdd2:            "add",
+	ActionCodeLess2:           "lessThan",
+	ActionCodeEquals2:         "equals",
+	ActionCodeToNumber:        "toNumber",
+	ActionCodeToString:        "toString",
+	ActionCodePushDuplicate:   "dup",
+	ActionCodeStackSwap:       "swap",
+	ActionCodeGetMember:       "getMember",
+	ActionCodeSetMember:       "setMember",
+	ActionCodeIncrement:       "increment",
+	ActionCodeDecrement:       "decrement",
+	ActionCodeCallMethod:      "callMethod",
+	ActionCodeNewMethod:       "newMethod",
+	ActionCodeInstanceOf:      "instanceOf",
+	ActionCodeEnumerate2:      "enumerateValue",
+	ActionCodeBitAnd:          "bitwiseAnd",
+	ActionCodeBitOr:           "bitwiseOr",
+	ActionCodeBitXor:          "bitwiseXor",
+	ActionCodeBitLShift:       "shiftLeft",
+	ActionCodeBitRShift:       "shiftRight",
+	ActionCodeBitURShift:      "shiftRight2",
+	ActionCodeStrictEquals:    "strictEquals",
+	ActionCodeGreater:         "greaterThan",
+	ActionCodeStringGreater:   "stringGreater",
+	ActionCodeExtends:         "extends",
+	ActionCodeGotoFrame:       "gotoFrame",
+	ActionCodeGetURL:          "getURL",
+	ActionCodeStoreRegister:   "setRegister",
+	ActionCodeConstantPool:    "constants",
+	ActionCodeWaitForFrame:    "ifFrameLoaded",
+	ActionCodeSetTarget:       "setTarget",
+	ActionCodeGoToLabel:       "gotoLabel",
+	ActionCodeWaitForFrame2:   "ifFrameLoadedExpr",
+	ActionCodeDefineFunction2: "function2",
+	ActionCodeTry:             "try",
+	ActionCodeWith:            "with",
+	ActionCodePush:            "push",
+	ActionCodeJump:            "branch",
+	ActionCodeGetURL2:         "getURL2",
+	ActionCodeDefineFunction:  "function",
+	ActionCodeIf:              "branchIfTrue",
+	ActionCodeCall:            "callFrame",
+	ActionCodeGotoFrame2:      "gotoFrame2",
+}
+
+// These represent the types of the values of an ActionPush
+const (
+	PushTypeString     = 0
+	PushTypeFloat      = 1
+	PushTypeNull       = 2
+	PushTypeUndefined  = 3
+	PushTypeRegister   = 4
+	PushTypeBoolean    = 5
+	PushTypeDouble     = 6
+	PushTypeInteger    = 7
+	PushTypeConstant8  = 8
+	PushTypeConstant16 = 9
+)
+
+// These represent the flags of an ActionDefineFunction2, in the order they
+// are stored
+const (
+	FunctionPreloadParent     = 1 << 15
+	FunctionPreloadRoot       = 1 << 14
+	FunctionSuppressSuper     = 1 << 13
+	FunctionPreloadSuper      = 1 << 12
+	FunctionSuppressArguments = 1 << 11
+	FunctionPreloadArguments  = 1 << 10
+	FunctionSuppressThis      = 1 << 9
+	FunctionPreloadThis       = 1 << 8
+	FunctionPreloadGlobal     = 1 << 0
+)
+
+// Name returns the mnemonic of the action
+func (a Action) Name() string {
+	if name, found := actionNames[a.Code]; found {
+		return name
+	}
+	return "op_" + strconv.FormatUint(uint64(a.Code), 16)
+}
+
+// Actions decodes the actions of the tag, see DecodeActions
+func (t *TagDoAction) Actions() ([]Action, error) {
+	return DecodeActions(t.ActionData)
+}
+
+// Actions decodes the actions of the tag, see DecodeActions
+func (t *TagDoInitAction) Actions() ([]Action, error) {
+	return DecodeActions(t.ActionData)
+}
+
+// DecodeActions decodes AVM1 code, such as the ActionData of a DoAction
+// tag or the Actions of a ClipActionRecord, into actions in code order.
+// The bodies of functions and the blocks of ActionWith and ActionTry are
+// the actions that follow them, their sizes tell where they end.
+// On error, the actions decoded so far are returned
+func DecodeActions(code []byte) ([]Action, error) {
+	var actions []Action
+	for offset := 0; offset < len(code); {
+		a := Action{Offset: uint32(offset), Code: code[offset]}
+		offset++
+		if a.Code >= 0x80 {
+			if offset+2 > len(code) {
+				return actions, io.ErrUnexpectedEOF
+			}
+			length := int(binary.LittleEndian.Uint16(code[offset:]))
+			offset += 2
+			if offset+length > len(code) {
+				return actions, io.ErrUnexpectedEOF
+			}
+			a.Data = code[offset : offset+length]
+			offset += length
+
+			payload, err := decodeActionPayload(a.Code, a.Data, uint32(offset))
+			if err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return actions, err
+			}
+			a.Payload = payload
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// decodeActionPayload decodes the payload of an action. next is the offset
+// of the following action, branches are relative to it
+func decodeActionPayload(code uint8, data []byte, next uint32) (ActionPayload, error) {
+	r := NewReader(bytes.NewReader(data))
+	var err error
+	switch code {
+	case ActionCodeGotoFrame:
+		p := &ActionGotoFrame{}
+		p.Frame, err = r.ReadUInt16()
+		return p, err
+	case ActionCodeGetURL:
+		p := &ActionGetURL{}
+		if p.URL, err = r.ReadString(); err != nil {
+			return nil, err
+		}
+		p.Target, err = r.ReadString()
+		return p, err
+	case ActionCodeStoreRegister:
+		p := &ActionStoreRegister{}
+		p.Register, err = r.ReadUInt8()
+		return p, err
+	case ActionCodeConstantPool:
+		return decodeConstantPool(r)
+	case ActionCodeWaitForFrame:
+		p := &ActionWaitForFrame{}
+		if p.Frame, err = r.ReadUInt16(); err != nil {
+			return nil, err
+		}
+		p.SkipCount, err = r.ReadUInt8()
+		return p, err
+	case ActionCodeSetTarget:
+		p := &ActionSetTarget{}
+		p.TargetName, err = r.ReadString()
+		return p, err
+	case ActionCodeGoToLabel:
+		p := &ActionGoToLabel{}
+		p.Label, err = r.ReadString()
+		return p, err
+	case ActionCodeWaitForFrame2:
+		p := &ActionWaitForFrame2{}
+		p.SkipCount, err = r.ReadUInt8()
+		return p, err
+	case ActionCodeDefineFunction2:
+		return decodeDefineFunction2(r)
+	case ActionCodeTry:
+		return decodeTry(r)
+	case ActionCodeWith:
+		p := &ActionWith{}
+		p.Size, err = r.ReadUInt16()
+		return p, err
+	case ActionCodePush:
+		return decodePush(r, len(data))
+	case ActionCodeJump, ActionCodeIf:
+		p := &ActionBranch{}
+		if p.BranchOffset, err = r.ReadInt16(); err != nil {
+			return nil, err
+		}
+		p.Target = uint32(int64(next) + int64(p.BranchOffset))
+		return p, nil
+	case ActionCodeGetURL2:
+		flags, err := r.ReadUInt8()
+		if err != nil {
+			return nil, err
+		}
+		return &ActionGetURL2{flags >> 6, flags&0x02 != 0, flags&0x01 != 0}, nil
+	case ActionCodeDefineFunction:
+		return decodeDefineFunction(r)
+	case ActionCodeGotoFrame2:
+		flags, err := r.ReadUInt8()
+		if err != nil {
+			return nil, err
+		}
+		p := &ActionGotoFrame2{SceneBiasFlag: flags&0x02 != 0, Play: flags&0x01 != 0}
+		if p.SceneBiasFlag {
+			p.SceneBias, err = r.ReadUInt16()
+		}
+		return p, err
+	}
+	return nil, nil
+}
+
+func decodeConstantPool(r Reader) (*ActionConstantPool, error) {
+	count, err := r.ReadUInt16()
+	if err != nil {
+		return nil, err
+	}
+	p := &ActionConstantPool{Constants: make([]string, count)}
+	for i := range p.Constants {
+		if p.Constants[i], err = r.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func decodeDefineFunction(r Reader) (*ActionDefineFunction, error) {
+	p := &ActionDefineFunction{}
+	var err error
+	if p.FunctionName, err = r.ReadString(); err != nil {
+		return nil, err
+	}
+	count, err := r.ReadUInt16()
+	if err != nil {
+		return nil, err
+	}
+	p.Params = make([]string, count)
+	for i := range p.Params {
+		if p.Params[i], err = r.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+	p.CodeSize, err = r.ReadUInt16()
+	return p, err
+}
+
+func decodeDefineFunction2(r Reader) (*ActionDefineFunction2, error) {
+	p := &ActionDefineFunction2{}
+	var err error
+	if p.FunctionName, err = r.ReadString(); err != nil {
+		return nil, err
+	}
+	count, err := r.ReadUInt16()
+	if err != nil {
+		return nil, err
+	}
+	if p.RegisterCount, err = r.ReadUInt8(); err != nil {
+		return nil, err
+	}
+	high, err := r.ReadUInt8()
+	if err != nil {
+		return nil, err
+	}
+	low, err := r.ReadUInt8()
+	if err != nil {
+		return nil, err
+	}
+	p.Flags = uint16(high)<<8 | uint16(low)
+	p.Parameters = make([]RegisterParam, count)
+	for i := range p.Parameters {
+		if p.Parameters[i].Register, err = r.ReadUInt8(); err != nil {
+			return nil, err
+		}
+		if p.Parameters[i].ParamName, err = r.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+	p.CodeSize, err = r.ReadUInt16()
+	return p, err
+}
+
+func decodeTry(r Reader) (*ActionTry, error) {
+	flags, err := r.ReadUInt8()
+	if err != nil {
+		return nil, err
+	}
+	p := &ActionTry{
+		CatchInRegister: flags&0x04 != 0,
+		HasFinallyBlock: flags&0x02 != 0,
+		HasCatchBlock:   flags&0x01 != 0,
+	}
+	for _, size := range []*uint16{&p.TrySize, &p.CatchSize, &p.FinallySize} {
+		if *size, err = r.ReadUInt16(); err != nil {
+			return nil, err
+		}
+	}
+	if p.CatchInRegister {
+		p.CatchRegister, err = r.ReadUInt8()
+	} else {
+		p.CatchName, err = r.ReadString()
+	}
+	return p, err
+}
+
+// decodePush decodes the values of an ActionPush, up to the end of its
+// payload
+func decodePush(r Reader, length int) (*ActionPush, error) {
+	p := &ActionPush{}
+	for {
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if offset >= int64(length) {
+			return p, nil
+		}
+
+		v := PushValue{}
+		if v.Type, err = r.ReadUInt8(); err != nil {
+			return nil, err
+		}
+		switch v.Type {
+		default:
+			return nil, ErrPushType
+		case PushTypeNull, PushTypeUndefined:
+		case PushTypeString:
+			v.String, err = r.ReadString()
+		case PushTypeFloat:
+			var bits uint32
+			bits, err = r.ReadUInt32()
+			v.Float = math.Float32frombits(bits)
+		case PushTypeRegister:
+			v.Register, err = r.ReadUInt8()
+		case PushTypeBoolean:
+			var b uint8
+			b, err = r.ReadUInt8()
+			v.Boolean = b != 0
+		case PushTypeDouble:
+			// The two 32 bits halves are swapped
+			var high, low uint32
+			if high, err = r.ReadUInt32(); err == nil {
+				low, err = r.ReadUInt32()
+			}
+			v.Double = math.Float64frombits(uint64(high)<<32 | uint64(low))
+		case PushTypeInteger:
+			v.Integer, err = r.ReadInt32()
+		case PushTypeConstant8:
+			var c uint8
+			c, err = r.ReadUInt8()
+			v.Constant = uint16(c)
+		case PushTypeConstant16:
+			v.Constant, err = r.ReadUInt16()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.Values = append(p.Values, v)
+	}
+}
+
+func (*ActionGotoFrame) actionPayload()       {}
+func (*ActionGetURL) actionPayload()          {}
+func (*ActionStoreRegister) actionPayload()   {}
+func (*ActionConstantPool) actionPayload()    {}
+func (*ActionWaitForFrame) actionPayload()    {}
+func (*ActionSetTarget) actionPayload()       {}
+func (*ActionGoToLabel) actionPayload()       {}
+func (*ActionWaitForFrame2) actionPayload()   {}
+func (*ActionDefineFunction2) actionPayload() {}
+func (*ActionTry) actionPayload()             {}
+func (*ActionWith) actionPayload()            {}
+func (*ActionPush) actionPayload()            {}
+func (*ActionBranch) actionPayload()          {}
+func (*ActionGetURL2) actionPayload()         {}
+func (*ActionDefineFunction) actionPayload()  {}
+func (*ActionGotoFrame2) actionPayload()      {}
+
+func (p *parser) ParseTagDoAction(length uint32) (Tag, error) {
+	data, err := p.readAll("DoAction.Actions")
+	if err != nil {
+		return nil, err
+	}
+	return &TagDoAction{tag{code: CodeTagDoAction, length: length}, data}, nil
+}
+
+func (p *parser) ParseTagDoInitAction(length uint32) (Tag, error) {
+	spriteID, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "DoInitAction.SpriteID")
+	}
+	data, err := p.readAll("DoInitAction.Actions")
+	if err != nil {
+		return nil, err
+	}
+	return &TagDoInitAction{tag{code: CodeTagDoInitAction, length: length}, spriteID, data}, nil
+}
+
+func (s *serializer) SerializeTagDoInitAction(t *TagDoInitAction) error {
+	if err := s.w.WriteUInt16(t.SpriteID); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.ActionData)
+	return err
+}
@@ -0,0 +1,120 @@
+package swf
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+var actionsBytes = []byte{
+	// ConstantPool
+	0x88, 0x0a, 0x00, 0x02, 0x00, 0x61, 0x00, 0x74, 0x72, 0x61, 0x63, 0x65, 0x00,
+	// Push
+	0x96, 0x21, 0x00, 0x00, 0x73, 0x00, 0x01, 0x00, 0x00, 0xc0, 0x3f, 0x02, 0x03, 0x04, 0x01, 0x05, 0x01,
+	0x06, 0x00, 0x00, 0x04, 0x40, 0x00, 0x00, 0x00, 0x00, 0x07, 0xff, 0xff, 0xff, 0xff, 0x08, 0x00, 0x09, 0x01, 0x00,
+	// DefineFunction2
+	0x8e, 0x0c, 0x00, 0x66, 0x00, 0x01, 0x00, 0x03, 0x01, 0x01, 0x01, 0x78, 0x00, 0x01, 0x00,
+	0x3e,                         // Return
+	0x94, 0x02, 0x00, 0x01, 0x00, // With
+	0x17, // Pop
+	// Try
+	0x8f, 0x08, 0x00, 0x05, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+	0x2a,                         // Throw
+	0x17,                         // Pop
+	0x99, 0x02, 0x00, 0xa7, 0xff, // Jump
+	0x9d, 0x02, 0x00, 0x00, 0x00, // If
+	0x9f, 0x03, 0x00, 0x03, 0x05, 0x00, // GotoFrame2
+	0x00, // End
+}
+
+func TestDecodeActions(t *testing.T) {
+	actions, err := DecodeActions(actionsBytes)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []struct {
+		offset  uint32
+		name    string
+		payload ActionPayload
+	}{
+		{0, "constants", &ActionConstantPool{[]string{"a", "trace"}}},
+		{13, "push", &ActionPush{[]PushValue{
+			{Type: PushTypeString, String: "s"},
+			{Type: PushTypeFloat, Float: 1.5},
+			{Type: PushTypeNull},
+			{Type: PushTypeUndefined},
+			{Type: PushTypeRegister, Register: 1},
+			{Type: PushTypeBoolean, Boolean: true},
+			{Type: PushTypeDouble, Double: 2.5},
+			{Type: PushTypeInteger, Integer: -1},
+			{Type: PushTypeConstant8, Constant: 0},
+			{Type: PushTypeConstant16, Constant: 1},
+		}}},
+		{49, "function2", &ActionDefineFunction2{"f", 3, FunctionPreloadThis | FunctionPreloadGlobal, []RegisterParam{{1, "x"}}, 1}},
+		{64, "return", nil},
+		{65, "with", &ActionWith{1}},
+		{70, "pop", nil},
+		{71, "try", &ActionTry{CatchInRegister: true, HasCatchBlock: true, TrySize: 1, CatchSize: 1, CatchRegister: 2}},
+		{82, "throw", nil},
+		{83, "pop", nil},
+		{84, "branch", &ActionBranch{-89, 0}},
+		{89, "branchIfTrue", &ActionBranch{0, 94}},
+		{94, "gotoFrame2", &ActionGotoFrame2{true, true, 5}},
+		{100, "end", nil},
+	}
+	if len(actions) != len(expected) {
+		t.Fatalf("expected %v, got %v", len(expected), len(actions))
+	}
+	for i, e := range expected {
+		a := actions[i]
+		if a.Offset != e.offset || a.Name() != e.name || !reflect.DeepEqual(a.Payload, e.payload) {
+			t.Errorf("expected %v %v %#v, got %v %v %#v", e.offset, e.name, e.payload, a.Offset, a.Name(), a.Payload)
+		}
+	}
+}
+
+func TestDecodeActionsErrors(t *testing.T) {
+	actions, err := DecodeActions([]byte{0x07, 0x96, 0x02, 0x00, 0x0a, 0x00})
+	if err != ErrPushType {
+		t.Errorf("expected %v, got %v", ErrPushType, err)
+	}
+	if len(actions) != 1 || actions[0].Code != ActionCodeStop {
+		t.Errorf("expected the stop action, got %#v", actions)
+	}
+
+	for _, b := range [][]byte{{0x96, 0x02}, {0x96, 0x02, 0x00, 0x00}, {0x83, 0x02, 0x00, 0x61, 0x00}} {
+		if _, err := DecodeActions(b); err != io.ErrUnexpectedEOF {
+			t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+func TestDoInitAction(t *testing.T) {
+	tagsBytes := []byte{
+		0xc4, 0x0e, // DoInitAction, length 4
+		0x01, 0x00, // SpriteID
+		0x07, 0x00, // Actions
+		0x02, 0x03, // DoAction, length 2
+		0x06, 0x00, // Actions
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	expected := []Tag{
+		&TagDoInitAction{tag{code: CodeTagDoInitAction, length: 4}, 1, []byte{0x07, 0x00}},
+		&TagDoAction{tag{code: CodeTagDoAction, length: 2}, []byte{0x06, 0x00}},
+	}
+	for i, e := range expected {
+		if !reflect.DeepEqual(tags[i], e) {
+			t.Errorf("expected %#v, got %#v", e, tags[i])
+		}
+	}
+
+	actions, err := tags[1].(*TagDoAction).Actions()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if len(actions) != 2 || actions[0].Code != ActionCodePlay || actions[1].Code != ActionCodeEnd {
+		t.Errorf("expected play and end, got %#v", actions)
+	}
+}
@@ -0,0 +1,276 @@
+package swf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// actionBlock is a block of actions being disassembled, such as a function
+// body. The block moves to its next part at each end offset, such as the
+// catch part of a try block, and is closed at the last one
+type actionBlock struct {
+	ends  []uint32
+	texts []string // texts holds the line printed at each end
+}
+
+type actionDumper struct {
+	w         *bufio.Writer
+	blocks    []actionBlock
+	constants []string
+	labels    map[uint32]int
+}
+
+// DisassembleActions writes the disassembled AVM1 code of a DoAction,
+// DoInitAction or clip action, in the spirit of flasm. The values pushed
+// from the constant pool are replaced by the constants, branch targets by
+// labels, and the blocks of functions, ActionWith and ActionTry are
+// indented. End actions are omitted.
+// On a decoding error, the actions decoded so far are written
+func DisassembleActions(w io.Writer, code []byte) error {
+	actions, decodeErr := DecodeActions(code)
+	d := &actionDumper{w: bufio.NewWriter(w)}
+	d.disassemble(actions)
+	if err := d.w.Flush(); decodeErr == nil {
+		decodeErr = err
+	}
+	return decodeErr
+}
+
+func (d *actionDumper) printf(format string, args ...interface{}) {
+	fmt.Fprintf(d.w, format, args...)
+}
+
+func (d *actionDumper) indent() string {
+	return strings.Repeat("  ", len(d.blocks))
+}
+
+func (d *actionDumper) disassemble(actions []Action) {
+	// Labels are numbered in code order, only targets starting an action
+	// get one
+	targets := make(map[uint32]bool)
+	for _, a := range actions {
+		if branch, ok := a.Payload.(*ActionBranch); ok {
+			targets[branch.Target] = true
+		}
+	}
+	d.labels = make(map[uint32]int)
+	for _, a := range actions {
+		if targets[a.Offset] {
+			d.labels[a.Offset] = len(d.labels) + 1
+		}
+	}
+
+	for _, a := range actions {
+		d.closeBlocks(a.Offset)
+		if label, found := d.labels[a.Offset]; found {
+			d.printf("label%d:\n", label)
+		}
+		if a.Code == ActionCodeEnd {
+			continue
+		}
+		d.printf("%s%s", d.indent(), a.Name())
+		if operands := d.operands(a.Payload); operands != "" {
+			d.printf(" %s", operands)
+		}
+		d.printf("\n")
+
+		next := a.Offset + 1
+		if a.Code >= 0x80 {
+			next += 2 + uint32(len(a.Data))
+		}
+		d.openBlock(a.Payload, next)
+	}
+	d.closeBlocks(^uint32(0))
+}
+
+// openBlock opens the block of an action which starts at offset, if any
+func (d *actionDumper) openBlock(payload ActionPayload, offset uint32) {
+	var block actionBlock
+	switch p := payload.(type) {
+	default:
+		return
+	case *ActionDefineFunction:
+		block = functionBlock(p.FunctionName, offset+uint32(p.CodeSize))
+	case *ActionDefineFunction2:
+		block = functionBlock(p.FunctionName, offset+uint32(p.CodeSize))
+	case *ActionWith:
+		block = actionBlock{[]uint32{offset + uint32(p.Size)}, []string{"end // of with"}}
+	case *ActionTry:
+		end := offset + uint32(p.TrySize)
+		if p.HasCatchBlock {
+			block.ends, block.texts = append(block.ends, end), append(block.texts, "catch")
+			end += uint32(p.CatchSize)
+		}
+		if p.HasFinallyBlock {
+			block.ends, block.texts = append(block.ends, end), append(block.texts, "finally")
+			end += uint32(p.FinallySize)
+		}
+		block.ends, block.texts = append(block.ends, end), append(block.texts, "end // of try")
+	}
+	d.blocks = append(d.blocks, block)
+}
+
+// functionBlock returns the block of a function body ending at end
+func functionBlock(name string, end uint32) actionBlock {
+	return actionBlock{[]uint32{end}, []string{strings.TrimSpace("end // of function " + name)}}
+}
+
+// closeBlocks moves the blocks ending at or before offset to their next
+// part, or closes them
+func (d *actionDumper) closeBlocks(offset uint32) {
+	for len(d.blocks) > 0 {
+		block := &d.blocks[len(d.blocks)-1]
+		if block.ends[0] > offset {
+			return
+		}
+		text := block.texts[0]
+		block.ends, block.texts = block.ends[1:], block.texts[1:]
+		if len(block.ends) == 0 {
+			d.blocks = d.blocks[:len(d.blocks)-1]
+			d.printf("%s%s\n", d.indent(), text)
+		} else {
+			d.printf("%s%s\n", strings.Repeat("  ", len(d.blocks)-1), text)
+		}
+	}
+}
+
+// operands returns the textual operands of an action
+func (d *actionDumper) operands(payload ActionPayload) string {
+	var operands []string
+	switch p := payload.(type) {
+	case *ActionGotoFrame:
+		operands = append(operands, strconv.Itoa(int(p.Frame)))
+	case *ActionGetURL:
+		operands = append(operands, strconv.Quote(p.URL), strconv.Quote(p.Target))
+	case *ActionStoreRegister:
+		operands = append(operands, registerName(p.Register))
+	case *ActionConstantPool:
+		d.constants = p.Constants
+		for _, c := range p.Constants {
+			operands = append(operands, strconv.Quote(c))
+		}
+	case *ActionWaitForFrame:
+		operands = append(operands, strconv.Itoa(int(p.Frame)), strconv.Itoa(int(p.SkipCount)))
+	case *ActionSetTarget:
+		operands = append(operands, strconv.Quote(p.TargetName))
+	case *ActionGoToLabel:
+		operands = append(operands, strconv.Quote(p.Label))
+	case *ActionWaitForFrame2:
+		operands = append(operands, strconv.Itoa(int(p.SkipCount)))
+	case *ActionPush:
+		for _, v := range p.Values {
+			operands = append(operands, d.pushValue(v))
+		}
+	case *ActionBranch:
+		if label, found := d.labels[p.Target]; found {
+			return "label" + strconv.Itoa(label)
+		}
+		return fmt.Sprintf("%+d", p.BranchOffset)
+	case *ActionTry:
+		if p.CatchInRegister {
+			return registerName(p.CatchRegister)
+		}
+		return strconv.Quote(p.CatchName)
+	case *ActionDefineFunction:
+		var params []string
+		for _, param := range p.Params {
+			params = append(params, strconv.Quote(param))
+		}
+		return strconv.Quote(p.FunctionName) + " (" + strings.Join(params, ", ") + ")"
+	case *ActionDefineFunction2:
+		return d.function2(p)
+	case *ActionGetURL2:
+		method := []string{"", "GET", "POST"}
+		if int(p.SendVarsMethod) < len(method) && p.SendVarsMethod != 0 {
+			operands = append(operands, method[p.SendVarsMethod])
+		}
+		if p.LoadTarget {
+			operands = append(operands, "loadTarget")
+		}
+		if p.LoadVariables {
+			operands = append(operands, "loadVariables")
+		}
+		return strings.Join(operands, " ")
+	case *ActionGotoFrame2:
+		if p.Play {
+			operands = append(operands, "play")
+		}
+		if p.SceneBiasFlag {
+			operands = append(operands, "bias "+strconv.Itoa(int(p.SceneBias)))
+		}
+		return strings.Join(operands, " ")
+	}
+	return strings.Join(operands, ", ")
+}
+
+// functionFlags holds the names of the flags of an ActionDefineFunction2
+var functionFlags = []struct {
+	flag uint16
+	name string
+}{
+	{FunctionPreloadParent, "preloadParent"},
+	{FunctionPreloadRoot, "preloadRoot"},
+	{FunctionSuppressSuper, "suppressSuper"},
+	{FunctionPreloadSuper, "preloadSuper"},
+	{FunctionSuppressArguments, "suppressArguments"},
+	{FunctionPreloadArguments, "preloadArguments"},
+	{FunctionSuppressThis, "suppressThis"},
+	{FunctionPreloadThis, "preloadThis"},
+	{FunctionPreloadGlobal, "preloadGlobal"},
+}
+
+func (d *actionDumper) function2(p *ActionDefineFunction2) string {
+	var params []string
+	for _, param := range p.Parameters {
+		name := strconv.Quote(param.ParamName)
+		if param.Register != 0 {
+			name = registerName(param.Register) + "=" + name
+		}
+		params = append(params, name)
+	}
+	s := strconv.Quote(p.FunctionName) + " (" + strings.Join(params, ", ") + ") registers " + strconv.Itoa(int(p.RegisterCount))
+	for _, f := range functionFlags {
+		if p.Flags&f.flag != 0 {
+			s += " " + f.name
+		}
+	}
+	return s
+}
+
+// pushValue returns the textual value of a pushed value, constants are
+// resolved against the last constant pool
+func (d *actionDumper) pushValue(v PushValue) string {
+	switch v.Type {
+	case PushTypeString:
+		return strconv.Quote(v.String)
+	case PushTypeFloat:
+		return strconv.FormatFloat(float64(v.Float), 'g', -1, 32)
+	case PushTypeNull:
+		return "NULL"
+	case PushTypeUndefined:
+		return "UNDEF"
+	case PushTypeRegister:
+		return registerName(v.Register)
+	case PushTypeBoolean:
+		if v.Boolean {
+			return "TRUE"
+		}
+		return "FALSE"
+	case PushTypeDouble:
+		return strconv.FormatFloat(v.Double, 'g', -1, 64)
+	case PushTypeInteger:
+		return strconv.Itoa(int(v.Integer))
+	}
+	if int(v.Constant) < len(d.constants) {
+		return strconv.Quote(d.constants[v.Constant])
+	}
+	return "c:" + strconv.Itoa(int(v.Constant))
+}
+
+// registerName returns the textual name of a register
+func registerName(r uint8) string {
+	return "r:" + strconv.Itoa(int(r))
+}
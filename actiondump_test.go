@@ -0,0 +1,62 @@
+package swf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDisassembleActions(t *testing.T) {
+	expected := `label1:
+constants "a", "trace"
+push "s", 1.5, NULL, UNDEF, r:1, TRUE, 2.5, -1, "a", "trace"
+function2 "f" (r:1="x") registers 3 preloadThis preloadGlobal
+  return
+end // of function f
+with
+  pop
+end // of with
+try r:2
+  throw
+catch
+  pop
+end // of try
+branch label1
+branchIfTrue label2
+label2:
+gotoFrame2 play bias 5
+`
+	var buf bytes.Buffer
+	if err := DisassembleActions(&buf, actionsBytes); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+}
+
+func TestDisassembleActionsNested(t *testing.T) {
+	// An anonymous function holding a try block with a finally part only
+	code := []byte{
+		0x9b, 0x05, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x00, // DefineFunction
+		0x8f, 0x09, 0x00, 0x02, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x65, 0x00, // Try
+		0x07,                         // Stop
+		0x06,                         // Play
+		0x96, 0x02, 0x00, 0x08, 0x03, // Push
+	}
+	expected := `function "" ()
+  try "e"
+    stop
+  finally
+    play
+  end // of try
+end // of function
+push c:3
+`
+	var buf bytes.Buffer
+	if err := DisassembleActions(&buf, code); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrEmbeddedDepth means that an embedded Swf file is not parsed.
+// It is nested deeper than maxEmbeddedDepth, it is only reported in lenient
+// mode
+var ErrEmbeddedDepth = errors.New("embedded file nested too deep")
+
+// maxEmbeddedDepth is the number of nested embedded files parsed, the
+// payloads of deeper files are kept raw
+const maxEmbeddedDepth = 8
+
+// ParseEmbedded makes the parser recursively parse the DefineBinaryData
+// payloads that are Swf files, starting with a FWS, CWS or ZWS signature.
+// Embedded files are parsed with the same options, see TagDefineBinaryData
+func ParseEmbedded() Option {
+	return func(p *parser) {
+		p.embedded = true
+	}
+}
+
+// embeddedDepth sets the nesting depth of an embedded file
+func embeddedDepth(depth int) Option {
+	return func(p *parser) {
+		p.depth = depth
+	}
+}
+
+// isSwf reports whether data starts with a Swf signature
+func isSwf(data []byte) bool {
+	if len(data) < 3 || data[1] != 'W' || data[2] != 'S' {
+		return false
+	}
+	return data[0] == 'F' || data[0] == 'C' || data[0] == 'Z'
+}
+
+func (p *parser) ParseTagDefineBinaryData(length uint32) (Tag, error) {
+	characterID, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "DefineBinaryData.Tag")
+	}
+	if _, err = p.r.ReadUInt32(); err != nil {
+		return nil, p.fail(err, "DefineBinaryData.Reserved")
+	}
+	data, err := p.readAll("DefineBinaryData.Data")
+	if err != nil {
+		return nil, err
+	}
+	t := &TagDefineBinaryData{tag{code: CodeTagDefineBinaryData, length: length}, characterID, data, nil}
+
+	if p.embedded && isSwf(t.Data) && p.depth >= maxEmbeddedDepth {
+		if p.lenient {
+			p.warn(p.fail(ErrEmbeddedDepth, "DefineBinaryData.Data"))
+		}
+		return t, nil
+	}
+	if p.embedded && isSwf(t.Data) {
+		opts := append(append([]Option{}, p.opts...), embeddedDepth(p.depth+1))
+		embedded, err := Parse(bytes.NewReader(t.Data), opts...)
+		if err != nil {
+			// The error of the embedded file is wrapped, not replaced
+			offset, _ := p.r.Seek(0, io.SeekCurrent)
+			return nil, &ParseError{offset, p.tagIndex, p.tagCode, "DefineBinaryData.Data", err}
+		}
+		t.Swf = &embedded
+	}
+	return t, nil
+}
+
+func (s *serializer) SerializeTagDefineBinaryData(t *TagDefineBinaryData) error {
+	if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt32(0); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.Data)
+	return err
+}
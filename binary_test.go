@@ -0,0 +1,121 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestParseTagDefineBinaryData(t *testing.T) {
+	tagsBytes := []byte{
+		0xc9, 0x15, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2a, 0x2b, 0x2c,
+		0x00, 0x00,
+	}
+	tags := roundTripTags(t, tagsBytes)
+	correct := &TagDefineBinaryData{tag{code: CodeTagDefineBinaryData, length: 9}, 3, []byte{0x2a, 0x2b, 0x2c}, nil}
+	if !reflect.DeepEqual(tags[0], correct) {
+		t.Errorf("expected %v, got %v", correct, tags[0])
+	}
+}
+
+func TestParseEmbedded(t *testing.T) {
+	embedded := Swf{
+		Header: Header{Compression: CompressionZlib, Version: 10, FrameSize: Rect{16, 0, 25600, 0, 20480}, FrameRate: 24, FrameCount: 1},
+		Tags: []Tag{
+			&TagDefineBinaryData{tag{code: CodeTagDefineBinaryData, length: 0}, 1, []byte("FWS"), nil},
+			&tag{code: CodeTagEnd, length: 0},
+		},
+	}
+	var data bytes.Buffer
+	if err := Write(&data, embedded); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	outer := embedded
+	outer.Tags = []Tag{
+		&TagDefineBinaryData{tag{code: CodeTagDefineBinaryData, length: 0}, 2, data.Bytes(), nil},
+		&tag{code: CodeTagEnd, length: 0},
+	}
+	var file bytes.Buffer
+	if err := Write(&file, outer); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	s, err := Parse(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if binaryData := s.Tags[0].(*TagDefineBinaryData); binaryData.Swf != nil {
+		t.Errorf("expected nil, got %v", binaryData.Swf)
+	}
+
+	// The innermost payload looks like a Swf file but is truncated
+	_, err = Parse(bytes.NewReader(file.Bytes()), ParseEmbedded())
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Record != "DefineBinaryData.Data" {
+		t.Errorf("expected a DefineBinaryData.Data *ParseError, got %v", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	s, err = Parse(bytes.NewReader(file.Bytes()), ParseEmbedded(), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	nested := s.Tags[0].(*TagDefineBinaryData).Swf
+	if nested == nil || !reflect.DeepEqual(nested.Header.FrameSize, embedded.Header.FrameSize) {
+		t.Fatalf("expected the embedded Swf, got %v", nested)
+	}
+	if _, ok := nested.Tags[0].(*UnknownTag); !ok || len(nested.Warnings) != 1 {
+		t.Errorf("expected the innermost payload to be kept as *UnknownTag, got %v", nested.Tags)
+	}
+}
+
+func TestParseEmbeddedDepth(t *testing.T) {
+	file := Swf{
+		Header: Header{Version: 10, FrameSize: Rect{16, 0, 25600, 0, 20480}, FrameRate: 24, FrameCount: 1},
+		Tags:   []Tag{&tag{code: CodeTagEnd, length: 0}},
+	}
+	var data bytes.Buffer
+	for i := 0; i <= maxEmbeddedDepth+1; i++ {
+		if err := Write(&data, file); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		file.Tags = []Tag{
+			&TagDefineBinaryData{tag{code: CodeTagDefineBinaryData, length: 0}, 1, append([]byte{}, data.Bytes()...), nil},
+			&tag{code: CodeTagEnd, length: 0},
+		}
+		data.Reset()
+	}
+	if err := Write(&data, file); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	for _, lenient := range []bool{false, true} {
+		opts := []Option{ParseEmbedded()}
+		if lenient {
+			opts = append(opts, Lenient())
+		}
+		s, err := Parse(bytes.NewReader(data.Bytes()), opts...)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		depth := 0
+		for {
+			binaryData := s.Tags[0].(*TagDefineBinaryData)
+			if binaryData.Swf == nil {
+				break
+			}
+			s = *binaryData.Swf
+			depth++
+		}
+		if depth != maxEmbeddedDepth {
+			t.Errorf("expected %v, got %v", maxEmbeddedDepth, depth)
+		}
+		if lenient && (len(s.Warnings) != 1 || !errors.Is(s.Warnings[0], ErrEmbeddedDepth)) {
+			t.Errorf("expected an ErrEmbeddedDepth warning, got %v", s.Warnings)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// ErrAlphaData means that the alpha channel of a bitmap can not be applied.
+// It does not hold one byte per pixel
+var ErrAlphaData = errors.New("alpha data does not match the image size")
+
+var (
+	pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	gifSignature = []byte("GIF89a")
+)
+
+// cleanJPEG rebuilds a JPEG stream made of a single SOI, the segments of
+// data, and an EOI. It removes the erroneous EOI/SOI pair older encoders
+// put at the start of the stream, and merges JPEGTables with the image data
+func cleanJPEG(data ...[]byte) []byte {
+	out := []byte{0xff, 0xd8}
+	for _, d := range data {
+		for i := 0; i+1 < len(d); {
+			if d[i] != 0xff {
+				// Not a marker, keep the rest as is
+				return append(out, d[i:]...)
+			}
+			marker := d[i+1]
+			switch {
+			case marker == 0xff:
+				// Fill byte
+				i++
+			case marker == 0xd8 || marker == 0xd9:
+				// SOI and EOI are written once
+				i += 2
+			case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+				out = append(out, d[i:i+2]...)
+				i += 2
+			case marker == 0xda:
+				// The entropy coded data follows the SOS segment
+				return append(out, d[i:]...)
+			default:
+				if i+4 > len(d) {
+					return append(out, d[i:]...)
+				}
+				end := i + 2 + int(binary.BigEndian.Uint16(d[i+2:]))
+				if end > len(d) {
+					end = len(d)
+				}
+				out = append(out, d[i:end]...)
+				i = end
+			}
+		}
+	}
+	return append(out, 0xff, 0xd9)
+}
+
+// decodeImageData decodes JPEG data, or PNG and GIF data since Swf 8
+func decodeImageData(data ...[]byte) (image.Image, error) {
+	if len(data) == 1 && bytes.HasPrefix(data[0], pngSignature) {
+		return png.Decode(bytes.NewReader(data[0]))
+	}
+	if len(data) == 1 && bytes.HasPrefix(data[0], gifSignature) {
+		return gif.Decode(bytes.NewReader(data[0]))
+	}
+	return jpeg.Decode(bytes.NewReader(cleanJPEG(data...)))
+}
+
+// applyAlpha combines an image with a zlib compressed alpha channel.
+// The colors of the image are already premultiplied by the alpha
+func applyAlpha(img image.Image, alphaData []byte) (image.Image, error) {
+	r, err := zlib.NewReader(bytes.NewReader(alphaData))
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	alpha := make([]byte, bounds.Dx()*bounds.Dy())
+	if _, err = io.ReadFull(r, alpha); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrAlphaData
+		}
+		return nil, err
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.RGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			c.A = alpha[y*bounds.Dx()+x]
+			rgba.SetRGBA(x, y, premultiplied(c))
+		}
+	}
+	return rgba, nil
+}
+
+// Image decodes the JPEG data, merged with the JPEGTables of the file
+func (t *TagDefineBits) Image() (image.Image, error) {
+	return decodeImageData(t.JPEGTables, t.JPEGData)
+}
+
+// Image decodes the image data, which is JPEG, PNG or GIF data
+func (t *TagDefineBitsJPEG2) Image() (image.Image, error) {
+	return decodeImageData(t.ImageData)
+}
+
+// Image decodes the image data and applies the alpha channel to JPEG data.
+// PNG and GIF data hold their own alpha channel
+func (t *TagDefineBitsJPEG3) Image() (image.Image, error) {
+	img, err := decodeImageData(t.ImageData)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(t.ImageData, pngSignature) || bytes.HasPrefix(t.ImageData, gifSignature) {
+		return img, nil
+	}
+	return applyAlpha(img, t.BitmapAlphaData)
+}
+
+func (p *parser) ParseTagDefineBits(length uint32) (Tag, error) {
+	characterID, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "DefineBits.CharacterID")
+	}
+	data, err := p.readAll("DefineBits.JPEGData")
+	if err != nil {
+		return nil, err
+	}
+	return &TagDefineBits{tag{code: CodeTagDefineBits, length: length}, characterID, data, p.jpegTables}, nil
+}
+
+func (p *parser) ParseTagJPEGTables(length uint32) (Tag, error) {
+	data, err := p.readAll("JPEGTables.JPEGData")
+	if err != nil {
+		return nil, err
+	}
+	p.jpegTables = data
+	return &TagJPEGTables{tag{code: CodeTagJPEGTables, length: length}, data}, nil
+}
+
+func (p *parser) ParseTagDefineBitsJPEG2(length uint32) (Tag, error) {
+	characterID, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "DefineBitsJPEG2.CharacterID")
+	}
+	data, err := p.readAll("DefineBitsJPEG2.ImageData")
+	if err != nil {
+		return nil, err
+	}
+	return &TagDefineBitsJPEG2{tag{code: CodeTagDefineBitsJPEG2, length: length}, characterID, data}, nil
+}
+
+func (p *parser) ParseTagDefineBitsJPEG3(length uint32) (Tag, error) {
+	return p.parseTagDefineBitsJPEG3or4(CodeTagDefineBitsJPEG3, length)
+}
+
+// ParseTagDefineBitsJPEG4 parses a DefineBitsJPEG4 tag, which only adds
+// DeblockParam to DefineBitsJPEG3
+func (p *parser) ParseTagDefineBitsJPEG4(length uint32) (Tag, error) {
+	return p.parseTagDefineBitsJPEG3or4(CodeTagDefineBitsJPEG4, length)
+}
+
+func (p *parser) parseTagDefineBitsJPEG3or4(code uint16, length uint32) (Tag, error) {
+	record := "DefineBitsJPEG3"
+	if code == CodeTagDefineBitsJPEG4 {
+		record = "DefineBitsJPEG4"
+	}
+
+	t := &TagDefineBitsJPEG3{tag: tag{code: code, length: length}}
+	var err error
+	if t.CharacterID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, record+".CharacterID")
+	}
+	alphaDataOffset, err := p.r.ReadUInt32()
+	if err != nil {
+		return nil, p.fail(err, record+".AlphaDataOffset")
+	}
+	header := uint32(6)
+	if code == CodeTagDefineBitsJPEG4 {
+		if t.DeblockParam, err = p.r.ReadFixed8(); err != nil {
+			return nil, p.fail(err, record+".DeblockParam")
+		}
+		header += 2
+	}
+	// The offset is checked against the payload before allocating
+	if length < header || alphaDataOffset > length-header {
+		return nil, p.fail(io.ErrUnexpectedEOF, record+".ImageData")
+	}
+	t.ImageData = make([]byte, alphaDataOffset)
+	if _, err = io.ReadFull(p.r, t.ImageData); err != nil {
+		return nil, p.fail(err, record+".ImageData")
+	}
+	if t.BitmapAlphaData, err = p.readAll(record + ".BitmapAlphaData"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *serializer) SerializeTagDefineBits(t *TagDefineBits) error {
+	if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.JPEGData)
+	return err
+}
+
+func (s *serializer) SerializeTagDefineBitsJPEG2(t *TagDefineBitsJPEG2) error {
+	if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.ImageData)
+	return err
+}
+
+// SerializeTagDefineBitsJPEG3 serializes a DefineBitsJPEG3 or a
+// DefineBitsJPEG4 tag, according to its code
+func (s *serializer) SerializeTagDefineBitsJPEG3(t *TagDefineBitsJPEG3) error {
+	if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt32(uint32(len(t.ImageData))); err != nil {
+		return err
+	}
+	if t.Code() == CodeTagDefineBitsJPEG4 {
+		if err := s.w.WriteFixed8(t.DeblockParam); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write(t.ImageData); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.BitmapAlphaData)
+	return err
+}
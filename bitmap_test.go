@@ -0,0 +1,147 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"reflect"
+	"testing"
+)
+
+// encodeJPEG encodes a 2x2 gray image
+func encodeJPEG(t *testing.T) []byte {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func compressZlib(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func checkGray(t *testing.T, img image.Image, err error) {
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 2, 2) {
+		t.Fatalf("expected a 2x2 image, got %v", img.Bounds())
+	}
+	if r, _, _, _ := img.At(1, 1).RGBA(); r>>8 < 190 || r>>8 > 210 {
+		t.Errorf("expected a gray around 200, got %v", r>>8)
+	}
+}
+
+func TestParseTagDefineBits(t *testing.T) {
+	data := encodeJPEG(t)
+	// Split the tables from the image, as JPEGTables and DefineBits do
+	sof := bytes.Index(data, []byte{0xff, 0xc0})
+	tables := append(append([]byte{}, data[:sof]...), 0xff, 0xd9)
+	jpegData := append([]byte{0xff, 0xd8}, data[sof:]...)
+
+	var buf bytes.Buffer
+	s := newSerializer(&buf)
+	tags := []Tag{
+		&TagJPEGTables{tag{code: CodeTagJPEGTables, length: 0}, tables},
+		&TagDefineBits{tag{code: CodeTagDefineBits, length: 0}, 1, jpegData, nil},
+		&tag{code: CodeTagEnd, length: 0},
+	}
+	if err := s.SerializeTags(tags); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	tags = roundTripTags(t, buf.Bytes())
+
+	defineBits, ok := tags[1].(*TagDefineBits)
+	if !ok {
+		t.Fatalf("expected a *TagDefineBits, got %v", tags[1])
+	}
+	if defineBits.CharacterID != 1 || !bytes.Equal(defineBits.JPEGTables, tables) {
+		t.Errorf("expected the JPEGTables to be merged, got %v", defineBits)
+	}
+	img, err := defineBits.Image()
+	checkGray(t, img, err)
+}
+
+func TestParseTagDefineBitsJPEG2(t *testing.T) {
+	// Older encoders start the stream with an erroneous EOI/SOI pair
+	data := append([]byte{0xff, 0xd9, 0xff, 0xd8}, encodeJPEG(t)...)
+	tagsBytes := []byte{0x7f, 0x05, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00}
+	binary.LittleEndian.PutUint32(tagsBytes[2:], uint32(len(data)+2))
+	tagsBytes = append(tagsBytes, data...)
+	tags := roundTripTags(t, append(tagsBytes, 0x00, 0x00))
+
+	correct := &TagDefineBitsJPEG2{tag{code: CodeTagDefineBitsJPEG2, length: uint32(len(data) + 2), long: true}, 2, data}
+	if !reflect.DeepEqual(tags[0], correct) {
+		t.Errorf("expected %v, got %v", correct, tags[0])
+	}
+	img, err := correct.Image()
+	checkGray(t, img, err)
+}
+
+func TestParseTagDefineBitsJPEG3(t *testing.T) {
+	data := encodeJPEG(t)
+	alpha := compressZlib(t, []byte{255, 0, 128, 255})
+
+	for _, code := range []uint16{CodeTagDefineBitsJPEG3, CodeTagDefineBitsJPEG4} {
+		var buf bytes.Buffer
+		s := newSerializer(&buf)
+		original := &TagDefineBitsJPEG3{tag{code: code, length: 0}, 3, 0, data, alpha}
+		if code == CodeTagDefineBitsJPEG4 {
+			original.DeblockParam = 1.5
+		}
+		if err := s.SerializeTags([]Tag{original, &tag{code: CodeTagEnd, length: 0}}); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		tags := roundTripTags(t, buf.Bytes())
+
+		parsed, ok := tags[0].(*TagDefineBitsJPEG3)
+		if !ok {
+			t.Fatalf("expected a *TagDefineBitsJPEG3, got %v", tags[0])
+		}
+		original.tag = parsed.tag
+		if !reflect.DeepEqual(parsed, original) {
+			t.Errorf("expected %v, got %v", original, parsed)
+		}
+
+		img, err := parsed.Image()
+		checkGray(t, img, err)
+		if c := img.At(1, 0).(color.RGBA); c != (color.RGBA{}) {
+			t.Errorf("expected a transparent pixel, got %v", c)
+		}
+		if c := img.At(0, 1).(color.RGBA); c.A != 128 || c.R > 128 {
+			t.Errorf("expected a premultiplied pixel, got %v", c)
+		}
+	}
+
+	short := &TagDefineBitsJPEG3{tag{code: CodeTagDefineBitsJPEG3, length: 0}, 3, 0, data, compressZlib(t, []byte{255})}
+	if _, err := short.Image(); err != ErrAlphaData {
+		t.Errorf("expected ErrAlphaData, got %v", err)
+	}
+}
+
+func TestParseTagDefineBitsJPEG3AlphaDataOffset(t *testing.T) {
+	// AlphaDataOffset points past the payload
+	tagsBytes := []byte{0xc8, 0x08, 0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00}
+	_, err := newParser(bytes.NewReader(tagsBytes)).ParseTags()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Record != "DefineBitsJPEG3.ImageData" {
+		t.Errorf("expected a *ParseError for DefineBitsJPEG3.ImageData, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package swf
+
+import (
+	"errors"
+	"image/color"
+)
+
+var colorTransformChannels = []string{"Red", "Green", "Blue", "Alpha"}
+
+// WithAlpha converts the color transform to a ColorTransformWithAlpha that
+// leaves the alpha channel unchanged
+func (cx ColorTransform) WithAlpha() ColorTransformWithAlpha {
+	return ColorTransformWithAlpha{
+		cx.HasAddTerms, cx.HasMultTerms, cx.NBits,
+		cx.RedMultTerm, cx.GreenMultTerm, cx.BlueMultTerm, 256,
+		cx.RedAddTerm, cx.GreenAddTerm, cx.BlueAddTerm, 0,
+	}
+}
+
+// Apply transforms a color, its alpha is left unchanged.
+// The color is not premultiplied by its alpha, as the colors of Swf records
+func (cx ColorTransform) Apply(c color.RGBA) color.RGBA {
+	return cx.WithAlpha().Apply(c)
+}
+
+// Apply transforms a color. Each channel is multiplied by its
+// multiplication term divided by 256, then added its addition term, and
+// clamped to [0, 255]. The color is not premultiplied by its alpha, as the
+// colors of Swf records
+func (cx ColorTransformWithAlpha) Apply(c color.RGBA) color.RGBA {
+	channel := func(v uint8, mult, add int16) uint8 {
+		x := int32(v)
+		if cx.HasMultTerms {
+			x = x * int32(mult) / 256
+		}
+		if cx.HasAddTerms {
+			x += int32(add)
+		}
+		if x < 0 {
+			return 0
+		} else if x > 0xff {
+			return 0xff
+		}
+		return uint8(x)
+	}
+	return color.RGBA{
+		channel(c.R, cx.RedMultTerm, cx.RedAddTerm),
+		channel(c.G, cx.GreenMultTerm, cx.GreenAddTerm),
+		channel(c.B, cx.BlueMultTerm, cx.BlueAddTerm),
+		channel(c.A, cx.AlphaMultTerm, cx.AlphaAddTerm),
+	}
+}
+
+// ParseColorTransform parses a ColorTransform record
+func (p *parser) ParseColorTransform() (cx ColorTransform, err error) {
+	cx.HasAddTerms, cx.HasMultTerms, cx.NBits, err = p.parseColorTransformTerms("CXFORM",
+		[]*int16{&cx.RedMultTerm, &cx.GreenMultTerm, &cx.BlueMultTerm},
+		[]*int16{&cx.RedAddTerm, &cx.GreenAddTerm, &cx.BlueAddTerm})
+	return cx, err
+}
+
+// ParseColorTransformWithAlpha parses a ColorTransformWithAlpha record
+func (p *parser) ParseColorTransformWithAlpha() (cx ColorTransformWithAlpha, err error) {
+	cx.HasAddTerms, cx.HasMultTerms, cx.NBits, err = p.parseColorTransformTerms("CXFORMWITHALPHA",
+		[]*int16{&cx.RedMultTerm, &cx.GreenMultTerm, &cx.BlueMultTerm, &cx.AlphaMultTerm},
+		[]*int16{&cx.RedAddTerm, &cx.GreenAddTerm, &cx.BlueAddTerm, &cx.AlphaAddTerm})
+	return cx, err
+}
+
+// parseColorTransformTerms parses the flags and the terms of a color
+// transform, one term per channel. The record starts on a byte boundary
+func (p *parser) parseColorTransformTerms(record string, mult, add []*int16) (hasAdd, hasMult bool, nBits uint8, err error) {
+	p.align()
+	flags, err := p.r.ReadUBitValue(6)
+	if err != nil {
+		return false, false, 0, p.fail(err, record+".Flags")
+	}
+	hasAdd, hasMult, nBits = flags&0x20 != 0, flags&0x10 != 0, uint8(flags&0xf)
+
+	terms := []struct {
+		has   bool
+		ptrs  []*int16
+		field string
+	}{
+		{hasMult, mult, "MultTerm"},
+		{hasAdd, add, "AddTerm"},
+	}
+	for _, term := range terms {
+		if !term.has {
+			continue
+		}
+		for i, ptr := range term.ptrs {
+			v, err := p.readBits(nBits, record+"."+colorTransformChannels[i]+term.field)
+			if err != nil {
+				return false, false, 0, err
+			}
+			*ptr = int16(v)
+		}
+	}
+	return hasAdd, hasMult, nBits, nil
+}
+
+// SerializeColorTransform serializes a ColorTransform record
+func (s *serializer) SerializeColorTransform(cx ColorTransform) error {
+	return s.serializeColorTransformTerms(cx.HasAddTerms, cx.HasMultTerms, cx.NBits,
+		[]int16{cx.RedMultTerm, cx.GreenMultTerm, cx.BlueMultTerm},
+		[]int16{cx.RedAddTerm, cx.GreenAddTerm, cx.BlueAddTerm})
+}
+
+// SerializeColorTransformWithAlpha serializes a ColorTransformWithAlpha record
+func (s *serializer) SerializeColorTransformWithAlpha(cx ColorTransformWithAlpha) error {
+	return s.serializeColorTransformTerms(cx.HasAddTerms, cx.HasMultTerms, cx.NBits,
+		[]int16{cx.RedMultTerm, cx.GreenMultTerm, cx.BlueMultTerm, cx.AlphaMultTerm},
+		[]int16{cx.RedAddTerm, cx.GreenAddTerm, cx.BlueAddTerm, cx.AlphaAddTerm})
+}
+
+// serializeColorTransformTerms serializes the flags and the terms of a color
+// transform. NBits is increased when it is too small to hold the terms
+func (s *serializer) serializeColorTransformTerms(hasAdd, hasMult bool, nBits uint8, mult, add []int16) error {
+	var values []int32
+	var flags uint32
+	if hasAdd {
+		flags |= 0x20
+	}
+	if hasMult {
+		flags |= 0x10
+		for _, v := range mult {
+			values = append(values, int32(v))
+		}
+	}
+	if hasAdd {
+		for _, v := range add {
+			values = append(values, int32(v))
+		}
+	}
+	if len(values) > 0 {
+		if needed := signedBits(values...); needed > nBits {
+			nBits = needed
+		}
+	}
+
+	if nBits > 0xf {
+		return errors.New("bit value overflows its bit count")
+	}
+
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if err := s.w.WriteUBitValue(flags|uint32(nBits), 6); err != nil {
+		return err
+	}
+	for _, v := range values {
+		if err := s.writeBits(v, nBits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package swf
+
+import (
+	"bytes"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestParseColorTransformWithAlpha(t *testing.T) {
+	cxformBytes := []byte{0xe9, 0x00, 0x20, 0x00, 0x04, 0x03, 0xf6, 0x00, 0x0f, 0xf0, 0x00}
+	cx, err := newParser(bytes.NewReader(cxformBytes)).ParseColorTransformWithAlpha()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := ColorTransformWithAlpha{true, true, 10, 256, 128, 0, 256, -10, 0, 255, 0}
+	if !reflect.DeepEqual(cx, expected) {
+		t.Errorf("expected %#v, got %#v", expected, cx)
+	}
+
+	var buf bytes.Buffer
+	s := newSerializer(&buf)
+	if err = s.SerializeColorTransformWithAlpha(cx); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err = s.w.Flush(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), cxformBytes) {
+		t.Errorf("expected %#v, got %#v", cxformBytes, buf.Bytes())
+	}
+
+	c := cx.Apply(color.RGBA{0x05, 0x80, 0x80, 0x80})
+	if expected := (color.RGBA{0, 0x40, 0xff, 0x80}); c != expected {
+		t.Errorf("expected %v, got %v", expected, c)
+	}
+}
+
+func TestColorTransform(t *testing.T) {
+	// Only add terms, the alpha channel is left unchanged
+	cxformBytes := []byte{0x99, 0x06, 0x20}
+	cx, err := newParser(bytes.NewReader(cxformBytes)).ParseColorTransform()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := ColorTransform{HasAddTerms: true, NBits: 6, RedAddTerm: 16, GreenAddTerm: 24, BlueAddTerm: -32}
+	if !reflect.DeepEqual(cx, expected) {
+		t.Errorf("expected %#v, got %#v", expected, cx)
+	}
+
+	c := cx.Apply(color.RGBA{0x10, 0x10, 0x10, 0x10})
+	if expected := (color.RGBA{0x20, 0x28, 0, 0x10}); c != expected {
+		t.Errorf("expected %v, got %v", expected, c)
+	}
+}
@@ -0,0 +1,92 @@
+package swf
+
+// These are the flags of a FileAttributes tag
+const (
+	fileAttributeUseNetwork    = 0x01
+	fileAttributeActionScript3 = 0x08
+	fileAttributeHasMetadata   = 0x10
+	fileAttributeUseGPU        = 0x20
+	fileAttributeUseDirectBlit = 0x40
+
+	fileAttributeFlags = fileAttributeUseNetwork | fileAttributeActionScript3 | fileAttributeHasMetadata | fileAttributeUseGPU | fileAttributeUseDirectBlit
+)
+
+func (p *parser) ParseTagFileAttributes(length uint32) (Tag, error) {
+	// The flags are held by the first byte of the little endian 32 bits
+	flags, err := p.r.ReadUInt32()
+	if err != nil {
+		return nil, p.fail(err, "FileAttributes.Flags")
+	}
+	return &TagFileAttributes{
+		tag{code: CodeTagFileAttributes, length: length},
+		flags&fileAttributeUseDirectBlit != 0,
+		flags&fileAttributeUseGPU != 0,
+		flags&fileAttributeHasMetadata != 0,
+		flags&fileAttributeActionScript3 != 0,
+		flags&fileAttributeUseNetwork != 0,
+		flags &^ fileAttributeFlags,
+	}, nil
+}
+
+func (p *parser) ParseTagSetBackgroundColor(length uint32) (Tag, error) {
+	color, err := p.ParseRGB()
+	if err != nil {
+		return nil, err
+	}
+	return &TagSetBackgroundColor{tag{code: CodeTagSetBackgroundColor, length: length}, color}, nil
+}
+
+func (p *parser) ParseTagMetadata(length uint32) (Tag, error) {
+	metadata, err := p.r.ReadString()
+	if err != nil {
+		return nil, p.fail(err, "Metadata.Metadata")
+	}
+	return &TagMetadata{tag{code: CodeTagMetadata, length: length}, metadata}, nil
+}
+
+func (p *parser) ParseTagScriptLimits(length uint32) (Tag, error) {
+	maxRecursionDepth, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "ScriptLimits.MaxRecursionDepth")
+	}
+	scriptTimeoutSeconds, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "ScriptLimits.ScriptTimeoutSeconds")
+	}
+	return &TagScriptLimits{tag{code: CodeTagScriptLimits, length: length}, maxRecursionDepth, scriptTimeoutSeconds}, nil
+}
+
+func (s *serializer) SerializeTagFileAttributes(t *TagFileAttributes) error {
+	flags := t.ReservedFlags &^ fileAttributeFlags
+	fields := []struct {
+		set  bool
+		flag uint32
+	}{
+		{t.UseDirectBlit, fileAttributeUseDirectBlit},
+		{t.UseGPU, fileAttributeUseGPU},
+		{t.HasMetadata, fileAttributeHasMetadata},
+		{t.ActionScript3, fileAttributeActionScript3},
+		{t.UseNetwork, fileAttributeUseNetwork},
+	}
+	for _, field := range fields {
+		if field.set {
+			flags |= field.flag
+		}
+	}
+	return s.w.WriteUInt32(flags)
+}
+
+func (s *serializer) SerializeTagSetBackgroundColor(t *TagSetBackgroundColor) error {
+	return s.SerializeRGB(t.BackgroundColor)
+}
+
+func (s *serializer) SerializeTagMetadata(t *TagMetadata) error {
+	return s.w.WriteString(t.Metadata)
+}
+
+func (s *serializer) SerializeTagScriptLimits(t *TagScriptLimits) error {
+	if err := s.w.WriteUInt16(t.MaxRecursionDepth); err != nil {
+		return err
+	}
+	return s.w.WriteUInt16(t.ScriptTimeoutSeconds)
+}
@@ -0,0 +1,28 @@
+package swf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseControlTags(t *testing.T) {
+	tagsBytes := []byte{
+		0x44, 0x11, 0xdf, 0x00, 0x01, 0x80, // FileAttributes
+		0x43, 0x02, 0x10, 0x20, 0x30, // SetBackgroundColor
+		0x45, 0x13, 0x3c, 0x61, 0x2f, 0x3e, 0x00, // Metadata
+		0x44, 0x10, 0xe8, 0x03, 0x0f, 0x00, // ScriptLimits
+		0x00, 0x00,
+	}
+	tags := roundTripTags(t, tagsBytes)
+
+	correct := []Tag{
+		&TagFileAttributes{tag{code: CodeTagFileAttributes, length: 4}, true, false, true, true, true, 0x80010086},
+		&TagSetBackgroundColor{tag{code: CodeTagSetBackgroundColor, length: 3}, RGB{0x10, 0x20, 0x30}},
+		&TagMetadata{tag{code: CodeTagMetadata, length: 5}, "<a/>"},
+		&TagScriptLimits{tag{code: CodeTagScriptLimits, length: 4}, 1000, 15},
+		&tag{code: CodeTagEnd, length: 0},
+	}
+	if !reflect.DeepEqual(tags, correct) {
+		t.Errorf("expected %v, got %v", correct, tags)
+	}
+}
@@ -0,0 +1,91 @@
+package swf
+
+// TagDecoder decodes the payload of a tag.
+// r only holds the payload, which is length bytes long
+type TagDecoder func(r Reader, code uint16, length uint32) (Tag, error)
+
+// Option configures a Parser
+type Option func(p *parser)
+
+// RegisterTagDecoder makes the parser decode the tags of the given code
+// with fn. It overrides the built-in decoder of the code, if any
+func RegisterTagDecoder(code uint16, fn TagDecoder) Option {
+	return func(p *parser) {
+		p.decoders[code] = fn
+	}
+}
+
+// DisableTagDecoder makes the parser keep the tags of the given code
+// as UnknownTag, for instance to skip the decoding of DoABC tags
+func DisableTagDecoder(code uint16) Option {
+	return func(p *parser) {
+		delete(p.decoders, code)
+	}
+}
+
+// Lenient makes the parser recover from truncated or corrupt files.
+// Parsing stops at the first unrecoverable error, and the tags decoded
+// before it are returned. Errors are reported in Swf.Warnings instead.
+// A FileLength mismatch, a missing End tag, tag lengths that overrun the
+// file and tags that fail to decode, kept as UnknownTag, are tolerated
+func Lenient() Option {
+	return func(p *parser) {
+		p.lenient = true
+	}
+}
+
+// builtinDecoder turns a decoding method of the parser into a TagDecoder.
+// The method reads the payload in place of the parser reader, so that the
+// state of the parser, such as the warnings, is shared
+func (p *parser) builtinDecoder(fn func(*parser, uint32) (Tag, error)) TagDecoder {
+	return func(r Reader, code uint16, length uint32) (Tag, error) {
+		origin := p.r
+		p.r = r
+		defer func() { p.r = origin }()
+		return fn(p, length)
+	}
+}
+
+func (p *parser) registerBuiltinDecoders() {
+	p.decoders = map[uint16]TagDecoder{
+		CodeTagEnd:                          p.builtinDecoder((*parser).ParseTagEnd),
+		CodeTagShowFrame:                    p.builtinDecoder((*parser).ParseTagShowFrame),
+		CodeTagPlaceObject:                  p.builtinDecoder((*parser).ParseTagPlaceObject),
+		CodeTagPlaceObject2:                 p.builtinDecoder((*parser).ParseTagPlaceObject2),
+		CodeTagPlaceObject3:                 p.builtinDecoder((*parser).ParseTagPlaceObject3),
+		CodeTagRemoveObject:                 p.builtinDecoder((*parser).ParseTagRemoveObject),
+		CodeTagRemoveObject2:                p.builtinDecoder((*parser).ParseTagRemoveObject2),
+		CodeTagDoAction:                     p.builtinDecoder((*parser).ParseTagDoAction),
+		CodeTagDoInitAction:                 p.builtinDecoder((*parser).ParseTagDoInitAction),
+		CodeTagDefineSound:                  p.builtinDecoder((*parser).ParseTagDefineSound),
+		CodeTagStartSound:                   p.builtinDecoder((*parser).ParseTagStartSound),
+		CodeTagStartSound2:                  p.builtinDecoder((*parser).ParseTagStartSound2),
+		CodeTagSoundStreamHead:              p.builtinDecoder((*parser).ParseTagSoundStreamHead),
+		CodeTagSoundStreamHead2:             p.builtinDecoder((*parser).ParseTagSoundStreamHead2),
+		CodeTagSoundStreamBlock:             p.builtinDecoder((*parser).ParseTagSoundStreamBlock),
+		CodeTagDefineSprite:                 p.builtinDecoder((*parser).ParseTagDefineSprite),
+		CodeTagFrameLabel:                   p.builtinDecoder((*parser).ParseTagFrameLabel),
+		CodeTagDefineSceneAndFrameLabelData: p.builtinDecoder((*parser).ParseTagDefineSceneAndFrameLabelData),
+		CodeTagDoABC:                        p.builtinDecoder((*parser).ParseTagDoABC),
+		CodeTagFileAttributes:               p.builtinDecoder((*parser).ParseTagFileAttributes),
+		CodeTagSetBackgroundColor:           p.builtinDecoder((*parser).ParseTagSetBackgroundColor),
+		CodeTagMetadata:                     p.builtinDecoder((*parser).ParseTagMetadata),
+		CodeTagScriptLimits:                 p.builtinDecoder((*parser).ParseTagScriptLimits),
+		CodeTagSymbolClass:                  p.builtinDecoder((*parser).ParseTagSymbolClass),
+		CodeTagExportAssets:                 p.builtinDecoder((*parser).ParseTagExportAssets),
+		CodeTagImportAssets:                 p.builtinDecoder((*parser).ParseTagImportAssets),
+		CodeTagImportAssets2:                p.builtinDecoder((*parser).ParseTagImportAssets2),
+		CodeTagDefineBinaryData:             p.builtinDecoder((*parser).ParseTagDefineBinaryData),
+		CodeTagDefineBits:                   p.builtinDecoder((*parser).ParseTagDefineBits),
+		CodeTagJPEGTables:                   p.builtinDecoder((*parser).ParseTagJPEGTables),
+		CodeTagDefineBitsJPEG2:              p.builtinDecoder((*parser).ParseTagDefineBitsJPEG2),
+		CodeTagDefineBitsJPEG3:              p.builtinDecoder((*parser).ParseTagDefineBitsJPEG3),
+		CodeTagDefineBitsJPEG4:              p.builtinDecoder((*parser).ParseTagDefineBitsJPEG4),
+		CodeTagDefineBitsLossless:           p.builtinDecoder((*parser).ParseTagDefineBitsLossless),
+		CodeTagDefineBitsLossless2:          p.builtinDecoder((*parser).ParseTagDefineBitsLossless2),
+		CodeTagDefineShape:                  p.builtinDecoder((*parser).ParseTagDefineShape),
+		CodeTagDefineShape2:                 p.builtinDecoder((*parser).ParseTagDefineShape2),
+		CodeTagDefineShape3:                 p.builtinDecoder((*parser).ParseTagDefineShape3),
+		CodeTagDefineShape4:                 p.builtinDecoder((*parser).ParseTagDefineShape4),
+	}
+}
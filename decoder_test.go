@@ -0,0 +1,81 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type colorTag struct {
+	tag
+	R, G, B uint8
+}
+
+func decodeColor(r Reader, code uint16, length uint32) (Tag, error) {
+	t := &colorTag{tag: tag{code: code, length: length}}
+	for _, ptr := range []*uint8{&t.R, &t.G, &t.B} {
+		v, err := r.ReadUInt8()
+		if err != nil {
+			return nil, err
+		}
+		*ptr = v
+	}
+	return t, nil
+}
+
+func TestRegisterTagDecoder(t *testing.T) {
+	p := newParser(bytes.NewReader(iteratorTagsBytes), RegisterTagDecoder(255, decodeColor))
+	tags, err := p.ParseTags()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	correct := &colorTag{tag{code: 255, length: 3}, 0xff, 0xff, 0xff}
+	if !reflect.DeepEqual(tags[0], correct) {
+		t.Errorf("expected %v, got %v", correct, tags[0])
+	}
+	if _, ok := tags[1].(*TagDoABC); !ok {
+		t.Errorf("expected a *TagDoABC, got %v", tags[1])
+	}
+
+	// Decoders can not read past the payload
+	tooLong := func(r Reader, code uint16, length uint32) (Tag, error) {
+		_, err := r.ReadUInt32()
+		return nil, err
+	}
+	p = newParser(bytes.NewReader(iteratorTagsBytes), RegisterTagDecoder(255, tooLong))
+	if _, err = p.ParseTags(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	// Built-in decoders can be overridden
+	errCustom := errors.New("custom")
+	failing := func(r Reader, code uint16, length uint32) (Tag, error) {
+		return nil, errCustom
+	}
+	p = newParser(bytes.NewReader(iteratorTagsBytes), RegisterTagDecoder(CodeTagDoABC, failing))
+	if _, err = p.ParseTags(); !errors.Is(err, errCustom) {
+		t.Errorf("expected %v, got %v", errCustom, err)
+	}
+}
+
+func TestDisableTagDecoder(t *testing.T) {
+	p := newParser(bytes.NewReader(iteratorTagsBytes), DisableTagDecoder(CodeTagDoABC))
+	tags, err := p.ParseTags()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	correct := &UnknownTag{tag{code: CodeTagDoABC, length: 10}, false, iteratorTagsBytes[7:17]}
+	if !reflect.DeepEqual(tags[1], correct) {
+		t.Errorf("expected %v, got %v", correct, tags[1])
+	}
+
+	var buf bytes.Buffer
+	if err = newSerializer(&buf).SerializeTags(tags); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), iteratorTagsBytes) {
+		t.Errorf("expected %#v, got %#v", iteratorTagsBytes, buf.Bytes())
+	}
+}
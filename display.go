@@ -0,0 +1,484 @@
+package swf
+
+import (
+	"io"
+)
+
+// These represent the blend modes of a PlaceObject3
+const (
+	BlendModeNormal     = 1  // BlendModeNormal is the normal blend mode, 0 is normal too
+	BlendModeLayer      = 2  // BlendModeLayer is the layer blend mode
+	BlendModeMultiply   = 3  // BlendModeMultiply is the multiply blend mode
+	BlendModeScreen     = 4  // BlendModeScreen is the screen blend mode
+	BlendModeLighten    = 5  // BlendModeLighten is the lighten blend mode
+	BlendModeDarken     = 6  // BlendModeDarken is the darken blend mode
+	BlendModeDifference = 7  // BlendModeDifference is the difference blend mode
+	BlendModeAdd        = 8  // BlendModeAdd is the add blend mode
+	BlendModeSubtract   = 9  // BlendModeSubtract is the subtract blend mode
+	BlendModeInvert     = 10 // BlendModeInvert is the invert blend mode
+	BlendModeAlpha      = 11 // BlendModeAlpha is the alpha blend mode
+	BlendModeErase      = 12 // BlendModeErase is the erase blend mode
+	BlendModeOverlay    = 13 // BlendModeOverlay is the overlay blend mode
+	BlendModeHardlight  = 14 // BlendModeHardlight is the hardlight blend mode
+)
+
+// These represent the events of a ClipEventFlags record. Before Swf 6 the
+// flags are 16 bits long, only the events up to ClipEventData are used
+const (
+	ClipEventKeyUp          = 1 << 31 // ClipEventKeyUp is the key up event
+	ClipEventKeyDown        = 1 << 30 // ClipEventKeyDown is the key down event
+	ClipEventMouseUp        = 1 << 29 // ClipEventMouseUp is the mouse up event
+	ClipEventMouseDown      = 1 << 28 // ClipEventMouseDown is the mouse down event
+	ClipEventMouseMove      = 1 << 27 // ClipEventMouseMove is the mouse move event
+	ClipEventUnload         = 1 << 26 // ClipEventUnload is the clip unload event
+	ClipEventEnterFrame     = 1 << 25 // ClipEventEnterFrame is the enter frame event
+	ClipEventLoad           = 1 << 24 // ClipEventLoad is the clip load event
+	ClipEventDragOver       = 1 << 23 // ClipEventDragOver is the mouse drag over event, since Swf 6
+	ClipEventRollOut        = 1 << 22 // ClipEventRollOut is the mouse roll out event, since Swf 6
+	ClipEventRollOver       = 1 << 21 // ClipEventRollOver is the mouse roll over event, since Swf 6
+	ClipEventReleaseOutside = 1 << 20 // ClipEventReleaseOutside is the mouse release outside event, since Swf 6
+	ClipEventRelease        = 1 << 19 // ClipEventRelease is the mouse release inside event, since Swf 6
+	ClipEventPress          = 1 << 18 // ClipEventPress is the mouse press event, since Swf 6
+	ClipEventInitialize     = 1 << 17 // ClipEventInitialize is the initialize event, since Swf 6
+	ClipEventData           = 1 << 16 // ClipEventData is the data received event
+	ClipEventConstruct      = 1 << 10 // ClipEventConstruct is the construct event, since Swf 7
+	ClipEventKeyPress       = 1 << 9  // ClipEventKeyPress is the key press event, since Swf 6
+	ClipEventDragOut        = 1 << 8  // ClipEventDragOut is the mouse drag out event, since Swf 6
+)
+
+func (p *parser) ParseTagShowFrame(length uint32) (Tag, error) {
+	return &tag{code: CodeTagShowFrame, length: length}, nil
+}
+
+func (p *parser) ParseTagPlaceObject(length uint32) (Tag, error) {
+	begin, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	t := &TagPlaceObject{tag: tag{code: CodeTagPlaceObject, length: length}, HasCharacter: true, HasMatrix: true}
+	if t.CharacterID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "PlaceObject.CharacterId")
+	}
+	if t.Depth, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "PlaceObject.Depth")
+	}
+	if t.Matrix, err = p.ParseMatrix(); err != nil {
+		return nil, err
+	}
+
+	// The color transform is optional, it is there when bytes remain
+	end, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(end-begin) < length {
+		cx, err := p.ParseColorTransform()
+		if err != nil {
+			return nil, err
+		}
+		t.HasColorTransform, t.ColorTransform = true, cx.WithAlpha()
+	}
+	return t, nil
+}
+
+func (p *parser) ParseTagPlaceObject2(length uint32) (Tag, error) {
+	return p.parseTagPlaceObject2or3(CodeTagPlaceObject2, length)
+}
+
+// ParseTagPlaceObject3 parses a PlaceObject3 tag, which adds a class name,
+// filters, a blend mode, bitmap caching, visibility and a background color
+// to PlaceObject2
+func (p *parser) ParseTagPlaceObject3(length uint32) (Tag, error) {
+	return p.parseTagPlaceObject2or3(CodeTagPlaceObject3, length)
+}
+
+func (p *parser) parseTagPlaceObject2or3(code uint16, length uint32) (Tag, error) {
+	record := "PlaceObject2"
+	if code == CodeTagPlaceObject3 {
+		record = "PlaceObject3"
+	}
+
+	begin, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	t := &TagPlaceObject{tag: tag{code: code, length: length}}
+	flags, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, record+".Flags")
+	}
+	t.HasClipActions = flags&0x80 != 0
+	t.HasClipDepth = flags&0x40 != 0
+	t.HasName = flags&0x20 != 0
+	t.HasRatio = flags&0x10 != 0
+	t.HasColorTransform = flags&0x08 != 0
+	t.HasMatrix = flags&0x04 != 0
+	t.HasCharacter = flags&0x02 != 0
+	t.Move = flags&0x01 != 0
+	if code == CodeTagPlaceObject3 {
+		if flags, err = p.r.ReadUInt8(); err != nil {
+			return nil, p.fail(err, record+".Flags")
+		}
+		t.OpaqueBackground = flags&0x40 != 0
+		t.HasVisible = flags&0x20 != 0
+		t.HasImage = flags&0x10 != 0
+		t.HasClassName = flags&0x08 != 0
+		t.HasCacheAsBitmap = flags&0x04 != 0
+		t.HasBlendMode = flags&0x02 != 0
+		t.HasFilterList = flags&0x01 != 0
+	}
+
+	if t.Depth, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, record+".Depth")
+	}
+	if t.hasClassName() {
+		if t.ClassName, err = p.r.ReadString(); err != nil {
+			return nil, p.fail(err, record+".ClassName")
+		}
+	}
+	if t.HasCharacter {
+		if t.CharacterID, err = p.r.ReadUInt16(); err != nil {
+			return nil, p.fail(err, record+".CharacterId")
+		}
+	}
+	if t.HasMatrix {
+		if t.Matrix, err = p.ParseMatrix(); err != nil {
+			return nil, err
+		}
+	}
+	if t.HasColorTransform {
+		if t.ColorTransform, err = p.ParseColorTransformWithAlpha(); err != nil {
+			return nil, err
+		}
+	}
+	if t.HasRatio {
+		if t.Ratio, err = p.r.ReadUInt16(); err != nil {
+			return nil, p.fail(err, record+".Ratio")
+		}
+	}
+	if t.HasName {
+		if t.Name, err = p.r.ReadString(); err != nil {
+			return nil, p.fail(err, record+".Name")
+		}
+	}
+	if t.HasClipDepth {
+		if t.ClipDepth, err = p.r.ReadUInt16(); err != nil {
+			return nil, p.fail(err, record+".ClipDepth")
+		}
+	}
+
+	if code == CodeTagPlaceObject3 {
+		if t.HasFilterList {
+			if t.SurfaceFilterList, err = p.ParseFilterList(); err != nil {
+				return nil, err
+			}
+		}
+		fields := []struct {
+			has    bool
+			ptr    *uint8
+			record string
+		}{
+			{t.HasBlendMode, &t.BlendMode, ".BlendMode"},
+			{t.HasCacheAsBitmap, &t.BitmapCache, ".BitmapCache"},
+			{t.HasVisible, &t.Visible, ".Visible"},
+		}
+		for _, field := range fields {
+			if !field.has {
+				continue
+			}
+			if *field.ptr, err = p.r.ReadUInt8(); err != nil {
+				return nil, p.fail(err, record+field.record)
+			}
+		}
+		if t.OpaqueBackground {
+			if t.BackgroundColor, err = p.ParseRGBA(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if t.HasClipActions {
+		if t.ClipActions, err = p.ParseClipActions(begin + int64(length)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// hasClassName returns true when a PlaceObject3 holds a class name. The
+// class name of an image is written even without the HasClassName flag
+func (t *TagPlaceObject) hasClassName() bool {
+	return t.Code() == CodeTagPlaceObject3 && (t.HasClassName || (t.HasImage && t.HasCharacter))
+}
+
+// ParseClipActions parses a ClipActions record, the records end with empty
+// event flags. The action records must not go past the end offset
+func (p *parser) ParseClipActions(end int64) (actions ClipActions, err error) {
+	if _, err = p.r.ReadUInt16(); err != nil {
+		return actions, p.fail(err, "CLIPACTIONS.Reserved")
+	}
+	if actions.AllEventFlags, err = p.parseClipEventFlags("CLIPACTIONS.AllEventFlags"); err != nil {
+		return actions, err
+	}
+	for {
+		var record ClipActionRecord
+		if record.EventFlags, err = p.parseClipEventFlags("CLIPACTIONRECORD.EventFlags"); err != nil {
+			return actions, err
+		}
+		if record.EventFlags == 0 {
+			return actions, nil
+		}
+		size, err := p.r.ReadUInt32()
+		if err != nil {
+			return actions, p.fail(err, "CLIPACTIONRECORD.ActionRecordSize")
+		}
+		if record.EventFlags&ClipEventKeyPress != 0 && size > 0 {
+			if record.KeyCode, err = p.r.ReadUInt8(); err != nil {
+				return actions, p.fail(err, "CLIPACTIONRECORD.KeyCode")
+			}
+			size--
+		}
+		pos, err := p.r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return actions, err
+		}
+		if int64(size) > end-pos {
+			return actions, p.fail(io.ErrUnexpectedEOF, "CLIPACTIONRECORD.ActionRecordSize")
+		}
+		record.Actions = make([]byte, size)
+		if _, err = io.ReadFull(p.r, record.Actions); err != nil {
+			return actions, p.fail(err, "CLIPACTIONRECORD.Actions")
+		}
+		actions.ClipActionRecords = append(actions.ClipActionRecords, record)
+	}
+}
+
+// parseClipEventFlags parses a ClipEventFlags record, which is 16 bits long
+// before Swf 6. The flags are returned as ClipEvent values
+func (p *parser) parseClipEventFlags(record string) (uint32, error) {
+	if p.version < 6 {
+		flags, err := p.r.ReadUBitValue(16)
+		return flags << 16, p.fail(err, record)
+	}
+	flags, err := p.r.ReadUBitValue(32)
+	return flags, p.fail(err, record)
+}
+
+func (p *parser) ParseTagRemoveObject(length uint32) (Tag, error) {
+	t := &TagRemoveObject{tag: tag{code: CodeTagRemoveObject, length: length}}
+	var err error
+	if t.CharacterID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "RemoveObject.CharacterId")
+	}
+	if t.Depth, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "RemoveObject.Depth")
+	}
+	return t, nil
+}
+
+func (p *parser) ParseTagRemoveObject2(length uint32) (Tag, error) {
+	depth, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, "RemoveObject2.Depth")
+	}
+	return &TagRemoveObject{tag{code: CodeTagRemoveObject2, length: length}, 0, depth}, nil
+}
+
+// SerializeTagRemoveObject serializes a RemoveObject or a RemoveObject2 tag,
+// according to its code
+func (s *serializer) SerializeTagRemoveObject(t *TagRemoveObject) error {
+	if t.Code() == CodeTagRemoveObject {
+		if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+			return err
+		}
+	}
+	return s.w.WriteUInt16(t.Depth)
+}
+
+// SerializeTagPlaceObject serializes a PlaceObject, PlaceObject2 or
+// PlaceObject3 tag, according to its code
+func (s *serializer) SerializeTagPlaceObject(t *TagPlaceObject) error {
+	if t.Code() == CodeTagPlaceObject {
+		return s.serializeTagPlaceObject1(t)
+	}
+
+	var flags uint8
+	for _, flag := range []struct {
+		set bool
+		bit uint8
+	}{
+		{t.HasClipActions, 0x80},
+		{t.HasClipDepth, 0x40},
+		{t.HasName, 0x20},
+		{t.HasRatio, 0x10},
+		{t.HasColorTransform, 0x08},
+		{t.HasMatrix, 0x04},
+		{t.HasCharacter, 0x02},
+		{t.Move, 0x01},
+	} {
+		if flag.set {
+			flags |= flag.bit
+		}
+	}
+	if err := s.w.WriteUInt8(flags); err != nil {
+		return err
+	}
+	if t.Code() == CodeTagPlaceObject3 {
+		flags = 0
+		for _, flag := range []struct {
+			set bool
+			bit uint8
+		}{
+			{t.OpaqueBackground, 0x40},
+			{t.HasVisible, 0x20},
+			{t.HasImage, 0x10},
+			{t.HasClassName, 0x08},
+			{t.HasCacheAsBitmap, 0x04},
+			{t.HasBlendMode, 0x02},
+			{t.HasFilterList, 0x01},
+		} {
+			if flag.set {
+				flags |= flag.bit
+			}
+		}
+		if err := s.w.WriteUInt8(flags); err != nil {
+			return err
+		}
+	}
+
+	if err := s.w.WriteUInt16(t.Depth); err != nil {
+		return err
+	}
+	if t.hasClassName() {
+		if err := s.w.WriteString(t.ClassName); err != nil {
+			return err
+		}
+	}
+	if t.HasCharacter {
+		if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+			return err
+		}
+	}
+	if t.HasMatrix {
+		if err := s.SerializeMatrix(t.Matrix); err != nil {
+			return err
+		}
+	}
+	if t.HasColorTransform {
+		if err := s.SerializeColorTransformWithAlpha(t.ColorTransform); err != nil {
+			return err
+		}
+	}
+	if t.HasRatio {
+		if err := s.w.WriteUInt16(t.Ratio); err != nil {
+			return err
+		}
+	}
+	if t.HasName {
+		if err := s.w.WriteString(t.Name); err != nil {
+			return err
+		}
+	}
+	if t.HasClipDepth {
+		if err := s.w.WriteUInt16(t.ClipDepth); err != nil {
+			return err
+		}
+	}
+
+	if t.Code() == CodeTagPlaceObject3 {
+		if t.HasFilterList {
+			if err := s.SerializeFilterList(t.SurfaceFilterList); err != nil {
+				return err
+			}
+		}
+		for _, field := range []struct {
+			has   bool
+			value uint8
+		}{
+			{t.HasBlendMode, t.BlendMode},
+			{t.HasCacheAsBitmap, t.BitmapCache},
+			{t.HasVisible, t.Visible},
+		} {
+			if !field.has {
+				continue
+			}
+			if err := s.w.WriteUInt8(field.value); err != nil {
+				return err
+			}
+		}
+		if t.OpaqueBackground {
+			if err := s.SerializeRGBA(t.BackgroundColor); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.HasClipActions {
+		return s.SerializeClipActions(t.ClipActions)
+	}
+	return nil
+}
+
+// serializeTagPlaceObject1 serializes a PlaceObject tag, the alpha terms of
+// its color transform are dropped
+func (s *serializer) serializeTagPlaceObject1(t *TagPlaceObject) error {
+	if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt16(t.Depth); err != nil {
+		return err
+	}
+	if err := s.SerializeMatrix(t.Matrix); err != nil {
+		return err
+	}
+	if !t.HasColorTransform {
+		return nil
+	}
+	cx := t.ColorTransform
+	return s.SerializeColorTransform(ColorTransform{
+		cx.HasAddTerms, cx.HasMultTerms, cx.NBits,
+		cx.RedMultTerm, cx.GreenMultTerm, cx.BlueMultTerm,
+		cx.RedAddTerm, cx.GreenAddTerm, cx.BlueAddTerm,
+	})
+}
+
+// SerializeClipActions serializes a ClipActions record
+func (s *serializer) SerializeClipActions(actions ClipActions) error {
+	if err := s.w.WriteUInt16(0); err != nil {
+		return err
+	}
+	if err := s.serializeClipEventFlags(actions.AllEventFlags); err != nil {
+		return err
+	}
+	for _, record := range actions.ClipActionRecords {
+		if err := s.serializeClipEventFlags(record.EventFlags); err != nil {
+			return err
+		}
+		size := uint32(len(record.Actions))
+		keyPress := record.EventFlags&ClipEventKeyPress != 0
+		if keyPress {
+			size++
+		}
+		if err := s.w.WriteUInt32(size); err != nil {
+			return err
+		}
+		if keyPress {
+			if err := s.w.WriteUInt8(record.KeyCode); err != nil {
+				return err
+			}
+		}
+		if _, err := s.w.Write(record.Actions); err != nil {
+			return err
+		}
+	}
+	return s.serializeClipEventFlags(0)
+}
+
+// serializeClipEventFlags serializes a ClipEventFlags record, which is 16
+// bits long before Swf 6
+func (s *serializer) serializeClipEventFlags(flags uint32) error {
+	if s.version < 6 {
+		return s.w.WriteUBitValue(flags>>16, 16)
+	}
+	return s.w.WriteUBitValue(flags, 32)
+}
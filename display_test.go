@@ -0,0 +1,243 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPlaceObject(t *testing.T) {
+	tagsBytes := []byte{
+		0x0c, 0x01, // PlaceObject, length 12
+		0x01, 0x00, // CharacterId
+		0x02, 0x00, // Depth
+		0x0e, 0x52, 0xc0, // Matrix
+		0x69, 0x00, 0x20, 0x00, 0x00, // ColorTransform
+		0x05, 0x01, // PlaceObject, length 5
+		0x01, 0x00, // CharacterId
+		0x03, 0x00, // Depth
+		0x00,       // Matrix
+		0x44, 0x01, // RemoveObject, length 4
+		0x01, 0x00, // CharacterId
+		0x02, 0x00, // Depth
+		0x02, 0x07, // RemoveObject2, length 2
+		0x03, 0x00, // Depth
+		0x40, 0x00, // ShowFrame
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	if len(tags) != 6 {
+		t.Fatalf("expected 6, got %v", len(tags))
+	}
+
+	expected := []Tag{
+		&TagPlaceObject{
+			tag:               tag{code: CodeTagPlaceObject, length: 12},
+			HasCharacter:      true,
+			HasMatrix:         true,
+			HasColorTransform: true,
+			Depth:             2,
+			CharacterID:       1,
+			Matrix:            Matrix{NTranslateBits: 7, TranslateX: 20, TranslateY: -40},
+			ColorTransform: ColorTransformWithAlpha{
+				HasMultTerms: true, NBits: 10,
+				RedMultTerm: 256, GreenMultTerm: 128, BlueMultTerm: 0, AlphaMultTerm: 256,
+			},
+		},
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject, length: 5}, HasCharacter: true, HasMatrix: true, Depth: 3, CharacterID: 1},
+		&TagRemoveObject{tag{code: CodeTagRemoveObject, length: 4}, 1, 2},
+		&TagRemoveObject{tag{code: CodeTagRemoveObject2, length: 2}, 0, 3},
+		&tag{code: CodeTagShowFrame, length: 0},
+	}
+	for i, e := range expected {
+		if !reflect.DeepEqual(tags[i], e) {
+			t.Errorf("expected %#v, got %#v", e, tags[i])
+		}
+	}
+}
+
+func TestPlaceObject2(t *testing.T) {
+	tagsBytes := []byte{
+		0xab, 0x06, // PlaceObject2, length 43
+		0xf6,       // Flags
+		0x02, 0x00, // Depth
+		0x03, 0x00, // CharacterId
+		0x00,       // Matrix
+		0x10, 0x00, // Ratio
+		0x61, 0x00, // Name
+		0x05, 0x00, // ClipDepth
+		// ClipActions
+		0x00, 0x00, 0x01, 0x00, 0x02, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x07, 0x00,
+		0x00, 0x00, 0x02, 0x00, 0x03, 0x00, 0x00, 0x00, 0x0d, 0x06, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, // End
+	}
+	p := newParser(bytes.NewReader(tagsBytes))
+	p.version = 6
+	tags, err := p.ParseTags()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := &TagPlaceObject{
+		tag:            tag{code: CodeTagPlaceObject2, length: 43},
+		HasCharacter:   true,
+		HasMatrix:      true,
+		HasRatio:       true,
+		HasName:        true,
+		HasClipDepth:   true,
+		HasClipActions: true,
+		Depth:          2,
+		CharacterID:    3,
+		Ratio:          0x10,
+		Name:           "a",
+		ClipDepth:      5,
+		ClipActions: ClipActions{
+			AllEventFlags: ClipEventLoad | ClipEventKeyPress,
+			ClipActionRecords: []ClipActionRecord{
+				{EventFlags: ClipEventLoad, Actions: []byte{0x07, 0x00}},
+				{EventFlags: ClipEventKeyPress, KeyCode: 0x0d, Actions: []byte{0x06, 0x00}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(tags[0], expected) {
+		t.Errorf("expected %#v, got %#v", expected, tags[0])
+	}
+
+	var buf bytes.Buffer
+	s := newSerializer(&buf)
+	s.version = 6
+	if err = s.SerializeTags(tags); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), tagsBytes) {
+		t.Errorf("expected %#v, got %#v", tagsBytes, buf.Bytes())
+	}
+}
+
+func TestPlaceObject2MatrixAndColorTransform(t *testing.T) {
+	// Both records start on a byte boundary
+	tagsBytes := []byte{
+		0x8a, 0x06, // PlaceObject2, length 10
+		0x0e,       // Flags
+		0x01, 0x00, // Depth
+		0x02, 0x00, // CharacterId
+		0x02, 0x00, // Matrix
+		0x90, 0x48, 0xd0, // ColorTransform
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+
+	expected := &TagPlaceObject{
+		tag:               tag{code: CodeTagPlaceObject2, length: 10},
+		HasCharacter:      true,
+		HasMatrix:         true,
+		HasColorTransform: true,
+		Depth:             1,
+		CharacterID:       2,
+		Matrix:            Matrix{NTranslateBits: 1},
+		ColorTransform: ColorTransformWithAlpha{
+			HasAddTerms:  true,
+			NBits:        4,
+			RedAddTerm:   1,
+			GreenAddTerm: 2,
+			BlueAddTerm:  3,
+			AlphaAddTerm: 4,
+		},
+	}
+	if !reflect.DeepEqual(tags[0], expected) {
+		t.Errorf("expected %#v, got %#v", expected, tags[0])
+	}
+}
+
+func TestPlaceObject2ClipEventFlagsSwf5(t *testing.T) {
+	// Before Swf 6 the clip event flags are 16 bits long
+	tagsBytes := []byte{
+		0x9a, 0x06, // PlaceObject2, length 26
+		0xf6,       // Flags
+		0x02, 0x00, // Depth
+		0x03, 0x00, // CharacterId
+		0x00,       // Matrix
+		0x10, 0x00, // Ratio
+		0x61, 0x00, // Name
+		0x05, 0x00, // ClipDepth
+		// ClipActions
+		0x00, 0x00, 0x01, 0x00,
+		0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x07, 0x00,
+		0x00, 0x00,
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	expected := ClipActions{
+		AllEventFlags:     ClipEventLoad,
+		ClipActionRecords: []ClipActionRecord{{EventFlags: ClipEventLoad, Actions: []byte{0x07, 0x00}}},
+	}
+	if actions := tags[0].(*TagPlaceObject).ClipActions; !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actions)
+	}
+}
+
+func TestPlaceObject2ActionRecordSize(t *testing.T) {
+	// ActionRecordSize goes past the payload
+	tagsBytes := []byte{
+		0x8f, 0x06, // PlaceObject2, length 15
+		0x80,       // Flags
+		0x01, 0x00, // Depth
+		// ClipActions
+		0x00, 0x00, 0x01, 0x00,
+		0x01, 0x00, 0xff, 0xff, 0xff, 0x7f, 0x07, 0x00,
+		0x00, 0x00, // End
+	}
+	_, err := newParser(bytes.NewReader(tagsBytes)).ParseTags()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Record != "CLIPACTIONRECORD.ActionRecordSize" {
+		t.Errorf("expected a *ParseError for CLIPACTIONRECORD.ActionRecordSize, got %v", err)
+	}
+}
+
+func TestPlaceObject3(t *testing.T) {
+	tagsBytes := []byte{
+		0xa0, 0x11, // PlaceObject3, length 32
+		0x0a, 0x6f, // Flags
+		0x01, 0x00, // Depth
+		0x41, 0x00, // ClassName
+		0x04, 0x00, // CharacterId
+		0xa8, 0xff, 0x00, 0x00, 0x0c, 0x04, // ColorTransform
+		0x01, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x08, // SurfaceFilterList
+		0x03,                   // BlendMode
+		0x01,                   // BitmapCache
+		0x01,                   // Visible
+		0x11, 0x22, 0x33, 0x44, // BackgroundColor
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+
+	expected := &TagPlaceObject{
+		tag:               tag{code: CodeTagPlaceObject3, length: 32},
+		HasCharacter:      true,
+		HasColorTransform: true,
+		HasFilterList:     true,
+		HasBlendMode:      true,
+		HasCacheAsBitmap:  true,
+		HasClassName:      true,
+		HasVisible:        true,
+		OpaqueBackground:  true,
+		Depth:             1,
+		ClassName:         "A",
+		CharacterID:       4,
+		ColorTransform: ColorTransformWithAlpha{
+			HasAddTerms: true, NBits: 10,
+			RedAddTerm: 255, AlphaAddTerm: -255,
+		},
+		SurfaceFilterList: []Filter{&BlurFilter{1, 1, 1}},
+		BlendMode:         BlendModeMultiply,
+		BitmapCache:       1,
+		Visible:           1,
+		BackgroundColor:   RGBA{0x11, 0x22, 0x33, 0x44},
+	}
+	if !reflect.DeepEqual(tags[0], expected) {
+		t.Errorf("expected %#v, got %#v", expected, tags[0])
+	}
+}
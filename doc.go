@@ -1,5 +1,6 @@
-// Package swf contains utilities to read Shockwave Flash Format files
-// It provides a Parser to parse an entire Swf file.
-// It also provides a Reader implementation that can read basic data types defined by the specification
+// Package swf contains utilities to read and write Shockwave Flash Format files
+// It provides a Parser to parse an entire Swf file and Write to serialize it back.
+// It also provides Reader and Writer implementations that can read and write basic data types
+// defined by the specification
 // (see http://wwwimages.adobe.com/content/dam/Adobe/en/devnet/swf/pdf/swf-file-format-spec.pdf)
 package swf
@@ -0,0 +1,43 @@
+package swf
+
+import (
+	"fmt"
+	"io"
+)
+
+// ParseError describes where the parsing of a Swf file failed.
+// It wraps the underlying error, so errors.Is(err, io.ErrUnexpectedEOF)
+// still works
+type ParseError struct {
+	Offset   int64  // Offset in the decompressed file where reading stopped
+	TagIndex int    // TagIndex is the index of the tag being parsed, -1 in the header
+	TagCode  uint16 // TagCode is the code of the tag being parsed
+	Record   string // Record is the record being read, such as "RECT.Xmax"
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	location := "header"
+	if e.TagIndex >= 0 {
+		location = fmt.Sprintf("tag %d (code %d)", e.TagIndex, e.TagCode)
+	}
+	return fmt.Sprintf("swf: %v reading %s at offset %d in %s", e.Err, e.Record, e.Offset, location)
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// fail wraps err in a ParseError located at the current position.
+// Errors that are already a ParseError are returned as is
+func (p *parser) fail(err error, record string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*ParseError); ok {
+		return err
+	}
+	offset, _ := p.r.Seek(0, io.SeekCurrent)
+	return &ParseError{offset, p.tagIndex, p.tagCode, record, p.handleEOF(err)}
+}
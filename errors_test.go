@@ -0,0 +1,49 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	headerBytes := []byte{
+		0x46, 0x57, 0x53,
+		0x0b,
+		0x94, 0x16, 0xb1, 0x00,
+		0x80, 0x00, 0x03, 0x20, 0x00,
+	}
+	_, err := Parse(bytes.NewReader(headerBytes))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a *ParseError, got %v", err)
+	}
+	correct := &ParseError{13, -1, 0, "RECT.Ymin", io.ErrUnexpectedEOF}
+	if !reflect.DeepEqual(parseErr, correct) {
+		t.Errorf("expected %v, got %v", correct, parseErr)
+	}
+
+	_, err = Parse(bytes.NewReader([]byte{0x46, 0x57, 0x58}))
+	if !errors.Is(err, ErrMalformedHeader) {
+		t.Errorf("expected ErrMalformedHeader, got %v", err)
+	}
+
+	// The DoABC tag has a name but no flags
+	tagsBytes := append([]byte{}, iteratorTagsBytes[:5]...)
+	tagsBytes = append(tagsBytes, 0x82, 0x14, 0x61, 0x00)
+	p := newParser(bytes.NewReader(tagsBytes))
+	_, err = p.ParseTags()
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a *ParseError, got %v", err)
+	}
+	correct = &ParseError{9, 1, CodeTagDoABC, "DoABC.Flags", io.ErrUnexpectedEOF}
+	if !reflect.DeepEqual(parseErr, correct) {
+		t.Errorf("expected %v, got %v", correct, parseErr)
+	}
+	expected := "swf: unexpected EOF reading DoABC.Flags at offset 9 in tag 1 (code 82)"
+	if parseErr.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, parseErr.Error())
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "github.com/kelvyne/swf"
+import "github.com/kelvyne/swf/abc"
+import "os"
+import "fmt"
+
+func main() {
+	file, err := os.Open(os.Args[1])
+	if err != nil {
+		fmt.Printf("os.open: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	s, err := swf.Parse(file)
+	if err != nil {
+		fmt.Printf("swf.Parse: %v\n", err)
+		return
+	}
+
+	for _, tag := range s.Tags {
+		if tag.Code() == swf.CodeTagDoABC {
+			doAbc := tag.(*swf.TagDoABC)
+			abcFile, err := abc.ParseBytes(doAbc.ABCData)
+			if err != nil {
+				fmt.Printf("abc.ParseBytes: %v\n", err)
+				continue
+			}
+			fmt.Printf("// %v\n", doAbc.Name)
+			if err = abc.Dump(os.Stdout, &abcFile); err != nil {
+				fmt.Printf("abc.Dump: %v\n", err)
+			}
+		}
+	}
+}
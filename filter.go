@@ -0,0 +1,453 @@
+package swf
+
+import (
+	"errors"
+	"math"
+)
+
+// These represent the IDs of the filters of a FilterList
+const (
+	FilterDropShadow    = 0 // FilterDropShadow is the ID of a DropShadowFilter
+	FilterBlur          = 1 // FilterBlur is the ID of a BlurFilter
+	FilterGlow          = 2 // FilterGlow is the ID of a GlowFilter
+	FilterBevel         = 3 // FilterBevel is the ID of a BevelFilter
+	FilterGradientGlow  = 4 // FilterGradientGlow is the ID of a GradientGlowFilter
+	FilterConvolution   = 5 // FilterConvolution is the ID of a ConvolutionFilter
+	FilterColorMatrix   = 6 // FilterColorMatrix is the ID of a ColorMatrixFilter
+	FilterGradientBevel = 7 // FilterGradientBevel is the ID of a GradientBevelFilter
+)
+
+// ErrFilterID means that a filter can not be decoded.
+// Its FilterID is unknown
+var ErrFilterID = errors.New("unknown filter id")
+
+// FilterID returns FilterDropShadow
+func (*DropShadowFilter) FilterID() uint8 { return FilterDropShadow }
+
+// FilterID returns FilterBlur
+func (*BlurFilter) FilterID() uint8 { return FilterBlur }
+
+// FilterID returns FilterGlow
+func (*GlowFilter) FilterID() uint8 { return FilterGlow }
+
+// FilterID returns FilterBevel
+func (*BevelFilter) FilterID() uint8 { return FilterBevel }
+
+// FilterID returns FilterGradientGlow
+func (*GradientGlowFilter) FilterID() uint8 { return FilterGradientGlow }
+
+// FilterID returns FilterConvolution
+func (*ConvolutionFilter) FilterID() uint8 { return FilterConvolution }
+
+// FilterID returns FilterColorMatrix
+func (*ColorMatrixFilter) FilterID() uint8 { return FilterColorMatrix }
+
+// FilterID returns FilterGradientBevel
+func (*GradientBevelFilter) FilterID() uint8 { return FilterGradientBevel }
+
+// filterFlags holds the flags ending most filters. Passes is 5 bits long
+// when there is no OnTop flag, 4 bits long otherwise
+type filterFlags struct {
+	inner, knockout, compositeSource, onTop bool
+	passes                                  uint8
+}
+
+func (p *parser) parseFilterFlags(record string, hasOnTop bool) (f filterFlags, err error) {
+	b, err := p.r.ReadUInt8()
+	if err != nil {
+		return f, p.fail(err, record+".Flags")
+	}
+	f.inner, f.knockout, f.compositeSource = b&0x80 != 0, b&0x40 != 0, b&0x20 != 0
+	if hasOnTop {
+		f.onTop, f.passes = b&0x10 != 0, b&0xf
+	} else {
+		f.passes = b & 0x1f
+	}
+	return f, nil
+}
+
+func (s *serializer) serializeFilterFlags(f filterFlags, hasOnTop bool) error {
+	var b uint8
+	for _, flag := range []struct {
+		set bool
+		bit uint8
+	}{
+		{f.inner, 0x80},
+		{f.knockout, 0x40},
+		{f.compositeSource, 0x20},
+		{f.onTop && hasOnTop, 0x10},
+	} {
+		if flag.set {
+			b |= flag.bit
+		}
+	}
+	if hasOnTop {
+		return s.w.WriteUInt8(b | f.passes&0xf)
+	}
+	return s.w.WriteUInt8(b | f.passes&0x1f)
+}
+
+// fixedField is a fixed point number of a record
+type fixedField struct {
+	ptr    *float32
+	record string
+}
+
+// parseFixed parses fixed point numbers in order
+func (p *parser) parseFixed(fields ...fixedField) (err error) {
+	for _, field := range fields {
+		if *field.ptr, err = p.r.ReadFixed(); err != nil {
+			return p.fail(err, field.record)
+		}
+	}
+	return nil
+}
+
+// parseFloat parses a single precision floating point number
+func (p *parser) parseFloat(record string) (float32, error) {
+	v, err := p.r.ReadUInt32()
+	if err != nil {
+		return 0, p.fail(err, record)
+	}
+	return math.Float32frombits(v), nil
+}
+
+// ParseFilterList parses a FilterList record
+func (p *parser) ParseFilterList() ([]Filter, error) {
+	count, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, "FILTERLIST.NumberOfFilters")
+	}
+	filters := make([]Filter, count)
+	for i := range filters {
+		if filters[i], err = p.ParseFilter(); err != nil {
+			return nil, err
+		}
+	}
+	return filters, nil
+}
+
+// ParseFilter parses a Filter record
+func (p *parser) ParseFilter() (Filter, error) {
+	id, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, "FILTER.FilterID")
+	}
+	switch id {
+	case FilterDropShadow:
+		return p.parseDropShadowFilter()
+	case FilterBlur:
+		return p.parseBlurFilter()
+	case FilterGlow:
+		return p.parseGlowFilter()
+	case FilterBevel:
+		return p.parseBevelFilter()
+	case FilterGradientGlow:
+		return p.parseGradientGlowFilter("GRADIENTGLOWFILTER")
+	case FilterConvolution:
+		return p.parseConvolutionFilter()
+	case FilterColorMatrix:
+		return p.parseColorMatrixFilter()
+	case FilterGradientBevel:
+		f, err := p.parseGradientGlowFilter("GRADIENTBEVELFILTER")
+		return (*GradientBevelFilter)(f), err
+	}
+	return nil, p.fail(ErrFilterID, "FILTER.FilterID")
+}
+
+func (p *parser) parseDropShadowFilter() (*DropShadowFilter, error) {
+	f := &DropShadowFilter{}
+	var err error
+	if f.DropShadowColor, err = p.ParseRGBA(); err != nil {
+		return nil, err
+	}
+	err = p.parseFixed(
+		fixedField{&f.BlurX, "DROPSHADOWFILTER.BlurX"},
+		fixedField{&f.BlurY, "DROPSHADOWFILTER.BlurY"},
+		fixedField{&f.Angle, "DROPSHADOWFILTER.Angle"},
+		fixedField{&f.Distance, "DROPSHADOWFILTER.Distance"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if f.Strength, err = p.r.ReadFixed8(); err != nil {
+		return nil, p.fail(err, "DROPSHADOWFILTER.Strength")
+	}
+	flags, err := p.parseFilterFlags("DROPSHADOWFILTER", false)
+	if err != nil {
+		return nil, err
+	}
+	f.InnerShadow, f.Knockout, f.CompositeSource, f.Passes = flags.inner, flags.knockout, flags.compositeSource, flags.passes
+	return f, nil
+}
+
+func (p *parser) parseBlurFilter() (*BlurFilter, error) {
+	f := &BlurFilter{}
+	err := p.parseFixed(
+		fixedField{&f.BlurX, "BLURFILTER.BlurX"},
+		fixedField{&f.BlurY, "BLURFILTER.BlurY"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	passes, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, "BLURFILTER.Passes")
+	}
+	f.Passes = passes >> 3
+	return f, nil
+}
+
+func (p *parser) parseGlowFilter() (*GlowFilter, error) {
+	f := &GlowFilter{}
+	var err error
+	if f.GlowColor, err = p.ParseRGBA(); err != nil {
+		return nil, err
+	}
+	err = p.parseFixed(
+		fixedField{&f.BlurX, "GLOWFILTER.BlurX"},
+		fixedField{&f.BlurY, "GLOWFILTER.BlurY"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if f.Strength, err = p.r.ReadFixed8(); err != nil {
+		return nil, p.fail(err, "GLOWFILTER.Strength")
+	}
+	flags, err := p.parseFilterFlags("GLOWFILTER", false)
+	if err != nil {
+		return nil, err
+	}
+	f.InnerGlow, f.Knockout, f.CompositeSource, f.Passes = flags.inner, flags.knockout, flags.compositeSource, flags.passes
+	return f, nil
+}
+
+func (p *parser) parseBevelFilter() (*BevelFilter, error) {
+	f := &BevelFilter{}
+	var err error
+	if f.ShadowColor, err = p.ParseRGBA(); err != nil {
+		return nil, err
+	}
+	if f.HighlightColor, err = p.ParseRGBA(); err != nil {
+		return nil, err
+	}
+	err = p.parseFixed(
+		fixedField{&f.BlurX, "BEVELFILTER.BlurX"},
+		fixedField{&f.BlurY, "BEVELFILTER.BlurY"},
+		fixedField{&f.Angle, "BEVELFILTER.Angle"},
+		fixedField{&f.Distance, "BEVELFILTER.Distance"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if f.Strength, err = p.r.ReadFixed8(); err != nil {
+		return nil, p.fail(err, "BEVELFILTER.Strength")
+	}
+	flags, err := p.parseFilterFlags("BEVELFILTER", true)
+	if err != nil {
+		return nil, err
+	}
+	f.InnerShadow, f.Knockout, f.CompositeSource, f.OnTop, f.Passes = flags.inner, flags.knockout, flags.compositeSource, flags.onTop, flags.passes
+	return f, nil
+}
+
+func (p *parser) parseGradientGlowFilter(record string) (*GradientGlowFilter, error) {
+	f := &GradientGlowFilter{}
+	count, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, record+".NumColors")
+	}
+	f.GradientColors = make([]RGBA, count)
+	for i := range f.GradientColors {
+		if f.GradientColors[i], err = p.ParseRGBA(); err != nil {
+			return nil, err
+		}
+	}
+	f.GradientRatio = make([]uint8, count)
+	for i := range f.GradientRatio {
+		if f.GradientRatio[i], err = p.r.ReadUInt8(); err != nil {
+			return nil, p.fail(err, record+".GradientRatio")
+		}
+	}
+	err = p.parseFixed(
+		fixedField{&f.BlurX, record + ".BlurX"},
+		fixedField{&f.BlurY, record + ".BlurY"},
+		fixedField{&f.Angle, record + ".Angle"},
+		fixedField{&f.Distance, record + ".Distance"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if f.Strength, err = p.r.ReadFixed8(); err != nil {
+		return nil, p.fail(err, record+".Strength")
+	}
+	flags, err := p.parseFilterFlags(record, true)
+	if err != nil {
+		return nil, err
+	}
+	f.InnerShadow, f.Knockout, f.CompositeSource, f.OnTop, f.Passes = flags.inner, flags.knockout, flags.compositeSource, flags.onTop, flags.passes
+	return f, nil
+}
+
+func (p *parser) parseConvolutionFilter() (*ConvolutionFilter, error) {
+	f := &ConvolutionFilter{}
+	var err error
+	if f.MatrixX, err = p.r.ReadUInt8(); err != nil {
+		return nil, p.fail(err, "CONVOLUTIONFILTER.MatrixX")
+	}
+	if f.MatrixY, err = p.r.ReadUInt8(); err != nil {
+		return nil, p.fail(err, "CONVOLUTIONFILTER.MatrixY")
+	}
+	if f.Divisor, err = p.parseFloat("CONVOLUTIONFILTER.Divisor"); err != nil {
+		return nil, err
+	}
+	if f.Bias, err = p.parseFloat("CONVOLUTIONFILTER.Bias"); err != nil {
+		return nil, err
+	}
+	f.Matrix = make([]float32, int(f.MatrixX)*int(f.MatrixY))
+	for i := range f.Matrix {
+		if f.Matrix[i], err = p.parseFloat("CONVOLUTIONFILTER.Matrix"); err != nil {
+			return nil, err
+		}
+	}
+	if f.DefaultColor, err = p.ParseRGBA(); err != nil {
+		return nil, err
+	}
+	flags, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, "CONVOLUTIONFILTER.Flags")
+	}
+	f.Clamp, f.PreserveAlpha = flags&0x02 != 0, flags&0x01 != 0
+	return f, nil
+}
+
+func (p *parser) parseColorMatrixFilter() (*ColorMatrixFilter, error) {
+	f := &ColorMatrixFilter{}
+	var err error
+	for i := range f.Matrix {
+		if f.Matrix[i], err = p.parseFloat("COLORMATRIXFILTER.Matrix"); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// SerializeFilterList serializes a FilterList record
+func (s *serializer) SerializeFilterList(filters []Filter) error {
+	if err := s.w.WriteUInt8(uint8(len(filters))); err != nil {
+		return err
+	}
+	for _, f := range filters {
+		if err := s.SerializeFilter(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeFilter serializes a Filter record
+func (s *serializer) SerializeFilter(f Filter) error {
+	if err := s.w.WriteUInt8(f.FilterID()); err != nil {
+		return err
+	}
+	switch f := f.(type) {
+	case *DropShadowFilter:
+		return s.serializeFilterFields([]RGBA{f.DropShadowColor}, []float32{f.BlurX, f.BlurY, f.Angle, f.Distance}, f.Strength,
+			filterFlags{f.InnerShadow, f.Knockout, f.CompositeSource, false, f.Passes}, false)
+	case *BlurFilter:
+		if err := s.serializeFixed(f.BlurX, f.BlurY); err != nil {
+			return err
+		}
+		return s.w.WriteUInt8(f.Passes << 3)
+	case *GlowFilter:
+		return s.serializeFilterFields([]RGBA{f.GlowColor}, []float32{f.BlurX, f.BlurY}, f.Strength,
+			filterFlags{f.InnerGlow, f.Knockout, f.CompositeSource, false, f.Passes}, false)
+	case *BevelFilter:
+		return s.serializeFilterFields([]RGBA{f.ShadowColor, f.HighlightColor}, []float32{f.BlurX, f.BlurY, f.Angle, f.Distance}, f.Strength,
+			filterFlags{f.InnerShadow, f.Knockout, f.CompositeSource, f.OnTop, f.Passes}, true)
+	case *GradientGlowFilter:
+		return s.serializeGradientFilter(f)
+	case *GradientBevelFilter:
+		return s.serializeGradientFilter((*GradientGlowFilter)(f))
+	case *ConvolutionFilter:
+		return s.serializeConvolutionFilter(f)
+	case *ColorMatrixFilter:
+		for _, v := range f.Matrix {
+			if err := s.w.WriteUInt32(math.Float32bits(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ErrFilterID
+}
+
+func (s *serializer) serializeFixed(values ...float32) error {
+	for _, v := range values {
+		if err := s.w.WriteFixed(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serializeFilterFields serializes the colors, the fixed point numbers, the
+// strength and the flags most filters are made of
+func (s *serializer) serializeFilterFields(colors []RGBA, fixed []float32, strength float32, flags filterFlags, hasOnTop bool) error {
+	for _, c := range colors {
+		if err := s.SerializeRGBA(c); err != nil {
+			return err
+		}
+	}
+	if err := s.serializeFixed(fixed...); err != nil {
+		return err
+	}
+	if err := s.w.WriteFixed8(strength); err != nil {
+		return err
+	}
+	return s.serializeFilterFlags(flags, hasOnTop)
+}
+
+func (s *serializer) serializeGradientFilter(f *GradientGlowFilter) error {
+	if len(f.GradientColors) != len(f.GradientRatio) {
+		return errors.New("gradient colors and ratios do not match")
+	}
+	if err := s.w.WriteUInt8(uint8(len(f.GradientColors))); err != nil {
+		return err
+	}
+	for _, c := range f.GradientColors {
+		if err := s.SerializeRGBA(c); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write(f.GradientRatio); err != nil {
+		return err
+	}
+	return s.serializeFilterFields(nil, []float32{f.BlurX, f.BlurY, f.Angle, f.Distance}, f.Strength,
+		filterFlags{f.InnerShadow, f.Knockout, f.CompositeSource, f.OnTop, f.Passes}, true)
+}
+
+func (s *serializer) serializeConvolutionFilter(f *ConvolutionFilter) error {
+	if len(f.Matrix) != int(f.MatrixX)*int(f.MatrixY) {
+		return errors.New("convolution matrix does not match its size")
+	}
+	if _, err := s.w.Write([]byte{f.MatrixX, f.MatrixY}); err != nil {
+		return err
+	}
+	for _, v := range append([]float32{f.Divisor, f.Bias}, f.Matrix...) {
+		if err := s.w.WriteUInt32(math.Float32bits(v)); err != nil {
+			return err
+		}
+	}
+	if err := s.SerializeRGBA(f.DefaultColor); err != nil {
+		return err
+	}
+	var flags uint8
+	if f.Clamp {
+		flags |= 0x02
+	}
+	if f.PreserveAlpha {
+		flags |= 0x01
+	}
+	return s.w.WriteUInt8(flags)
+}
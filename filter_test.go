@@ -0,0 +1,65 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFilterList(t *testing.T) {
+	var colorMatrix ColorMatrixFilter
+	for i := range colorMatrix.Matrix {
+		colorMatrix.Matrix[i] = float32(i) / 4
+	}
+	gradient := GradientGlowFilter{
+		GradientColors: []RGBA{{0xff, 0, 0, 0xff}, {0, 0, 0xff, 0}},
+		GradientRatio:  []uint8{0, 0xff},
+		BlurX:          4, BlurY: 4, Angle: 0.75, Distance: 4, Strength: 1.5,
+		Knockout: true, OnTop: true, Passes: 2,
+	}
+	gradientBevel := GradientBevelFilter(gradient)
+	filters := []Filter{
+		&DropShadowFilter{RGBA{0, 0, 0, 0x80}, 5, 5, 0.5, 4, 1, true, false, true, 1},
+		&BlurFilter{2.5, 2.5, 3},
+		&GlowFilter{RGBA{0xff, 0, 0, 0xff}, 6, 6, 2, false, true, true, 1},
+		&BevelFilter{RGBA{0, 0, 0, 0xff}, RGBA{0xff, 0xff, 0xff, 0xff}, 4, 4, 0.25, 4, 1, true, false, true, true, 1},
+		&gradient,
+		&ConvolutionFilter{3, 1, 3, -1, []float32{1, 0.5, 1}, RGBA{0, 0, 0, 0}, true, false},
+		&colorMatrix,
+		&gradientBevel,
+	}
+
+	var buf bytes.Buffer
+	if err := newSerializer(&buf).SerializeFilterList(filters); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	p := newParser(bytes.NewReader(buf.Bytes()))
+	parsed, err := p.ParseFilterList()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !reflect.DeepEqual(parsed, filters) {
+		t.Errorf("expected %#v, got %#v", filters, parsed)
+	}
+}
+
+func TestFilterBlurBytes(t *testing.T) {
+	b := []byte{0x01, 0x01, 0x00, 0x80, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x18}
+	p := newParser(bytes.NewReader(b))
+	filters, err := p.ParseFilterList()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := []Filter{&BlurFilter{2.5, 1, 3}}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("expected %#v, got %#v", expected, filters)
+	}
+}
+
+func TestFilterID(t *testing.T) {
+	p := newParser(bytes.NewReader([]byte{0x01, 0x08}))
+	if _, err := p.ParseFilterList(); !errors.Is(err, ErrFilterID) {
+		t.Errorf("expected %v, got %v", ErrFilterID, err)
+	}
+}
@@ -0,0 +1,140 @@
+package swf
+
+import (
+	"io"
+)
+
+// TagIterator reads the tags of a Swf file one at a time.
+// Next only parses the RECORDHEADER of a tag, its payload is decoded when
+// Tag is called and skipped otherwise. The iteration stops after the End
+// tag or at the first error, which is returned by Err:
+//
+//	it := p.Tags()
+//	for it.Next() {
+//		if it.Code() == CodeTagDoABC {
+//			doAbc := it.Tag().(*TagDoABC)
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TagIterator struct {
+	p      *parser
+	code   uint16
+	length uint32
+	long   bool
+	end    int64 // end is the offset right after the payload of the current tag
+	tag    Tag
+	index  int
+	err    error
+	done   bool
+	active bool
+}
+
+// Tags returns an iterator over the tags following the header
+func (p *parser) Tags() *TagIterator {
+	return &TagIterator{p: p}
+}
+
+// Next moves to the next tag, skipping the payload of the current one.
+// It returns false when there are no more tags or when an error occurred
+func (it *TagIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	if it.active {
+		if it.code == CodeTagEnd {
+			it.done = true
+			it.checkFileLength()
+			return false
+		}
+		// A payload overrunning the file has already been reported by Tag
+		if t, ok := it.tag.(*UnknownTag); ok && uint32(len(t.Data)) < it.length {
+			it.done = true
+			return false
+		}
+		if _, err := it.p.r.Seek(it.end, io.SeekStart); err != nil {
+			it.stop(it.p.fail(err, "payload"))
+			return false
+		}
+		it.index++
+	}
+
+	it.p.tagIndex, it.p.tagCode = it.index, 0
+	code, length, long, err := it.p.ParseTagHeader()
+	if err != nil {
+		it.stop(err)
+		return false
+	}
+	begin, err := it.p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		it.stop(it.p.fail(err, "payload"))
+		return false
+	}
+	it.p.tagCode = code
+	it.code, it.length, it.long = code, length, long
+	it.end = begin + int64(length)
+	it.tag = nil
+	it.active = true
+	return true
+}
+
+// stop ends the iteration on err. In lenient mode err is a warning
+func (it *TagIterator) stop(err error) {
+	if it.p.lenient {
+		it.p.warn(err)
+		it.done = true
+		return
+	}
+	it.err = err
+}
+
+// checkFileLength reports a FileLength mismatch in lenient mode, once the
+// End tag is reached
+func (it *TagIterator) checkFileLength() {
+	if it.p.lenient && it.p.fileLength != 0 && it.end != int64(it.p.fileLength) {
+		it.p.warn(&ParseError{it.end, it.index, CodeTagEnd, "Header.FileLength", ErrFileLength})
+	}
+}
+
+// Code returns the code of the current tag without decoding it
+func (it *TagIterator) Code() uint16 {
+	return it.code
+}
+
+// Length returns the payload length of the current tag without decoding it
+func (it *TagIterator) Length() uint32 {
+	return it.length
+}
+
+// Tag decodes the current tag. It returns nil when the decoding fails,
+// the error is then returned by Err and the iteration stops
+func (it *TagIterator) Tag() Tag {
+	if it.tag != nil || it.err != nil || !it.active {
+		return it.tag
+	}
+	t, err := it.p.ParseTagBody(it.code, it.length, it.long)
+	if err != nil {
+		it.err = err
+		return nil
+	}
+	it.tag = t
+	return t
+}
+
+// Index returns the index of the current tag
+func (it *TagIterator) Index() int {
+	return it.index
+}
+
+// Warnings returns the errors recovered from in lenient mode
+func (it *TagIterator) Warnings() []error {
+	return it.p.warnings
+}
+
+// Err returns the error that stopped the iteration, if any.
+// Parsing errors are returned as a *ParseError
+func (it *TagIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,117 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+var iteratorTagsBytes = []byte{
+	0xc3, 0x3f, 0xff, 0xff, 0xff, // Unassigned code 255
+	0x8a, 0x14, 0x01, 0x00, 0x00, 0x00, 0x61, 0x00, 0x2a, 0x2a, 0x2a, 0x2a, // DoABC
+	0x40, 0x00, // ShowFrame
+	0x00, 0x00,
+}
+
+func TestTagIterator(t *testing.T) {
+	p := newParser(bytes.NewReader(iteratorTagsBytes))
+	it := p.Tags()
+
+	var codes []uint16
+	var doAbc Tag
+	for it.Next() {
+		codes = append(codes, it.Code())
+		if it.Code() == CodeTagDoABC {
+			doAbc = it.Tag()
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	correctCodes := []uint16{255, CodeTagDoABC, 1, CodeTagEnd}
+	if !reflect.DeepEqual(codes, correctCodes) {
+		t.Errorf("expected %v, got %v", correctCodes, codes)
+	}
+	correct := &TagDoABC{tag{code: CodeTagDoABC, length: 10}, 1, "a", []byte{0x2a, 0x2a, 0x2a, 0x2a}}
+	if !reflect.DeepEqual(doAbc, correct) {
+		t.Errorf("expected %v, got %v", correct, doAbc)
+	}
+	if it.Next() {
+		t.Errorf("expected false after the End tag, got true")
+	}
+
+	p = newParser(bytes.NewReader(iteratorTagsBytes[:9]))
+	it = p.Tags()
+	if !it.Next() || !it.Next() {
+		t.Fatalf("expected two tag headers, got %v", it.Err())
+	}
+	if it.Length() != 10 {
+		t.Errorf("expected 10, got %v", it.Length())
+	}
+	if tag := it.Tag(); tag != nil {
+		t.Errorf("expected nil, got %v", tag)
+	}
+	if it.Next() {
+		t.Errorf("expected false after an error, got true")
+	}
+	if err := it.Err(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestTagIteratorStream(t *testing.T) {
+	header := []byte{
+		0x80, 0x00, 0x03, 0x20, 0x00, 0x00, 0x02, 0x80, 0x00,
+		0x00, 0x32,
+		0x01, 0x00,
+	}
+	// The tags are followed by garbage, never reached when stopping early
+	body := append(append(header, iteratorTagsBytes[:17]...), 0x3f, 0x00, 0xff)
+
+	var buf bytes.Buffer
+	buf.Write([]byte{'C', 'W', 'S', 10, 0, 0, 0, 0})
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	files := map[uint8][]byte{
+		CompressionZlib: buf.Bytes(),
+		CompressionLZMA: compressLZMA(t, 10, body, false),
+	}
+	for compression, file := range files {
+		p := newParser(bytes.NewReader(file))
+		if _, err := p.ParseHeader(); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		it := p.Tags()
+		var doAbc Tag
+		for it.Next() {
+			if it.Code() == CodeTagDoABC {
+				doAbc = it.Tag()
+				break
+			}
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if doAbc == nil || doAbc.Length() != 10 {
+			t.Errorf("expected a DoABC tag for compression %v, got %v", compression, doAbc)
+		}
+
+		// Iterating further reaches the garbage
+		for it.Next() {
+		}
+		if err := it.Err(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	}
+}
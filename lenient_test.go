@@ -0,0 +1,143 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+var lenientHeaderBytes = []byte{
+	0x46, 0x57, 0x53,
+	0x0a,
+	0x00, 0x00, 0x00, 0x00,
+	0x80, 0x00, 0x03, 0x20, 0x00, 0x00, 0x02, 0x80, 0x00,
+	0x00, 0x32,
+	0x01, 0x00,
+}
+
+// lenientFile builds an uncompressed file with the given FileLength
+func lenientFile(fileLength uint32, tags []byte) []byte {
+	file := append(append([]byte{}, lenientHeaderBytes...), tags...)
+	file[4], file[5], file[6], file[7] = byte(fileLength), byte(fileLength>>8), byte(fileLength>>16), byte(fileLength>>24)
+	return file
+}
+
+func TestLenient(t *testing.T) {
+	fileLength := uint32(len(lenientHeaderBytes) + len(iteratorTagsBytes))
+
+	// Missing End tag
+	file := lenientFile(fileLength, iteratorTagsBytes[:19])
+	if _, err := Parse(bytes.NewReader(file)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	s, err := Parse(bytes.NewReader(file), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if len(s.Tags) != 3 || len(s.Warnings) != 1 {
+		t.Errorf("expected 3 tags and 1 warning, got %v and %v", s.Tags, s.Warnings)
+	}
+	var parseErr *ParseError
+	if !errors.As(s.Warnings[0], &parseErr) || parseErr.TagIndex != 3 {
+		t.Errorf("expected a *ParseError for tag 3, got %v", s.Warnings[0])
+	}
+
+	// DoABC overruns the file
+	file = lenientFile(fileLength, iteratorTagsBytes[:12])
+	s, err = Parse(bytes.NewReader(file), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	correct := []Tag{
+		&UnknownTag{tag{code: 255, length: 3}, false, []byte{0xff, 0xff, 0xff}},
+		&UnknownTag{tag{code: CodeTagDoABC, length: 5}, false, iteratorTagsBytes[7:12]},
+	}
+	if !reflect.DeepEqual(s.Tags, correct) {
+		t.Errorf("expected %v, got %v", correct, s.Tags)
+	}
+	if len(s.Warnings) != 1 || !errors.Is(s.Warnings[0], io.ErrUnexpectedEOF) {
+		t.Errorf("expected a single io.ErrUnexpectedEOF warning, got %v", s.Warnings)
+	}
+
+	// Skipping a payload overrunning the file
+	p := newParser(bytes.NewReader(file), Lenient())
+	if _, err = p.ParseHeader(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	it := p.Tags()
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if count != 2 || it.Err() != nil || len(it.Warnings()) != 1 {
+		t.Errorf("expected 2 tags and 1 warning, got %v, %v and %v", count, it.Err(), it.Warnings())
+	}
+
+	// FileLength mismatch
+	file = lenientFile(fileLength+10, iteratorTagsBytes)
+	s, err = Parse(bytes.NewReader(file), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if len(s.Tags) != 4 || len(s.Warnings) != 1 || !errors.Is(s.Warnings[0], ErrFileLength) {
+		t.Errorf("expected 4 tags and a ErrFileLength warning, got %v and %v", s.Tags, s.Warnings)
+	}
+	s, err = Parse(bytes.NewReader(lenientFile(fileLength, iteratorTagsBytes)), Lenient())
+	if err != nil || len(s.Warnings) != 0 {
+		t.Errorf("expected no warning, got %v and %v", err, s.Warnings)
+	}
+}
+
+func TestLenientLZMA(t *testing.T) {
+	body := append(append([]byte{}, lenientHeaderBytes[8:]...), iteratorTagsBytes...)
+	file := compressLZMA(t, 10, body, false)
+	// FileLength is too small for the uncompressed size
+	file[4] -= 10
+
+	if _, err := Parse(bytes.NewReader(file)); err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+	s, err := Parse(bytes.NewReader(file), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if len(s.Tags) != 4 || len(s.Warnings) != 1 || !errors.Is(s.Warnings[0], ErrFileLength) {
+		t.Errorf("expected 4 tags and a ErrFileLength warning, got %v and %v", s.Tags, s.Warnings)
+	}
+}
+
+func TestLenientDecoder(t *testing.T) {
+	failing := func(r Reader, code uint16, length uint32) (Tag, error) {
+		return nil, ErrMalformedHeader
+	}
+	file := lenientFile(uint32(len(lenientHeaderBytes)+len(iteratorTagsBytes)), iteratorTagsBytes)
+	s, err := Parse(bytes.NewReader(file), Lenient(), RegisterTagDecoder(255, failing))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if _, ok := s.Tags[0].(*UnknownTag); !ok || len(s.Tags) != 4 {
+		t.Errorf("expected the first tag to be kept as *UnknownTag, got %v", s.Tags)
+	}
+	if len(s.Warnings) != 1 || !errors.Is(s.Warnings[0], ErrMalformedHeader) {
+		t.Errorf("expected a single warning, got %v", s.Warnings)
+	}
+}
+
+func TestLenientHugeTagLength(t *testing.T) {
+	// The tag length is not trusted to allocate the payload
+	tags := []byte{0xff, 0x3f, 0xff, 0xff, 0xff, 0xff, 0x01, 0x02}
+	file := lenientFile(uint32(len(lenientHeaderBytes)+len(tags)), tags)
+	if _, err := Parse(bytes.NewReader(file)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	s, err := Parse(bytes.NewReader(file), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	correct := []Tag{&UnknownTag{tag{code: 255, length: 2}, true, []byte{0x01, 0x02}}}
+	if !reflect.DeepEqual(s.Tags, correct) {
+		t.Errorf("expected %v, got %v", correct, s.Tags)
+	}
+}
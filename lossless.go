@@ -0,0 +1,199 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// These represent the formats of a DefineBitsLossless bitmap
+const (
+	BitmapFormatColormapped = 3 // BitmapFormatColormapped is a 8 bits colormapped image
+	BitmapFormatRGB15       = 4 // BitmapFormatRGB15 is a 15 bits RGB image, only in DefineBitsLossless
+	BitmapFormatRGB24       = 5 // BitmapFormatRGB24 is a 24 bits RGB image, or 32 bits ARGB in DefineBitsLossless2
+)
+
+// ErrBitmapFormat means that a lossless bitmap can not be decoded.
+// Its BitmapFormat is unknown
+var ErrBitmapFormat = errors.New("unsupported bitmap format")
+
+// ErrBitmapData means that a lossless bitmap can not be decoded.
+// Its pixel data is shorter than the image size
+var ErrBitmapData = errors.New("bitmap data does not match the image size")
+
+// Image decompresses the pixel data. Colors of DefineBitsLossless2 bitmaps
+// are premultiplied by their alpha, as in image.RGBA
+func (t *TagDefineBitsLossless) Image() (image.Image, error) {
+	r, err := zlib.NewReader(bytes.NewReader(t.ZlibBitmapData))
+	if err != nil {
+		return nil, err
+	}
+	alpha := t.Code() == CodeTagDefineBitsLossless2
+	width, height := int(t.BitmapWidth), int(t.BitmapHeight)
+
+	// Rows are padded to 32 bits
+	var colors []color.RGBA
+	var rowSize int
+	switch {
+	default:
+		return nil, ErrBitmapFormat
+	case t.BitmapFormat == BitmapFormatColormapped:
+		colors, err = readColorTable(r, int(t.BitmapColorTableSize)+1, alpha)
+		if err != nil {
+			return nil, err
+		}
+		rowSize = (width + 3) &^ 3
+	case t.BitmapFormat == BitmapFormatRGB15 && !alpha:
+		rowSize = (width*2 + 3) &^ 3
+	case t.BitmapFormat == BitmapFormatRGB24:
+		rowSize = width * 4
+	}
+
+	// The pixel data is read before allocating the image, so that a size
+	// not matching the data fails without allocating it
+	size := int64(rowSize) * int64(height)
+	var data bytes.Buffer
+	if _, err = data.ReadFrom(io.LimitReader(r, size+1)); err != nil {
+		return nil, bitmapDataError(err)
+	}
+	if int64(data.Len()) < size {
+		return nil, ErrBitmapData
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	pixels := data.Bytes()
+	for y := 0; y < height; y++ {
+		row := pixels[y*rowSize : (y+1)*rowSize]
+		for x := 0; x < width; x++ {
+			var c color.RGBA
+			switch t.BitmapFormat {
+			case BitmapFormatColormapped:
+				if int(row[x]) < len(colors) {
+					c = colors[row[x]]
+				}
+			case BitmapFormatRGB15:
+				pix := uint16(row[2*x])<<8 | uint16(row[2*x+1])
+				c = color.RGBA{expand5(pix >> 10), expand5(pix >> 5), expand5(pix), 0xff}
+			case BitmapFormatRGB24:
+				c = color.RGBA{row[4*x+1], row[4*x+2], row[4*x+3], 0xff}
+				if alpha {
+					c.A = row[4*x]
+				}
+			}
+			img.SetRGBA(x, y, premultiplied(c))
+		}
+	}
+	return img, nil
+}
+
+// readColorTable reads the RGB or RGBA colors of a colormapped bitmap
+func readColorTable(r io.Reader, count int, alpha bool) ([]color.RGBA, error) {
+	size := 3
+	if alpha {
+		size = 4
+	}
+	table := make([]byte, count*size)
+	if _, err := io.ReadFull(r, table); err != nil {
+		return nil, bitmapDataError(err)
+	}
+	colors := make([]color.RGBA, count)
+	for i := range colors {
+		entry := table[i*size:]
+		colors[i] = color.RGBA{entry[0], entry[1], entry[2], 0xff}
+		if alpha {
+			colors[i].A = entry[3]
+		}
+	}
+	return colors, nil
+}
+
+func bitmapDataError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrBitmapData
+	}
+	return err
+}
+
+// expand5 scales the lowest 5 bits of v to 8 bits
+func expand5(v uint16) uint8 {
+	v &= 0x1f
+	return uint8(v<<3 | v>>2)
+}
+
+// premultiplied clamps the colors of a premultiplied color to its alpha
+func premultiplied(c color.RGBA) color.RGBA {
+	for _, ptr := range []*uint8{&c.R, &c.G, &c.B} {
+		if *ptr > c.A {
+			*ptr = c.A
+		}
+	}
+	return c
+}
+
+func (p *parser) ParseTagDefineBitsLossless(length uint32) (Tag, error) {
+	return p.parseTagDefineBitsLossless(CodeTagDefineBitsLossless, length)
+}
+
+// ParseTagDefineBitsLossless2 parses a DefineBitsLossless2 tag, which has
+// the same layout as DefineBitsLossless but colors with alpha
+func (p *parser) ParseTagDefineBitsLossless2(length uint32) (Tag, error) {
+	return p.parseTagDefineBitsLossless(CodeTagDefineBitsLossless2, length)
+}
+
+func (p *parser) parseTagDefineBitsLossless(code uint16, length uint32) (Tag, error) {
+	record := "DefineBitsLossless"
+	if code == CodeTagDefineBitsLossless2 {
+		record = "DefineBitsLossless2"
+	}
+
+	t := &TagDefineBitsLossless{tag: tag{code: code, length: length}}
+	var err error
+	if t.CharacterID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, record+".CharacterID")
+	}
+	if t.BitmapFormat, err = p.r.ReadUInt8(); err != nil {
+		return nil, p.fail(err, record+".BitmapFormat")
+	}
+	if t.BitmapWidth, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, record+".BitmapWidth")
+	}
+	if t.BitmapHeight, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, record+".BitmapHeight")
+	}
+	if t.BitmapFormat == BitmapFormatColormapped {
+		if t.BitmapColorTableSize, err = p.r.ReadUInt8(); err != nil {
+			return nil, p.fail(err, record+".BitmapColorTableSize")
+		}
+	}
+	if t.ZlibBitmapData, err = p.readAll(record + ".ZlibBitmapData"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SerializeTagDefineBitsLossless serializes a DefineBitsLossless or a
+// DefineBitsLossless2 tag
+func (s *serializer) SerializeTagDefineBitsLossless(t *TagDefineBitsLossless) error {
+	if err := s.w.WriteUInt16(t.CharacterID); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt8(t.BitmapFormat); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt16(t.BitmapWidth); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt16(t.BitmapHeight); err != nil {
+		return err
+	}
+	if t.BitmapFormat == BitmapFormatColormapped {
+		if err := s.w.WriteUInt8(t.BitmapColorTableSize); err != nil {
+			return err
+		}
+	}
+	_, err := s.w.Write(t.ZlibBitmapData)
+	return err
+}
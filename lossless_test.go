@@ -0,0 +1,103 @@
+package swf
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestDefineBitsLosslessImage(t *testing.T) {
+	tests := []struct {
+		code   uint16
+		format uint8
+		size   uint8
+		data   []byte
+		pixels []color.RGBA
+	}{
+		{
+			CodeTagDefineBitsLossless, BitmapFormatColormapped, 1,
+			[]byte{
+				0xff, 0x00, 0x00, 0x00, 0x00, 0xff,
+				0x01, 0x00, 0x00, 0x00, // Padded row
+				0x00, 0x01, 0x00, 0x00,
+			},
+			[]color.RGBA{{0, 0, 0xff, 0xff}, {0xff, 0, 0, 0xff}, {0xff, 0, 0, 0xff}, {0, 0, 0xff, 0xff}},
+		},
+		{
+			CodeTagDefineBitsLossless2, BitmapFormatColormapped, 1,
+			[]byte{
+				0x80, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x01, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+			},
+			[]color.RGBA{{0x80, 0, 0, 0x80}, {}, {}, {0x80, 0, 0, 0x80}},
+		},
+		{
+			CodeTagDefineBitsLossless, BitmapFormatRGB15, 0,
+			[]byte{
+				0x7c, 0x00, 0x03, 0xe0,
+				0x00, 0x1f, 0x7f, 0xff,
+			},
+			[]color.RGBA{{0xff, 0, 0, 0xff}, {0, 0xff, 0, 0xff}, {0, 0, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff}},
+		},
+		{
+			CodeTagDefineBitsLossless2, BitmapFormatRGB24, 0,
+			[]byte{
+				0xff, 0x10, 0x20, 0x30, 0x00, 0x00, 0x00, 0x00,
+				0x40, 0x40, 0x20, 0x50, 0xff, 0xff, 0xff, 0xff,
+			},
+			[]color.RGBA{{0x10, 0x20, 0x30, 0xff}, {}, {0x40, 0x20, 0x40, 0x40}, {0xff, 0xff, 0xff, 0xff}},
+		},
+	}
+
+	for _, test := range tests {
+		bitmap := &TagDefineBitsLossless{tag{code: test.code, length: 0}, 1, test.format, 2, 2, test.size, compressZlib(t, test.data)}
+		img, err := bitmap.Image()
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		var pixels []color.RGBA
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				pixels = append(pixels, img.(*image.RGBA).RGBAAt(x, y))
+			}
+		}
+		if !reflect.DeepEqual(pixels, test.pixels) {
+			t.Errorf("expected %v, got %v", test.pixels, pixels)
+		}
+
+		bitmap.ZlibBitmapData = compressZlib(t, test.data[:len(test.data)-1])
+		if _, err = bitmap.Image(); err != ErrBitmapData {
+			t.Errorf("expected ErrBitmapData, got %v", err)
+		}
+	}
+
+	bitmap := &TagDefineBitsLossless{tag{code: CodeTagDefineBitsLossless2, length: 0}, 1, BitmapFormatRGB15, 2, 2, 0, compressZlib(t, nil)}
+	if _, err := bitmap.Image(); err != ErrBitmapFormat {
+		t.Errorf("expected ErrBitmapFormat, got %v", err)
+	}
+
+	// The image is not allocated from a size the data does not match
+	huge := &TagDefineBitsLossless{tag{code: CodeTagDefineBitsLossless, length: 0}, 1, BitmapFormatRGB24, 0xffff, 0xffff, 0, compressZlib(t, []byte{0, 1, 2, 3})}
+	if _, err := huge.Image(); err != ErrBitmapData {
+		t.Errorf("expected ErrBitmapData, got %v", err)
+	}
+}
+
+func TestParseTagDefineBitsLossless(t *testing.T) {
+	tagsBytes := []byte{
+		0x09, 0x05, 0x01, 0x00, 0x05, 0x02, 0x00, 0x03, 0x00, 0xaa, 0xbb, // DefineBitsLossless
+		0x0a, 0x09, 0x02, 0x00, 0x03, 0x04, 0x00, 0x05, 0x00, 0x0f, 0xcc, 0xdd, // DefineBitsLossless2
+		0x00, 0x00,
+	}
+	tags := roundTripTags(t, tagsBytes)
+	correct := []Tag{
+		&TagDefineBitsLossless{tag{code: CodeTagDefineBitsLossless, length: 9}, 1, BitmapFormatRGB24, 2, 3, 0, []byte{0xaa, 0xbb}},
+		&TagDefineBitsLossless{tag{code: CodeTagDefineBitsLossless2, length: 10}, 2, BitmapFormatColormapped, 4, 5, 15, []byte{0xcc, 0xdd}},
+		&tag{code: CodeTagEnd, length: 0},
+	}
+	if !reflect.DeepEqual(tags, correct) {
+		t.Errorf("expected %v, got %v", correct, tags)
+	}
+}
@@ -0,0 +1,173 @@
+package swf
+
+import (
+	"math"
+)
+
+// newMatrix creates a matrix from its coefficients, the translation is
+// rounded to the nearest twip
+func newMatrix(a, b, c, d, tx, ty float64) Matrix {
+	m := Matrix{TranslateX: int32(math.Round(tx)), TranslateY: int32(math.Round(ty))}
+	if a != 1 || d != 1 {
+		m.HasScale, m.ScaleX, m.ScaleY = true, a, d
+	}
+	if b != 0 || c != 0 {
+		m.HasRotate, m.RotateSkew0, m.RotateSkew1 = true, b, c
+	}
+	return m
+}
+
+// coefficients returns the scale and rotate values of the matrix.
+// The values of the identity matrix are used when they are not set
+func (m Matrix) coefficients() (a, b, c, d float64) {
+	a, b, c, d = 1, 0, 0, 1
+	if m.HasScale {
+		a, d = m.ScaleX, m.ScaleY
+	}
+	if m.HasRotate {
+		b, c = m.RotateSkew0, m.RotateSkew1
+	}
+	return a, b, c, d
+}
+
+// Transform transforms a point, in twips
+func (m Matrix) Transform(x, y float64) (float64, float64) {
+	a, b, c, d := m.coefficients()
+	return a*x + c*y + float64(m.TranslateX), b*x + d*y + float64(m.TranslateY)
+}
+
+// Multiply composes two matrices. The result transforms a point by n then
+// by m, such as the matrix of a parent multiplied by the matrix of a child.
+// The translation is rounded to the nearest twip
+func (m Matrix) Multiply(n Matrix) Matrix {
+	ma, mb, mc, md := m.coefficients()
+	na, nb, nc, nd := n.coefficients()
+	tx, ty := m.Transform(float64(n.TranslateX), float64(n.TranslateY))
+	return newMatrix(ma*na+mc*nb, mb*na+md*nb, ma*nc+mc*nd, mb*nc+md*nd, tx, ty)
+}
+
+// Invert returns the inverse of the matrix, its translation is rounded to
+// the nearest twip. It returns false when the matrix can not be inverted
+func (m Matrix) Invert() (Matrix, bool) {
+	a, b, c, d := m.coefficients()
+	det := a*d - b*c
+	if det == 0 {
+		return Matrix{}, false
+	}
+	ia, ib, ic, id := d/det, -b/det, -c/det, a/det
+	tx, ty := float64(m.TranslateX), float64(m.TranslateY)
+	return newMatrix(ia, ib, ic, id, -(ia*tx + ic*ty), -(ib*tx + id*ty)), true
+}
+
+// fixedBits converts a 16.16 fixed point number to the signed bit value
+// it is written as
+func fixedBits(v float64) int32 {
+	return int32(math.Floor(v * 65536))
+}
+
+// ParseMatrix parses a Matrix record, which starts on a byte boundary
+func (p *parser) ParseMatrix() (m Matrix, err error) {
+	p.align()
+	hasScale, err := p.r.ReadUBitValue(1)
+	if err != nil {
+		return m, p.fail(err, "MATRIX.HasScale")
+	}
+	if m.HasScale = hasScale == 1; m.HasScale {
+		if m.NScaleBits, m.ScaleX, m.ScaleY, err = p.parseMatrixPair("MATRIX.NScaleBits", "MATRIX.ScaleX", "MATRIX.ScaleY"); err != nil {
+			return m, err
+		}
+	}
+
+	hasRotate, err := p.r.ReadUBitValue(1)
+	if err != nil {
+		return m, p.fail(err, "MATRIX.HasRotate")
+	}
+	if m.HasRotate = hasRotate == 1; m.HasRotate {
+		if m.NRotateBits, m.RotateSkew0, m.RotateSkew1, err = p.parseMatrixPair("MATRIX.NRotateBits", "MATRIX.RotateSkew0", "MATRIX.RotateSkew1"); err != nil {
+			return m, err
+		}
+	}
+
+	nBits, err := p.r.ReadUBitValue(5)
+	if err != nil {
+		return m, p.fail(err, "MATRIX.NTranslateBits")
+	}
+	m.NTranslateBits = uint8(nBits)
+	if m.TranslateX, err = p.readBits(m.NTranslateBits, "MATRIX.TranslateX"); err != nil {
+		return m, err
+	}
+	if m.TranslateY, err = p.readBits(m.NTranslateBits, "MATRIX.TranslateY"); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// parseMatrixPair parses a bit count followed by two fixed point values
+func (p *parser) parseMatrixPair(nRecord, xRecord, yRecord string) (n uint8, x, y float64, err error) {
+	nBits, err := p.r.ReadUBitValue(5)
+	if err != nil {
+		return 0, 0, 0, p.fail(err, nRecord)
+	}
+	n = uint8(nBits)
+	vx, err := p.readBits(n, xRecord)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	vy, err := p.readBits(n, yRecord)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return n, float64(vx) / 65536, float64(vy) / 65536, nil
+}
+
+// SerializeMatrix serializes a Matrix record.
+// The bit counts are increased when they are too small to hold the values
+func (s *serializer) SerializeMatrix(m Matrix) error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if err := s.serializeMatrixPair(m.HasScale, m.NScaleBits, m.ScaleX, m.ScaleY); err != nil {
+		return err
+	}
+	if err := s.serializeMatrixPair(m.HasRotate, m.NRotateBits, m.RotateSkew0, m.RotateSkew1); err != nil {
+		return err
+	}
+
+	nBits := m.NTranslateBits
+	if m.TranslateX != 0 || m.TranslateY != 0 {
+		if needed := signedBits(m.TranslateX, m.TranslateY); needed > nBits {
+			nBits = needed
+		}
+	}
+	if err := s.w.WriteUBitValue(uint32(nBits), 5); err != nil {
+		return err
+	}
+	if err := s.writeBits(m.TranslateX, nBits); err != nil {
+		return err
+	}
+	return s.writeBits(m.TranslateY, nBits)
+}
+
+// serializeMatrixPair serializes a flag, and when it is set a bit count
+// followed by two fixed point values
+func (s *serializer) serializeMatrixPair(has bool, nBits uint8, x, y float64) error {
+	if !has {
+		return s.w.WriteUBitValue(0, 1)
+	}
+	if err := s.w.WriteUBitValue(1, 1); err != nil {
+		return err
+	}
+	vx, vy := fixedBits(x), fixedBits(y)
+	if vx != 0 || vy != 0 {
+		if needed := signedBits(vx, vy); needed > nBits {
+			nBits = needed
+		}
+	}
+	if err := s.w.WriteUBitValue(uint32(nBits), 5); err != nil {
+		return err
+	}
+	if err := s.writeBits(vx, nBits); err != nil {
+		return err
+	}
+	return s.writeBits(vy, nBits)
+}
@@ -0,0 +1,85 @@
+package swf
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	matrixBytes := []byte{0xc9, 0x80, 0x00, 0xc0, 0x00, 0x07, 0x29, 0x60}
+	m, err := newParser(bytes.NewReader(matrixBytes)).ParseMatrix()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := Matrix{HasScale: true, NScaleBits: 18, ScaleX: 1.5, ScaleY: -1, NTranslateBits: 7, TranslateX: 20, TranslateY: -40}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %#v, got %#v", expected, m)
+	}
+
+	var buf bytes.Buffer
+	s := newSerializer(&buf)
+	if err = s.SerializeMatrix(m); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err = s.w.Flush(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), matrixBytes) {
+		t.Errorf("expected %#v, got %#v", matrixBytes, buf.Bytes())
+	}
+}
+
+func TestMatrixTransform(t *testing.T) {
+	tests := []struct {
+		m    Matrix
+		x, y float64
+	}{
+		{Matrix{}, 10, 20},
+		{Matrix{TranslateX: 5, TranslateY: -5}, 15, 15},
+		{Matrix{HasScale: true, ScaleX: 2, ScaleY: 0.5}, 20, 10},
+		{Matrix{HasRotate: true, RotateSkew0: 1, RotateSkew1: -1}, -10, 30},
+	}
+	for _, test := range tests {
+		x, y := test.m.Transform(10, 20)
+		if x != test.x || y != test.y {
+			t.Errorf("expected (%v, %v), got (%v, %v)", test.x, test.y, x, y)
+		}
+	}
+}
+
+func TestMatrixMultiply(t *testing.T) {
+	parent := Matrix{HasScale: true, ScaleX: 2, ScaleY: 2, TranslateX: 100}
+	child := Matrix{HasRotate: true, RotateSkew0: 1, RotateSkew1: -1, TranslateY: 10}
+	m := parent.Multiply(child)
+
+	expected := Matrix{HasScale: true, ScaleX: 2, ScaleY: 2, HasRotate: true, RotateSkew0: 2, RotateSkew1: -2, TranslateX: 100, TranslateY: 20}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %#v, got %#v", expected, m)
+	}
+	x, y := child.Transform(3, 4)
+	x, y = parent.Transform(x, y)
+	if mx, my := m.Transform(3, 4); mx != x || my != y {
+		t.Errorf("expected (%v, %v), got (%v, %v)", x, y, mx, my)
+	}
+}
+
+func TestMatrixInvert(t *testing.T) {
+	m := Matrix{HasScale: true, ScaleX: 2, ScaleY: 1, HasRotate: true, RotateSkew0: 1, RotateSkew1: 1, TranslateX: 30, TranslateY: -60}
+	inverse, ok := m.Invert()
+	if !ok {
+		t.Fatalf("expected true, got false")
+	}
+	x, y := inverse.Transform(m.Transform(7, -3))
+	if math.Abs(x-7) > 1e-9 || math.Abs(y+3) > 1e-9 {
+		t.Errorf("expected (7, -3), got (%v, %v)", x, y)
+	}
+	if identity := m.Multiply(inverse); !reflect.DeepEqual(identity, Matrix{}) {
+		t.Errorf("expected identity, got %#v", identity)
+	}
+
+	if _, ok = (Matrix{HasScale: true}).Invert(); ok {
+		t.Errorf("expected false, got true")
+	}
+}
@@ -3,41 +3,69 @@ package swf
 import (
 	"bytes"
 	"compress/zlib"
+	"encoding/binary"
 	"errors"
 	"io"
-	"io/ioutil"
+
+	"github.com/ulikunitz/xz/lzma"
 )
 
 // ErrMalformedHeader means that the swf file header is malformed.
 // The signature is malformed
 var ErrMalformedHeader = errors.New("malformed header")
 
+// ErrFileLength means that the FileLength of the header does not match the
+// actual length of the file. It is only reported in lenient mode
+var ErrFileLength = errors.New("file length mismatch")
+
 // ErrUnsupportedFile means that the swf file is not supported.
 // The file is compressed with an unsupported algorithm
 var ErrUnsupportedFile = errors.New("unsupported file")
 
-// Parser is the minimal interface for parsing a Swf file
+// Parser is the minimal interface for parsing a Swf file.
+// Tags must be called after ParseHeader, it iterates over the tags
+// without parsing the entire file
 type Parser interface {
 	Parse() (Swf, error)
+	ParseHeader() (Header, error)
+	Tags() *TagIterator
 }
 
 type parser struct {
-	r      Reader
-	origin io.ReadSeeker
+	r        Reader
+	origin   io.ReadSeeker
+	decoders map[uint16]TagDecoder
+	tagIndex int
+	tagCode  uint16
+
+	lenient    bool
+	warnings   []error
+	fileLength uint32
+	version    uint8
+	embedded   bool
+	depth      int // depth is the nesting depth of an embedded file
+	opts       []Option
+	jpegTables []byte
 }
 
-func newParser(origin io.ReadSeeker) *parser {
-	return &parser{NewReader(origin), origin}
+func newParser(origin io.ReadSeeker, opts ...Option) *parser {
+	p := &parser{r: NewReader(origin), origin: origin, tagIndex: -1, opts: opts}
+	p.registerBuiltinDecoders()
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // Parse creates a Parser and parses the given input
-func Parse(origin io.ReadSeeker) (Swf, error) {
-	return newParser(origin).Parse()
+func Parse(origin io.ReadSeeker, opts ...Option) (Swf, error) {
+	return newParser(origin, opts...).Parse()
 }
 
-// NewParser provides a simple way to create a Swf file
-func NewParser(origin io.ReadSeeker) Parser {
-	return newParser(origin)
+// NewParser provides a simple way to create a Swf file.
+// Options can register custom tag decoders, see RegisterTagDecoder
+func NewParser(origin io.ReadSeeker, opts ...Option) Parser {
+	return newParser(origin, opts...)
 }
 
 func (p *parser) handleEOF(err error) error {
@@ -47,7 +75,48 @@ func (p *parser) handleEOF(err error) error {
 	return err
 }
 
-// Parse parses an entire Swf file
+// readAll reads the rest of the payload of a tag
+func (p *parser) readAll(record string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(p.r); err != nil {
+		return nil, p.fail(err, record)
+	}
+	return buf.Bytes(), nil
+}
+
+// align skips the pending bits up to the next byte boundary
+func (p *parser) align() {
+	if a, ok := p.r.(aligner); ok {
+		a.Align()
+	}
+}
+
+// readUBits reads an unsigned bit value whose bit count may be 0
+func (p *parser) readUBits(n uint8, record string) (uint32, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := p.r.ReadUBitValue(n)
+	return v, p.fail(err, record)
+}
+
+// readBits reads a signed bit value whose bit count may be 0
+func (p *parser) readBits(n uint8, record string) (int32, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := p.r.ReadBitValue(n)
+	return v, p.fail(err, record)
+}
+
+// warn records a recoverable error in lenient mode
+func (p *parser) warn(err error) {
+	p.warnings = append(p.warnings, err)
+}
+
+// Parse parses an entire Swf file.
+// In lenient mode, the tags decoded before a failure are returned along
+// with the warnings, see Lenient
 func (p *parser) Parse() (s Swf, err error) {
 	header, err := p.ParseHeader()
 	if err != nil {
@@ -59,11 +128,15 @@ func (p *parser) Parse() (s Swf, err error) {
 		return
 	}
 	s.Tags = tags
+	s.Warnings = p.warnings
 
 	return
 }
 
-func (p *parser) replaceReader(compression uint8) error {
+// replaceReader makes the parser read the decompressed body of the file.
+// The body is decompressed on the fly, so that the tags can be read
+// without holding the entire file in memory
+func (p *parser) replaceReader(compression uint8, fileLength uint32) error {
 	switch compression {
 	default:
 		break
@@ -76,71 +149,123 @@ func (p *parser) replaceReader(compression uint8) error {
 		if err != nil {
 			return err
 		}
-		buf, err := ioutil.ReadAll(r)
+		p.r = NewReader(newStreamReader(r, 8))
+	case CompressionLZMA:
+		r, err := p.newLZMAReader(fileLength)
 		if err != nil {
 			return err
 		}
-		if err = r.Close(); err != nil {
-			return err
-		}
-		p.r = NewReader(bytes.NewReader(buf))
+		p.r = NewReader(newStreamReader(r, 8))
 	}
 	return nil
 }
 
+// newLZMAReader creates a reader decompressing the body of a 'ZWS' file.
+// Swf files do not use the .lzma header: the uncompressed size is replaced
+// by the compressed length, and the properties come right after it.
+// The .lzma header is rebuilt from the properties and the uncompressed size,
+// which is FileLength minus the 8 bytes of the uncompressed header.
+// In lenient mode FileLength is not trusted and the size is left unknown.
+func (p *parser) newLZMAReader(fileLength uint32) (io.Reader, error) {
+	if fileLength < 8 {
+		return nil, ErrMalformedHeader
+	}
+	_, err := p.origin.Seek(8, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	var compressedLength uint32
+	if err = binary.Read(p.origin, binary.LittleEndian, &compressedLength); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, lzma.HeaderLen)
+	if _, err = io.ReadFull(p.origin, header[:5]); err != nil {
+		return nil, err
+	}
+	size := uint64(fileLength - 8)
+	if p.lenient {
+		size = 1<<64 - 1
+	}
+	binary.LittleEndian.PutUint64(header[5:], size)
+
+	src := io.MultiReader(bytes.NewReader(header), io.LimitReader(p.origin, int64(compressedLength)))
+	r, err := lzma.NewReader(src)
+	if err != nil || !p.lenient {
+		return r, err
+	}
+	return &drainReader{r}, nil
+}
+
+// drainReader reads a LZMA stream of unknown size without end marker.
+// The decoder fails when the compressed data ends, but the data decoded
+// before the failure is still returned by the following reads
+type drainReader struct {
+	r io.Reader
+}
+
+func (d *drainReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if n == 0 && err != nil && err != io.EOF {
+		return d.r.Read(p)
+	}
+	return n, err
+}
+
 func (p *parser) ParseHeader() (Header, error) {
 	signature, err := p.r.ReadUInt8()
 	var compression uint8
 
 	if err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, p.fail(err, "Header.Signature")
 	}
 	switch signature {
 	default:
-		return Header{}, ErrMalformedHeader
+		return Header{}, p.fail(ErrMalformedHeader, "Header.Signature")
 	case 'F':
 		compression = CompressionNone
 	case 'C':
 		compression = CompressionZlib
 	case 'Z':
-		return Header{}, ErrUnsupportedFile
+		compression = CompressionLZMA
 	}
 
 	if signature, err = p.r.ReadUInt8(); err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, p.fail(err, "Header.Signature")
 	} else if signature != 'W' {
-		return Header{}, ErrMalformedHeader
+		return Header{}, p.fail(ErrMalformedHeader, "Header.Signature")
 	}
 	if signature, err = p.r.ReadUInt8(); err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, p.fail(err, "Header.Signature")
 	} else if signature != 'S' {
-		return Header{}, ErrMalformedHeader
+		return Header{}, p.fail(ErrMalformedHeader, "Header.Signature")
 	}
 	version, err := p.r.ReadUInt8()
 	if err != nil {
-		return Header{}, err
+		return Header{}, p.fail(err, "Header.Version")
 	}
 	fileLength, err := p.r.ReadUInt32()
 	if err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, p.fail(err, "Header.FileLength")
 	}
 
-	if err = p.replaceReader(compression); err != nil {
-		return Header{}, p.handleEOF(err)
+	p.fileLength, p.version = fileLength, version
+	if err = p.replaceReader(compression, fileLength); err != nil {
+		return Header{}, p.fail(err, "Header.Body")
 	}
 
 	frameSize, err := p.ParseRect()
 	if err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, err
 	}
 
 	frameRate, err := p.r.ReadFixed8()
 	if err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, p.fail(err, "Header.FrameRate")
 	}
 	frameCount, err := p.r.ReadUInt16()
 	if err != nil {
-		return Header{}, p.handleEOF(err)
+		return Header{}, p.fail(err, "Header.FrameCount")
 	}
 	return Header{compression, version, fileLength, frameSize, frameRate, frameCount}, nil
 }
@@ -148,61 +273,96 @@ func (p *parser) ParseHeader() (Header, error) {
 func (p *parser) ParseTags() ([]Tag, error) {
 	var tags []Tag
 
-	for {
-		t, err := p.ParseTag()
-		if err != nil {
-			return nil, err
-		}
-		if t != nil {
+	it := p.Tags()
+	for it.Next() {
+		if t := it.Tag(); t != nil {
 			tags = append(tags, t)
-			if t.Code() == CodeTagEnd {
-				break
-			}
 		}
 	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
 
 	return tags, nil
 }
 
 func (p *parser) ParseTag() (Tag, error) {
+	code, length, long, err := p.ParseTagHeader()
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseTagBody(code, length, long)
+}
+
+// ParseTagHeader parses a RECORDHEADER, long is true when the length is
+// stored in a separate uint32
+func (p *parser) ParseTagHeader() (code uint16, length uint32, long bool, err error) {
 	codeAndLength, err := p.r.ReadUInt16()
 	if err != nil {
-		return nil, p.handleEOF(err)
+		return 0, 0, false, p.fail(err, "RECORDHEADER.TagCodeAndLength")
 	}
-	code := (codeAndLength >> 6) & 0x3ff
-	length := uint32(codeAndLength & 0x3f)
-	if length == 0x3f {
+	code = (codeAndLength >> 6) & 0x3ff
+	length = uint32(codeAndLength & 0x3f)
+	long = length == 0x3f
+	if long {
 		length, err = p.r.ReadUInt32()
 		if err != nil {
-			return nil, p.handleEOF(err)
+			return 0, 0, false, p.fail(err, "RECORDHEADER.Length")
 		}
 	}
+	return code, length, long, nil
+}
 
-	type handleFunc func(uint32) (Tag, error)
-	supportedTags := map[uint16]handleFunc{
-		CodeTagEnd:   p.ParseTagEnd,
-		CodeTagDoABC: p.ParseTagDoABC,
+// ParseTagBody parses the payload of a tag whose RECORDHEADER was just parsed.
+// The payload is decoded by the decoder registered for the code, tags without
+// decoder are kept as UnknownTag. In lenient mode, truncated payloads and
+// payloads that fail to decode are kept as UnknownTag too
+func (p *parser) ParseTagBody(code uint16, length uint32, long bool) (Tag, error) {
+	begin, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, p.fail(err, "payload")
 	}
-
-	if handler, found := supportedTags[code]; found {
-		t, err := handler(length)
-		if err != nil {
-			return nil, p.handleEOF(err)
+	// The payload is not allocated from its length up front, a corrupt
+	// length would otherwise allocate up to 4GB
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(io.LimitReader(p.r, int64(length)))
+	if err == nil && n < int64(length) {
+		err = io.ErrUnexpectedEOF
+		if n == 0 {
+			err = io.EOF
 		}
-		return t, nil
+	}
+	data := buf.Bytes()
+	if err != nil {
+		if !p.lenient {
+			return nil, p.fail(err, "payload")
+		}
+		p.warn(p.fail(err, "payload"))
+		return &UnknownTag{tag{code: code, length: uint32(n)}, long, data}, nil
 	}
 
-	// Discard data since we can not handle it
-	if n, err := io.CopyN(ioutil.Discard, p.r, int64(length)); err != nil {
-		return nil, p.handleEOF(err)
-	} else if uint32(n) != length {
-		return nil, io.ErrUnexpectedEOF
+	decoder, found := p.decoders[code]
+	if !found || decoder == nil {
+		return &UnknownTag{tag{code: code, length: length}, long, data}, nil
+	}
+	payload := *p
+	payload.r = NewReader(&payloadReader{bytes.NewReader(data), begin})
+	t, err := decoder(payload.r, code, length)
+	if err != nil {
+		if !p.lenient {
+			return nil, payload.fail(err, "payload")
+		}
+		p.warn(payload.fail(err, "payload"))
+		return &UnknownTag{tag{code: code, length: length}, long, data}, nil
 	}
-	return nil, nil
+	if h, ok := t.(longHeaderTag); ok {
+		h.setLongHeader(long)
+	}
+	return t, nil
 }
 
 func (p *parser) ParseTagEnd(length uint32) (Tag, error) {
-	return &tag{CodeTagEnd, length}, nil
+	return &tag{code: CodeTagEnd, length: length}, nil
 }
 
 func (p *parser) ParseTagDoABC(length uint32) (Tag, error) {
@@ -212,11 +372,11 @@ func (p *parser) ParseTagDoABC(length uint32) (Tag, error) {
 	}
 	flags, err := p.r.ReadUInt32()
 	if err != nil {
-		return nil, p.handleEOF(err)
+		return nil, p.fail(err, "DoABC.Flags")
 	}
 	name, err := p.r.ReadString()
 	if err != nil {
-		return nil, p.handleEOF(err)
+		return nil, p.fail(err, "DoABC.Name")
 	}
 	end, err := p.r.Seek(0, io.SeekCurrent)
 	if err != nil {
@@ -224,42 +384,70 @@ func (p *parser) ParseTagDoABC(length uint32) (Tag, error) {
 	}
 	abcDataLen := length - uint32(end-begin)
 	abcData := make([]byte, abcDataLen)
-	if n, err := io.ReadFull(p.r, abcData); err != nil {
-		return nil, p.handleEOF(err)
-	} else if uint32(n) != abcDataLen {
-		return nil, io.ErrUnexpectedEOF
+	if _, err := io.ReadFull(p.r, abcData); err != nil {
+		return nil, p.fail(err, "DoABC.ABCData")
 	}
-	return &TagDoABC{tag{CodeTagDoABC, length}, flags, name, abcData}, nil
+	return &TagDoABC{tag{code: CodeTagDoABC, length: length}, flags, name, abcData}, nil
 }
 
 // ParseRect parses a Rectangle record
 func (p *parser) ParseRect() (rect Rect, err error) {
 	nBits, err := p.r.ReadUBitValue(5)
 	if err != nil {
-		return
+		return rect, p.fail(err, "RECT.Nbits")
 	}
 	rect.NBits = uint8(nBits)
 
-	readField := func(ptr *int32) error {
-		value, fieldErr := p.r.ReadBitValue(rect.NBits)
-		if fieldErr == io.EOF {
-			fieldErr = io.ErrUnexpectedEOF
+	fields := []struct {
+		ptr    *int32
+		record string
+	}{
+		{&rect.Xmin, "RECT.Xmin"},
+		{&rect.Xmax, "RECT.Xmax"},
+		{&rect.Ymin, "RECT.Ymin"},
+		{&rect.Ymax, "RECT.Ymax"},
+	}
+	for _, field := range fields {
+		if *field.ptr, err = p.r.ReadBitValue(rect.NBits); err != nil {
+			return rect, p.fail(err, field.record)
 		}
-		*ptr = value
-		return fieldErr
 	}
+	return rect, nil
+}
 
-	if err = readField(&rect.Xmin); err != nil {
-		return
+// ParseRGB parses a RGB record
+func (p *parser) ParseRGB() (color RGB, err error) {
+	fields := []struct {
+		ptr    *uint8
+		record string
+	}{
+		{&color.Red, "RGB.Red"},
+		{&color.Green, "RGB.Green"},
+		{&color.Blue, "RGB.Blue"},
 	}
-	if err = readField(&rect.Xmax); err != nil {
-		return
+	for _, field := range fields {
+		if *field.ptr, err = p.r.ReadUInt8(); err != nil {
+			return color, p.fail(err, field.record)
+		}
 	}
-	if err = readField(&rect.Ymin); err != nil {
-		return
+	return color, nil
+}
+
+// ParseRGBA parses a RGBA record
+func (p *parser) ParseRGBA() (color RGBA, err error) {
+	fields := []struct {
+		ptr    *uint8
+		record string
+	}{
+		{&color.Red, "RGBA.Red"},
+		{&color.Green, "RGBA.Green"},
+		{&color.Blue, "RGBA.Blue"},
+		{&color.Alpha, "RGBA.Alpha"},
 	}
-	if err = readField(&rect.Ymax); err != nil {
-		return
+	for _, field := range fields {
+		if *field.ptr, err = p.r.ReadUInt8(); err != nil {
+			return color, p.fail(err, field.record)
+		}
 	}
-	return
+	return color, nil
 }
@@ -2,10 +2,14 @@ package swf
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"io"
 	"os"
 	"reflect"
 	"testing"
+
+	"github.com/ulikunitz/xz/lzma"
 )
 
 const (
@@ -40,9 +44,15 @@ func TestParse(t *testing.T) {
 	if !reflect.DeepEqual(swf.Header, correctHeader) {
 		t.Errorf("expected %v, got %v", correctHeader, swf.Header)
 	}
-	doAbc, ok := swf.Tags[0].(*TagDoABC)
-	if !ok {
-		t.Errorf("expected %v to be a *TagDoABC", swf.Tags[0])
+	var doAbc *TagDoABC
+	for _, tag := range swf.Tags {
+		if cast, ok := tag.(*TagDoABC); ok {
+			doAbc = cast
+			break
+		}
+	}
+	if doAbc == nil {
+		t.Fatalf("expected a *TagDoABC in %v", swf.Tags)
 	}
 
 	if doAbc.Name != "frame1" {
@@ -80,6 +90,115 @@ func TestParseHeader(t *testing.T) {
 	}
 }
 
+// compressLZMA builds a 'ZWS' file from the body of an uncompressed one
+func compressLZMA(t *testing.T, version uint8, body []byte, eos bool) []byte {
+	var buf bytes.Buffer
+	w, err := lzma.WriterConfig{
+		Size:      int64(len(body)),
+		EOSMarker: eos,
+	}.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if _, err = w.Write(body); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	compressed := buf.Bytes()
+
+	out := []byte{'Z', 'W', 'S', version, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)+8))
+	binary.LittleEndian.PutUint32(out[8:], uint32(len(compressed)-lzma.HeaderLen))
+	out = append(out, compressed[:5]...)
+	return append(out, compressed[lzma.HeaderLen:]...)
+}
+
+func TestParseHeaderLZMA(t *testing.T) {
+	body := []byte{
+		0x80, 0x00, 0x03, 0x20, 0x00, 0x00, 0x02, 0x80, 0x00,
+		0x00, 0x32,
+		0x01, 0x00,
+		0x00, 0x00,
+	}
+	correctHeader := Header{
+		CompressionLZMA,
+		13, uint32(len(body) + 8),
+		Rect{16, 0, 25600, 0, 20480},
+		50.0, 1,
+	}
+
+	for _, eos := range []bool{false, true} {
+		p := newParser(bytes.NewReader(compressLZMA(t, 13, body, eos)))
+		swf, err := p.Parse()
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if !reflect.DeepEqual(swf.Header, correctHeader) {
+			t.Errorf("expected %v, got %v", correctHeader, swf.Header)
+		}
+		if len(swf.Tags) != 1 || swf.Tags[0].Code() != CodeTagEnd {
+			t.Errorf("expected a single End tag, got %v", swf.Tags)
+		}
+	}
+
+	truncated := compressLZMA(t, 13, body, false)
+	p := newParser(bytes.NewReader(truncated[:len(truncated)-4]))
+	if _, err := p.ParseHeader(); err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+}
+
+func TestParseTagsUnknown(t *testing.T) {
+	tagsBytes := []byte{
+		0xc3, 0x3f, 0xff, 0xff, 0xff, // Unassigned code 255, short header
+		0xbf, 0x3f, 0x01, 0x00, 0x00, 0x00, 0x2a, // Unassigned code 254, long header
+		0x00, 0x00,
+	}
+	p := newParser(bytes.NewReader(tagsBytes))
+	tags, err := p.ParseTags()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	correct := []Tag{
+		&UnknownTag{tag{code: 255, length: 3}, false, []byte{0xff, 0xff, 0xff}},
+		&UnknownTag{tag{code: 254, length: 1}, true, []byte{0x2a}},
+		&tag{code: CodeTagEnd, length: 0},
+	}
+	if !reflect.DeepEqual(tags, correct) {
+		t.Errorf("expected %v, got %v", correct, tags)
+	}
+
+	var buf bytes.Buffer
+	ser := newSerializer(&buf)
+	if err = ser.SerializeTags(tags); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), tagsBytes) {
+		t.Errorf("expected %#v, got %#v", tagsBytes, buf.Bytes())
+	}
+
+	p = newParser(bytes.NewReader(tagsBytes[:4]))
+	if _, err = p.ParseTags(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestParseTagsLongHeader(t *testing.T) {
+	// Typed tags keep a long header even when their length is short
+	tagsBytes := []byte{
+		0x7f, 0x02, 0x03, 0x00, 0x00, 0x00, 0x10, 0x20, 0x30, // SetBackgroundColor, long header
+		0x00, 0x00,
+	}
+	tags := roundTripTags(t, tagsBytes)
+	correct := &TagSetBackgroundColor{tag{code: CodeTagSetBackgroundColor, length: 3, long: true}, RGB{0x10, 0x20, 0x30}}
+	if !reflect.DeepEqual(tags[0], correct) {
+		t.Errorf("expected %v, got %v", correct, tags[0])
+	}
+}
+
 func TestParseRect(t *testing.T) {
 	rectBytes := []byte{0x80, 0x00, 0x03, 0x20, 0x00, 0x00, 0x02, 0x80, 0x00}
 	p := newParser(bytes.NewReader(rectBytes))
@@ -115,7 +234,7 @@ func TestParseRect(t *testing.T) {
 		func(b []byte) {
 			pFail := newParser(bytes.NewReader(b))
 			_, err := pFail.ParseRect()
-			if err != io.ErrUnexpectedEOF {
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
 				t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
 			}
 		}(fail)
@@ -140,3 +259,22 @@ func TestHandleEOF(t *testing.T) {
 		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
 	}
 }
+
+// roundTripTags parses tags and checks that they are serialized back
+// to the same bytes
+func roundTripTags(t *testing.T, tagsBytes []byte) []Tag {
+	p := newParser(bytes.NewReader(tagsBytes))
+	tags, err := p.ParseTags()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err = newSerializer(&buf).SerializeTags(tags); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), tagsBytes) {
+		t.Errorf("expected %#v, got %#v", tagsBytes, buf.Bytes())
+	}
+	return tags
+}
@@ -28,6 +28,12 @@ type Reader interface {
 	ReadString() (string, error)
 }
 
+// aligner is implemented by the readers able to skip the pending bits up
+// to the next byte boundary, such as the readers of NewReader
+type aligner interface {
+	Align() (skipped byte)
+}
+
 type byteReadSeeker interface {
 	io.ReadSeeker
 	io.ByteReader
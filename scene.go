@@ -0,0 +1,132 @@
+package swf
+
+import "io"
+
+// Scenes returns the first frame of the scenes named by the
+// DefineSceneAndFrameLabelData tags, frames are numbered from 0.
+// Files without such a tag, which are not ActionScript 3 files, have no
+// scene
+func (s Swf) Scenes() map[string]int {
+	scenes := make(map[string]int)
+	for _, t := range s.Tags {
+		if t, ok := t.(*TagDefineSceneAndFrameLabelData); ok {
+			for _, scene := range t.Scenes {
+				scenes[scene.Name] = int(scene.Offset)
+			}
+		}
+	}
+	return scenes
+}
+
+// FrameLabels returns the frames labelled by the FrameLabel tags of the main
+// timeline and by the DefineSceneAndFrameLabelData tags, frames are
+// numbered from 0
+func (s Swf) FrameLabels() map[string]int {
+	labels := s.Timeline().FrameLabels()
+	for _, t := range s.Tags {
+		if t, ok := t.(*TagDefineSceneAndFrameLabelData); ok {
+			for _, label := range t.FrameLabels {
+				if _, found := labels[label.Name]; !found {
+					labels[label.Name] = int(label.FrameNum)
+				}
+			}
+		}
+	}
+	return labels
+}
+
+func (p *parser) ParseTagFrameLabel(length uint32) (Tag, error) {
+	begin, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	t := &TagFrameLabel{tag: tag{code: CodeTagFrameLabel, length: length}}
+	if t.Name, err = p.r.ReadString(); err != nil {
+		return nil, p.fail(err, "FrameLabel.Name")
+	}
+
+	// The NamedAnchor flag is optional, it is there when bytes remain
+	end, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(end-begin) < length {
+		flag, err := p.r.ReadUInt8()
+		if err != nil {
+			return nil, p.fail(err, "FrameLabel.NamedAnchorFlag")
+		}
+		t.NamedAnchor = flag == 1
+	}
+	return t, nil
+}
+
+func (p *parser) ParseTagDefineSceneAndFrameLabelData(length uint32) (Tag, error) {
+	t := &TagDefineSceneAndFrameLabelData{tag: tag{code: CodeTagDefineSceneAndFrameLabelData, length: length}}
+	count, err := p.r.ReadEUInt32()
+	if err != nil {
+		return nil, p.fail(err, "DefineSceneAndFrameLabelData.SceneCount")
+	}
+	for i := uint32(0); i < count; i++ {
+		var scene Scene
+		if scene.Offset, err = p.r.ReadEUInt32(); err != nil {
+			return nil, p.fail(err, "DefineSceneAndFrameLabelData.Offset")
+		}
+		if scene.Name, err = p.r.ReadString(); err != nil {
+			return nil, p.fail(err, "DefineSceneAndFrameLabelData.Name")
+		}
+		t.Scenes = append(t.Scenes, scene)
+	}
+
+	if count, err = p.r.ReadEUInt32(); err != nil {
+		return nil, p.fail(err, "DefineSceneAndFrameLabelData.FrameLabelCount")
+	}
+	for i := uint32(0); i < count; i++ {
+		var label FrameLabel
+		if label.FrameNum, err = p.r.ReadEUInt32(); err != nil {
+			return nil, p.fail(err, "DefineSceneAndFrameLabelData.FrameNum")
+		}
+		if label.Name, err = p.r.ReadString(); err != nil {
+			return nil, p.fail(err, "DefineSceneAndFrameLabelData.FrameLabel")
+		}
+		t.FrameLabels = append(t.FrameLabels, label)
+	}
+	return t, nil
+}
+
+// SerializeTagFrameLabel serializes a FrameLabel tag, the NamedAnchor flag
+// is only written when it is set
+func (s *serializer) SerializeTagFrameLabel(t *TagFrameLabel) error {
+	if err := s.w.WriteString(t.Name); err != nil {
+		return err
+	}
+	if t.NamedAnchor {
+		return s.w.WriteUInt8(1)
+	}
+	return nil
+}
+
+func (s *serializer) SerializeTagDefineSceneAndFrameLabelData(t *TagDefineSceneAndFrameLabelData) error {
+	if err := s.w.WriteEUInt32(uint32(len(t.Scenes))); err != nil {
+		return err
+	}
+	for _, scene := range t.Scenes {
+		if err := s.w.WriteEUInt32(scene.Offset); err != nil {
+			return err
+		}
+		if err := s.w.WriteString(scene.Name); err != nil {
+			return err
+		}
+	}
+	if err := s.w.WriteEUInt32(uint32(len(t.FrameLabels))); err != nil {
+		return err
+	}
+	for _, label := range t.FrameLabels {
+		if err := s.w.WriteEUInt32(label.FrameNum); err != nil {
+			return err
+		}
+		if err := s.w.WriteString(label.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
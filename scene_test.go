@@ -0,0 +1,48 @@
+package swf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrameLabels(t *testing.T) {
+	tagsBytes := []byte{
+		0xc3, 0x0a, 0x61, 0x00, 0x01, // FrameLabel, NamedAnchor
+		0x40, 0x00, // ShowFrame
+		0xc2, 0x0a, 0x62, 0x00, // FrameLabel
+		0x40, 0x00, // ShowFrame
+		0x8c, 0x15, // DefineSceneAndFrameLabelData, length 12
+		0x01, 0x00, 0x53, 0x00, // Scenes
+		0x02, 0x01, 0x62, 0x00, 0xc8, 0x01, 0x63, 0x00, // FrameLabels
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	if len(tags) != 6 {
+		t.Fatalf("expected 6, got %v", len(tags))
+	}
+
+	expected := []Tag{
+		&TagFrameLabel{tag{code: CodeTagFrameLabel, length: 3}, "a", true},
+		&tag{code: CodeTagShowFrame, length: 0},
+		&TagFrameLabel{tag{code: CodeTagFrameLabel, length: 2}, "b", false},
+		&tag{code: CodeTagShowFrame, length: 0},
+		&TagDefineSceneAndFrameLabelData{
+			tag{code: CodeTagDefineSceneAndFrameLabelData, length: 12},
+			[]Scene{{0, "S"}},
+			[]FrameLabel{{1, "b"}, {200, "c"}},
+		},
+	}
+	for i, e := range expected {
+		if !reflect.DeepEqual(tags[i], e) {
+			t.Errorf("expected %#v, got %#v", e, tags[i])
+		}
+	}
+
+	s := Swf{Tags: tags}
+	if labels := map[string]int{"a": 0, "b": 1, "c": 200}; !reflect.DeepEqual(s.FrameLabels(), labels) {
+		t.Errorf("expected %v, got %v", labels, s.FrameLabels())
+	}
+	if scenes := map[string]int{"S": 0}; !reflect.DeepEqual(s.Scenes(), scenes) {
+		t.Errorf("expected %v, got %v", scenes, s.Scenes())
+	}
+}
@@ -0,0 +1,273 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// ErrUnsupportedTag means that the tag can not be serialized.
+// The library does not know how to encode its content
+var ErrUnsupportedTag = errors.New("unsupported tag")
+
+type serializer struct {
+	w       Writer
+	version uint8 // version is the version of the Swf file, some records depend on it
+}
+
+func newSerializer(w io.Writer) *serializer {
+	return &serializer{w: NewWriter(w)}
+}
+
+// Write serializes an entire Swf file.
+// Header.FileLength and the length of every tag are computed from the
+// serialized content, so they do not need to be up to date.
+// The file is compressed according to Header.Compression
+func Write(w io.Writer, s Swf) error {
+	var signature byte
+	switch s.Header.Compression {
+	default:
+		return ErrUnsupportedFile
+	case CompressionNone:
+		signature = 'F'
+	case CompressionZlib:
+		signature = 'C'
+	case CompressionLZMA:
+		signature = 'Z'
+	}
+
+	var body bytes.Buffer
+	ser := newSerializer(&body)
+	ser.version = s.Header.Version
+	if err := ser.SerializeHeader(s.Header); err != nil {
+		return err
+	}
+	if err := ser.SerializeTags(s.Tags); err != nil {
+		return err
+	}
+	if err := ser.w.Flush(); err != nil {
+		return err
+	}
+
+	header := []byte{signature, 'W', 'S', s.Header.Version, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(header[4:], uint32(body.Len()+len(header)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	return writeBody(w, s.Header.Compression, body.Bytes())
+}
+
+func writeBody(w io.Writer, compression uint8, body []byte) error {
+	switch compression {
+	default:
+		_, err := w.Write(body)
+		return err
+	case CompressionZlib:
+		zw := zlib.NewWriter(w)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		return zw.Close()
+	case CompressionLZMA:
+		var buf bytes.Buffer
+		lw, err := lzma.WriterConfig{
+			Size:      int64(len(body)),
+			EOSMarker: true,
+		}.NewWriter(&buf)
+		if err != nil {
+			return err
+		}
+		if _, err = lw.Write(body); err != nil {
+			return err
+		}
+		if err = lw.Close(); err != nil {
+			return err
+		}
+
+		// Replace the .lzma header by the one used in 'ZWS' files,
+		// see newLZMAReader
+		compressed := buf.Bytes()
+		var compressedLength [4]byte
+		binary.LittleEndian.PutUint32(compressedLength[:], uint32(len(compressed)-lzma.HeaderLen))
+		if _, err = w.Write(compressedLength[:]); err != nil {
+			return err
+		}
+		if _, err = w.Write(compressed[:5]); err != nil {
+			return err
+		}
+		_, err = w.Write(compressed[lzma.HeaderLen:])
+		return err
+	}
+}
+
+// SerializeHeader serializes the part of the header that follows FileLength
+func (s *serializer) SerializeHeader(h Header) error {
+	if err := s.w.WriteRect(h.FrameSize); err != nil {
+		return err
+	}
+	if err := s.w.WriteFixed8(h.FrameRate); err != nil {
+		return err
+	}
+	return s.w.WriteUInt16(h.FrameCount)
+}
+
+func (s *serializer) SerializeTags(tags []Tag) error {
+	for _, t := range tags {
+		if err := s.SerializeTag(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeTag serializes a tag and its header.
+// The short header form is used whenever the length allows it, unless the
+// tag was stored with a long header
+func (s *serializer) SerializeTag(t Tag) error {
+	var body bytes.Buffer
+	bodySer := newSerializer(&body)
+	bodySer.version = s.version
+
+	var err error
+	long := false
+	if h, ok := t.(longHeaderTag); ok {
+		long = h.longHeader()
+	}
+	switch t := t.(type) {
+	default:
+		err = ErrUnsupportedTag
+	case *tag:
+		// Tags without content, such as End
+	case *UnknownTag:
+		long = t.LongHeader
+		_, err = bodySer.w.Write(t.Data)
+	case *TagDoABC:
+		err = bodySer.SerializeTagDoABC(t)
+	case *TagFileAttributes:
+		err = bodySer.SerializeTagFileAttributes(t)
+	case *TagSetBackgroundColor:
+		err = bodySer.SerializeTagSetBackgroundColor(t)
+	case *TagMetadata:
+		err = bodySer.SerializeTagMetadata(t)
+	case *TagScriptLimits:
+		err = bodySer.SerializeTagScriptLimits(t)
+	case *TagSymbolClass:
+		err = bodySer.SerializeSymbols(t.Symbols)
+	case *TagExportAssets:
+		err = bodySer.SerializeSymbols(t.Symbols)
+	case *TagImportAssets:
+		err = bodySer.SerializeTagImportAssets(t)
+	case *TagDefineBinaryData:
+		err = bodySer.SerializeTagDefineBinaryData(t)
+	case *TagDefineBits:
+		err = bodySer.SerializeTagDefineBits(t)
+	case *TagJPEGTables:
+		_, err = bodySer.w.Write(t.JPEGData)
+	case *TagDefineBitsJPEG2:
+		err = bodySer.SerializeTagDefineBitsJPEG2(t)
+	case *TagDefineBitsJPEG3:
+		err = bodySer.SerializeTagDefineBitsJPEG3(t)
+	case *TagDefineBitsLossless:
+		err = bodySer.SerializeTagDefineBitsLossless(t)
+	case *TagDefineShape:
+		err = bodySer.SerializeTagDefineShape(t)
+	case *TagPlaceObject:
+		err = bodySer.SerializeTagPlaceObject(t)
+	case *TagRemoveObject:
+		err = bodySer.SerializeTagRemoveObject(t)
+	case *TagDoAction:
+		_, err = bodySer.w.Write(t.ActionData)
+	case *TagDoInitAction:
+		err = bodySer.SerializeTagDoInitAction(t)
+	case *TagDefineSound:
+		err = bodySer.SerializeTagDefineSound(t)
+	case *TagStartSound:
+		err = bodySer.SerializeTagStartSound(t)
+	case *TagSoundStreamHead:
+		err = bodySer.SerializeTagSoundStreamHead(t)
+	case *TagSoundStreamBlock:
+		_, err = bodySer.w.Write(t.StreamSoundData)
+	case *TagDefineSprite:
+		err = bodySer.SerializeTagDefineSprite(t)
+	case *TagFrameLabel:
+		err = bodySer.SerializeTagFrameLabel(t)
+	case *TagDefineSceneAndFrameLabelData:
+		err = bodySer.SerializeTagDefineSceneAndFrameLabelData(t)
+	}
+	if err != nil {
+		return err
+	}
+	if err = bodySer.w.Flush(); err != nil {
+		return err
+	}
+
+	if err = s.SerializeTagHeader(t.Code(), uint32(body.Len()), long); err != nil {
+		return err
+	}
+	_, err = s.w.Write(body.Bytes())
+	return err
+}
+
+// SerializeTagHeader serializes a RECORDHEADER.
+// The long form is used when forced or when length does not fit in 6 bits
+func (s *serializer) SerializeTagHeader(code uint16, length uint32, long bool) error {
+	if code > 0x3ff {
+		return ErrUnsupportedTag
+	}
+	if !long && length < 0x3f {
+		return s.w.WriteUInt16(code<<6 | uint16(length))
+	}
+	if err := s.w.WriteUInt16(code<<6 | 0x3f); err != nil {
+		return err
+	}
+	return s.w.WriteUInt32(length)
+}
+
+func (s *serializer) SerializeTagDoABC(t *TagDoABC) error {
+	if err := s.w.WriteUInt32(t.Flags); err != nil {
+		return err
+	}
+	if err := s.w.WriteString(t.Name); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.ABCData)
+	return err
+}
+
+// SerializeRGB serializes a RGB record
+func (s *serializer) SerializeRGB(color RGB) error {
+	_, err := s.w.Write([]byte{color.Red, color.Green, color.Blue})
+	return err
+}
+
+// SerializeRGBA serializes a RGBA record
+func (s *serializer) SerializeRGBA(color RGBA) error {
+	_, err := s.w.Write([]byte{color.Red, color.Green, color.Blue, color.Alpha})
+	return err
+}
+
+// writeUBits writes an unsigned bit value whose bit count may be 0
+func (s *serializer) writeUBits(v uint32, n uint8) error {
+	if n == 0 {
+		if v != 0 {
+			return errors.New("bit value overflows its bit count")
+		}
+		return nil
+	}
+	return s.w.WriteUBitValue(v, n)
+}
+
+// writeBits writes a signed bit value whose bit count may be 0
+func (s *serializer) writeBits(v int32, n uint8) error {
+	if n == 0 {
+		if v != 0 {
+			return errors.New("bit value overflows its bit count")
+		}
+		return nil
+	}
+	return s.w.WriteBitValue(v, n)
+}
@@ -0,0 +1,86 @@
+package swf
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	abcData := bytes.Repeat([]byte{0x10, 0x00, 0x2e, 0x00}, 100)
+	for _, compression := range []uint8{CompressionNone, CompressionZlib, CompressionLZMA} {
+		s := Swf{
+			Header: Header{
+				Compression: compression,
+				Version:     11,
+				FrameSize:   Rect{16, 0, 25600, 0, 20480},
+				FrameRate:   50.0,
+				FrameCount:  1,
+			},
+			Tags: []Tag{
+				&TagDoABC{tag{code: CodeTagDoABC, length: 0}, 1, "frame1", abcData},
+				&tag{code: CodeTagEnd, length: 0},
+			},
+		}
+
+		var buf bytes.Buffer
+		if err := Write(&buf, s); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		parsed, err := Parse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		// Header (21) + DoABC long header (6) + flags (4) + name (7) + data + End (2)
+		s.Header.FileLength = uint32(21 + 6 + 4 + 7 + len(abcData) + 2)
+		if !reflect.DeepEqual(parsed.Header, s.Header) {
+			t.Errorf("expected %v, got %v", s.Header, parsed.Header)
+		}
+		if len(parsed.Tags) != 2 {
+			t.Fatalf("expected 2 tags, got %v", len(parsed.Tags))
+		}
+		doAbc, ok := parsed.Tags[0].(*TagDoABC)
+		if !ok {
+			t.Fatalf("expected %v to be a *TagDoABC", parsed.Tags[0])
+		}
+		if doAbc.Length() != uint32(4+7+len(abcData)) {
+			t.Errorf("expected %v, got %v", 4+7+len(abcData), doAbc.Length())
+		}
+		if doAbc.Flags != 1 || doAbc.Name != "frame1" || !bytes.Equal(doAbc.ABCData, abcData) {
+			t.Errorf("expected %v, got %v", s.Tags[0], doAbc)
+		}
+	}
+
+	s := Swf{Header: Header{Compression: 42}}
+	if err := Write(&bytes.Buffer{}, s); err != ErrUnsupportedFile {
+		t.Errorf("expected ErrUnsupportedFile, got %v", err)
+	}
+}
+
+func TestSerializeTagHeader(t *testing.T) {
+	var buf bytes.Buffer
+	ser := newSerializer(&buf)
+	if err := ser.SerializeTagHeader(CodeTagDoABC, 0x3e, false); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := ser.SerializeTagHeader(CodeTagDoABC, 0x3f, false); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := ser.SerializeTagHeader(CodeTagEnd, 0, true); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	expected := []byte{
+		0xbe, 0x14,
+		0xbf, 0x14, 0x3f, 0x00, 0x00, 0x00,
+		0x3f, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, buf.Bytes())
+	}
+
+	if err := ser.SerializeTagHeader(0x400, 0, false); err != ErrUnsupportedTag {
+		t.Errorf("expected ErrUnsupportedTag, got %v", err)
+	}
+}
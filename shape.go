@@ -0,0 +1,791 @@
+package swf
+
+import (
+	"errors"
+)
+
+// These represent the types of a FillStyle
+const (
+	FillStyleSolid                      = 0x00 // FillStyleSolid is a solid color fill
+	FillStyleLinearGradient             = 0x10 // FillStyleLinearGradient is a linear gradient fill
+	FillStyleRadialGradient             = 0x12 // FillStyleRadialGradient is a radial gradient fill
+	FillStyleFocalRadialGradient        = 0x13 // FillStyleFocalRadialGradient is a focal radial gradient fill, only in DefineShape4
+	FillStyleRepeatingBitmap            = 0x40 // FillStyleRepeatingBitmap is a repeating bitmap fill
+	FillStyleClippedBitmap              = 0x41 // FillStyleClippedBitmap is a clipped bitmap fill
+	FillStyleNonSmoothedRepeatingBitmap = 0x42 // FillStyleNonSmoothedRepeatingBitmap is a non-smoothed repeating bitmap fill
+	FillStyleNonSmoothedClippedBitmap   = 0x43 // FillStyleNonSmoothedClippedBitmap is a non-smoothed clipped bitmap fill
+)
+
+// These represent the cap styles of a LineStyle2
+const (
+	CapStyleRound  = 0 // CapStyleRound is a round cap
+	CapStyleNone   = 1 // CapStyleNone is no cap
+	CapStyleSquare = 2 // CapStyleSquare is a square cap
+)
+
+// These represent the join styles of a LineStyle2
+const (
+	JoinStyleRound = 0 // JoinStyleRound is a round join
+	JoinStyleBevel = 1 // JoinStyleBevel is a bevel join
+	JoinStyleMiter = 2 // JoinStyleMiter is a miter join, limited by MiterLimitFactor
+)
+
+// ErrFillStyleType means that a FillStyle can not be decoded.
+// Its FillStyleType is unknown
+var ErrFillStyleType = errors.New("unknown fill style type")
+
+// shapeVersion returns the version of a DefineShape tag, from 1 to 4.
+// It tells which variant of the style records is used
+func shapeVersion(code uint16) int {
+	switch code {
+	case CodeTagDefineShape2:
+		return 2
+	case CodeTagDefineShape3:
+		return 3
+	case CodeTagDefineShape4:
+		return 4
+	}
+	return 1
+}
+
+// isGradient returns true when a fill style type is a gradient
+func isGradient(fillStyleType uint8) bool {
+	return fillStyleType == FillStyleLinearGradient ||
+		fillStyleType == FillStyleRadialGradient ||
+		fillStyleType == FillStyleFocalRadialGradient
+}
+
+// isBitmap returns true when a fill style type is a bitmap
+func isBitmap(fillStyleType uint8) bool {
+	return fillStyleType >= FillStyleRepeatingBitmap && fillStyleType <= FillStyleNonSmoothedClippedBitmap
+}
+
+func (p *parser) ParseTagDefineShape(length uint32) (Tag, error) {
+	return p.parseTagDefineShape(CodeTagDefineShape, length)
+}
+
+func (p *parser) ParseTagDefineShape2(length uint32) (Tag, error) {
+	return p.parseTagDefineShape(CodeTagDefineShape2, length)
+}
+
+func (p *parser) ParseTagDefineShape3(length uint32) (Tag, error) {
+	return p.parseTagDefineShape(CodeTagDefineShape3, length)
+}
+
+// ParseTagDefineShape4 parses a DefineShape4 tag, which adds the edge
+// bounds and the stroke flags to DefineShape3
+func (p *parser) ParseTagDefineShape4(length uint32) (Tag, error) {
+	return p.parseTagDefineShape(CodeTagDefineShape4, length)
+}
+
+func (p *parser) parseTagDefineShape(code uint16, length uint32) (Tag, error) {
+	t := &TagDefineShape{tag: tag{code: code, length: length}}
+	var err error
+	if t.ShapeID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "DefineShape.ShapeID")
+	}
+	if t.ShapeBounds, err = p.ParseRect(); err != nil {
+		return nil, err
+	}
+	version := shapeVersion(code)
+	if version == 4 {
+		if t.EdgeBounds, err = p.ParseRect(); err != nil {
+			return nil, err
+		}
+		flags, err := p.r.ReadUInt8()
+		if err != nil {
+			return nil, p.fail(err, "DefineShape4.Flags")
+		}
+		t.UsesFillWindingRule = flags&0x04 != 0
+		t.UsesNonScalingStrokes = flags&0x02 != 0
+		t.UsesScalingStrokes = flags&0x01 != 0
+	}
+	if t.Shapes, err = p.ParseShapeWithStyle(version); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ParseShapeWithStyle parses a ShapeWithStyle record of a DefineShape tag
+// of the given version
+func (p *parser) ParseShapeWithStyle(version int) (shape ShapeWithStyle, err error) {
+	if shape.FillStyles, err = p.ParseFillStyleArray(version); err != nil {
+		return shape, err
+	}
+	if shape.LineStyles, err = p.ParseLineStyleArray(version); err != nil {
+		return shape, err
+	}
+	if shape.NumFillBits, shape.NumLineBits, err = p.parseNumBits("SHAPEWITHSTYLE"); err != nil {
+		return shape, err
+	}
+	shape.ShapeRecords, err = p.ParseShapeRecords(version, shape.NumFillBits, shape.NumLineBits)
+	return shape, err
+}
+
+// parseNumBits parses the NumFillBits and NumLineBits fields of a record
+func (p *parser) parseNumBits(record string) (fillBits, lineBits uint8, err error) {
+	v, err := p.r.ReadUBitValue(4)
+	if err != nil {
+		return 0, 0, p.fail(err, record+".NumFillBits")
+	}
+	fillBits = uint8(v)
+	if v, err = p.r.ReadUBitValue(4); err != nil {
+		return 0, 0, p.fail(err, record+".NumLineBits")
+	}
+	return fillBits, uint8(v), nil
+}
+
+// parseStyleCount parses the count of a FillStyleArray or a LineStyleArray.
+// Its extended form is used since DefineShape2
+func (p *parser) parseStyleCount(version int, record string) (int, error) {
+	count, err := p.r.ReadUInt8()
+	if err != nil {
+		return 0, p.fail(err, record+"Count")
+	}
+	if count != 0xff || version < 2 {
+		return int(count), nil
+	}
+	extended, err := p.r.ReadUInt16()
+	if err != nil {
+		return 0, p.fail(err, record+"CountExtended")
+	}
+	return int(extended), nil
+}
+
+// parseColor parses a RGBA record since DefineShape3, a RGB record before
+func (p *parser) parseColor(version int) (RGBA, error) {
+	if version >= 3 {
+		return p.ParseRGBA()
+	}
+	color, err := p.ParseRGB()
+	return RGBA{color.Red, color.Green, color.Blue, 0xff}, err
+}
+
+// ParseFillStyleArray parses a FillStyleArray record
+func (p *parser) ParseFillStyleArray(version int) ([]FillStyle, error) {
+	count, err := p.parseStyleCount(version, "FILLSTYLEARRAY.FillStyle")
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	styles := make([]FillStyle, count)
+	for i := range styles {
+		if styles[i], err = p.ParseFillStyle(version); err != nil {
+			return nil, err
+		}
+	}
+	return styles, nil
+}
+
+// ParseFillStyle parses a FillStyle record
+func (p *parser) ParseFillStyle(version int) (style FillStyle, err error) {
+	if style.FillStyleType, err = p.r.ReadUInt8(); err != nil {
+		return style, p.fail(err, "FILLSTYLE.FillStyleType")
+	}
+	switch {
+	default:
+		return style, p.fail(ErrFillStyleType, "FILLSTYLE.FillStyleType")
+	case style.FillStyleType == FillStyleSolid:
+		style.Color, err = p.parseColor(version)
+	case isGradient(style.FillStyleType):
+		if style.GradientMatrix, err = p.ParseMatrix(); err != nil {
+			return style, err
+		}
+		style.Gradient, err = p.ParseGradient(version, style.FillStyleType == FillStyleFocalRadialGradient)
+	case isBitmap(style.FillStyleType):
+		if style.BitmapID, err = p.r.ReadUInt16(); err != nil {
+			return style, p.fail(err, "FILLSTYLE.BitmapId")
+		}
+		style.BitmapMatrix, err = p.ParseMatrix()
+	}
+	return style, err
+}
+
+// ParseGradient parses a Gradient record, or a FocalGradient record
+func (p *parser) ParseGradient(version int, focal bool) (g Gradient, err error) {
+	// The flags are read as a byte, the record is byte aligned
+	flags, err := p.r.ReadUInt8()
+	if err != nil {
+		return g, p.fail(err, "GRADIENT.Flags")
+	}
+	g.SpreadMode, g.InterpolationMode = flags>>6, flags>>4&0x3
+	numGradients := flags & 0xf
+
+	g.GradientRecords = make([]GradRecord, numGradients)
+	for i := range g.GradientRecords {
+		if g.GradientRecords[i].Ratio, err = p.r.ReadUInt8(); err != nil {
+			return g, p.fail(err, "GRADRECORD.Ratio")
+		}
+		if g.GradientRecords[i].Color, err = p.parseColor(version); err != nil {
+			return g, err
+		}
+	}
+	if focal {
+		if g.FocalPoint, err = p.r.ReadFixed8(); err != nil {
+			return g, p.fail(err, "FOCALGRADIENT.FocalPoint")
+		}
+	}
+	return g, nil
+}
+
+// ParseLineStyleArray parses a LineStyleArray record
+func (p *parser) ParseLineStyleArray(version int) ([]LineStyle, error) {
+	count, err := p.parseStyleCount(version, "LINESTYLEARRAY.LineStyle")
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	styles := make([]LineStyle, count)
+	for i := range styles {
+		if styles[i], err = p.ParseLineStyle(version); err != nil {
+			return nil, err
+		}
+	}
+	return styles, nil
+}
+
+// ParseLineStyle parses a LineStyle record, or a LineStyle2 record in
+// DefineShape4
+func (p *parser) ParseLineStyle(version int) (style LineStyle, err error) {
+	if style.Width, err = p.r.ReadUInt16(); err != nil {
+		return style, p.fail(err, "LINESTYLE.Width")
+	}
+	if version < 4 {
+		style.Color, err = p.parseColor(version)
+		return style, err
+	}
+
+	flags, err := p.r.ReadUBitValue(16)
+	if err != nil {
+		return style, p.fail(err, "LINESTYLE2.Flags")
+	}
+	style.StartCapStyle = uint8(flags >> 14 & 0x3)
+	style.JoinStyle = uint8(flags >> 12 & 0x3)
+	style.HasFill = flags&0x0800 != 0
+	style.NoHScale = flags&0x0400 != 0
+	style.NoVScale = flags&0x0200 != 0
+	style.PixelHinting = flags&0x0100 != 0
+	style.NoClose = flags&0x0004 != 0
+	style.EndCapStyle = uint8(flags & 0x3)
+	if style.JoinStyle == JoinStyleMiter {
+		if style.MiterLimitFactor, err = p.r.ReadFixed8(); err != nil {
+			return style, p.fail(err, "LINESTYLE2.MiterLimitFactor")
+		}
+	}
+	if style.HasFill {
+		style.FillType, err = p.ParseFillStyle(version)
+	} else {
+		style.Color, err = p.ParseRGBA()
+	}
+	return style, err
+}
+
+// ParseShapeRecords parses the ShapeRecords of a shape up to the
+// EndShapeRecord. The bit counts of the style indexes change with the
+// StyleChangeRecords defining new styles
+func (p *parser) ParseShapeRecords(version int, fillBits, lineBits uint8) ([]ShapeRecord, error) {
+	var records []ShapeRecord
+	for {
+		typeFlag, err := p.r.ReadUBitValue(1)
+		if err != nil {
+			return nil, p.fail(err, "SHAPERECORD.TypeFlag")
+		}
+		if typeFlag == 1 {
+			record, err := p.parseEdgeRecord()
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+			continue
+		}
+
+		flags, err := p.r.ReadUBitValue(5)
+		if err != nil {
+			return nil, p.fail(err, "STYLECHANGERECORD.Flags")
+		}
+		if flags == 0 {
+			// EndShapeRecord
+			return records, nil
+		}
+		record, err := p.parseStyleChangeRecord(version, flags, fillBits, lineBits)
+		if err != nil {
+			return nil, err
+		}
+		if record.StateNewStyles {
+			fillBits, lineBits = record.NumFillBits, record.NumLineBits
+		}
+		records = append(records, record)
+	}
+}
+
+func (p *parser) parseStyleChangeRecord(version int, flags uint32, fillBits, lineBits uint8) (*StyleChangeRecord, error) {
+	r := &StyleChangeRecord{
+		StateNewStyles:  flags&0x10 != 0,
+		StateLineStyle:  flags&0x08 != 0,
+		StateFillStyle1: flags&0x04 != 0,
+		StateFillStyle0: flags&0x02 != 0,
+		StateMoveTo:     flags&0x01 != 0,
+	}
+	var err error
+	if r.StateMoveTo {
+		moveBits, err := p.r.ReadUBitValue(5)
+		if err != nil {
+			return nil, p.fail(err, "STYLECHANGERECORD.MoveBits")
+		}
+		r.MoveBits = uint8(moveBits)
+		if r.MoveDeltaX, err = p.readBits(r.MoveBits, "STYLECHANGERECORD.MoveDeltaX"); err != nil {
+			return nil, err
+		}
+		if r.MoveDeltaY, err = p.readBits(r.MoveBits, "STYLECHANGERECORD.MoveDeltaY"); err != nil {
+			return nil, err
+		}
+	}
+	if r.StateFillStyle0 {
+		if r.FillStyle0, err = p.readUBits(fillBits, "STYLECHANGERECORD.FillStyle0"); err != nil {
+			return nil, err
+		}
+	}
+	if r.StateFillStyle1 {
+		if r.FillStyle1, err = p.readUBits(fillBits, "STYLECHANGERECORD.FillStyle1"); err != nil {
+			return nil, err
+		}
+	}
+	if r.StateLineStyle {
+		if r.LineStyle, err = p.readUBits(lineBits, "STYLECHANGERECORD.LineStyle"); err != nil {
+			return nil, err
+		}
+	}
+	if r.StateNewStyles {
+		if r.FillStyles, err = p.ParseFillStyleArray(version); err != nil {
+			return nil, err
+		}
+		if r.LineStyles, err = p.ParseLineStyleArray(version); err != nil {
+			return nil, err
+		}
+		if r.NumFillBits, r.NumLineBits, err = p.parseNumBits("STYLECHANGERECORD"); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (p *parser) parseEdgeRecord() (ShapeRecord, error) {
+	straightFlag, err := p.r.ReadUBitValue(1)
+	if err != nil {
+		return nil, p.fail(err, "EDGERECORD.StraightFlag")
+	}
+	numBits, err := p.r.ReadUBitValue(4)
+	if err != nil {
+		return nil, p.fail(err, "EDGERECORD.NumBits")
+	}
+	n := uint8(numBits) + 2
+
+	if straightFlag == 0 {
+		r := &CurvedEdgeRecord{NumBits: uint8(numBits)}
+		fields := []struct {
+			ptr    *int32
+			record string
+		}{
+			{&r.ControlDeltaX, "CURVEDEDGERECORD.ControlDeltaX"},
+			{&r.ControlDeltaY, "CURVEDEDGERECORD.ControlDeltaY"},
+			{&r.AnchorDeltaX, "CURVEDEDGERECORD.AnchorDeltaX"},
+			{&r.AnchorDeltaY, "CURVEDEDGERECORD.AnchorDeltaY"},
+		}
+		for _, field := range fields {
+			if *field.ptr, err = p.r.ReadBitValue(n); err != nil {
+				return nil, p.fail(err, field.record)
+			}
+		}
+		return r, nil
+	}
+
+	r := &StraightEdgeRecord{NumBits: uint8(numBits)}
+	generalLine, err := p.r.ReadUBitValue(1)
+	if err != nil {
+		return nil, p.fail(err, "STRAIGHTEDGERECORD.GeneralLineFlag")
+	}
+	vertLine := uint32(0)
+	if generalLine == 0 {
+		if vertLine, err = p.r.ReadUBitValue(1); err != nil {
+			return nil, p.fail(err, "STRAIGHTEDGERECORD.VertLineFlag")
+		}
+	}
+	if generalLine == 1 || vertLine == 0 {
+		if r.DeltaX, err = p.r.ReadBitValue(n); err != nil {
+			return nil, p.fail(err, "STRAIGHTEDGERECORD.DeltaX")
+		}
+	}
+	if generalLine == 1 || vertLine == 1 {
+		if r.DeltaY, err = p.r.ReadBitValue(n); err != nil {
+			return nil, p.fail(err, "STRAIGHTEDGERECORD.DeltaY")
+		}
+	}
+	return r, nil
+}
+
+// SerializeTagDefineShape serializes a DefineShape tag of any version,
+// according to its code
+func (s *serializer) SerializeTagDefineShape(t *TagDefineShape) error {
+	if err := s.w.WriteUInt16(t.ShapeID); err != nil {
+		return err
+	}
+	if err := s.w.WriteRect(t.ShapeBounds); err != nil {
+		return err
+	}
+	version := shapeVersion(t.Code())
+	if version == 4 {
+		if err := s.w.WriteRect(t.EdgeBounds); err != nil {
+			return err
+		}
+		var flags uint8
+		if t.UsesFillWindingRule {
+			flags |= 0x04
+		}
+		if t.UsesNonScalingStrokes {
+			flags |= 0x02
+		}
+		if t.UsesScalingStrokes {
+			flags |= 0x01
+		}
+		if err := s.w.WriteUInt8(flags); err != nil {
+			return err
+		}
+	}
+	return s.SerializeShapeWithStyle(t.Shapes, version)
+}
+
+// SerializeShapeWithStyle serializes a ShapeWithStyle record.
+// NumFillBits and NumLineBits are increased when they are too small to hold
+// the style indexes
+func (s *serializer) SerializeShapeWithStyle(shape ShapeWithStyle, version int) error {
+	if err := s.SerializeFillStyleArray(shape.FillStyles, version); err != nil {
+		return err
+	}
+	if err := s.SerializeLineStyleArray(shape.LineStyles, version); err != nil {
+		return err
+	}
+	fillBits, lineBits := styleBits(shape.NumFillBits, shape.NumLineBits, len(shape.FillStyles), len(shape.LineStyles))
+	if err := s.serializeNumBits(fillBits, lineBits); err != nil {
+		return err
+	}
+	return s.SerializeShapeRecords(shape.ShapeRecords, version, fillBits, lineBits)
+}
+
+// styleBits increases the bit counts of the style indexes when they are too
+// small to hold the number of styles
+func styleBits(fillBits, lineBits uint8, fillCount, lineCount int) (uint8, uint8) {
+	if needed := unsignedBits(uint32(fillCount)); needed > fillBits {
+		fillBits = needed
+	}
+	if needed := unsignedBits(uint32(lineCount)); needed > lineBits {
+		lineBits = needed
+	}
+	return fillBits, lineBits
+}
+
+func (s *serializer) serializeNumBits(fillBits, lineBits uint8) error {
+	if err := s.w.WriteUBitValue(uint32(fillBits), 4); err != nil {
+		return err
+	}
+	return s.w.WriteUBitValue(uint32(lineBits), 4)
+}
+
+// serializeStyleCount serializes the count of a FillStyleArray or a
+// LineStyleArray
+func (s *serializer) serializeStyleCount(count int, version int) error {
+	if count < 0xff || version < 2 {
+		return s.w.WriteUInt8(uint8(count))
+	}
+	if err := s.w.WriteUInt8(0xff); err != nil {
+		return err
+	}
+	return s.w.WriteUInt16(uint16(count))
+}
+
+// serializeColor serializes a RGBA record since DefineShape3, a RGB record
+// before
+func (s *serializer) serializeColor(color RGBA, version int) error {
+	if version >= 3 {
+		return s.SerializeRGBA(color)
+	}
+	return s.SerializeRGB(RGB{color.Red, color.Green, color.Blue})
+}
+
+// SerializeFillStyleArray serializes a FillStyleArray record
+func (s *serializer) SerializeFillStyleArray(styles []FillStyle, version int) error {
+	if err := s.serializeStyleCount(len(styles), version); err != nil {
+		return err
+	}
+	for _, style := range styles {
+		if err := s.SerializeFillStyle(style, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeFillStyle serializes a FillStyle record
+func (s *serializer) SerializeFillStyle(style FillStyle, version int) error {
+	if err := s.w.WriteUInt8(style.FillStyleType); err != nil {
+		return err
+	}
+	switch {
+	default:
+		return ErrFillStyleType
+	case style.FillStyleType == FillStyleSolid:
+		return s.serializeColor(style.Color, version)
+	case isGradient(style.FillStyleType):
+		if err := s.SerializeMatrix(style.GradientMatrix); err != nil {
+			return err
+		}
+		return s.SerializeGradient(style.Gradient, version, style.FillStyleType == FillStyleFocalRadialGradient)
+	case isBitmap(style.FillStyleType):
+		if err := s.w.WriteUInt16(style.BitmapID); err != nil {
+			return err
+		}
+		return s.SerializeMatrix(style.BitmapMatrix)
+	}
+}
+
+// SerializeGradient serializes a Gradient record, or a FocalGradient record
+func (s *serializer) SerializeGradient(g Gradient, version int, focal bool) error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if err := s.w.WriteUBitValue(uint32(g.SpreadMode), 2); err != nil {
+		return err
+	}
+	if err := s.w.WriteUBitValue(uint32(g.InterpolationMode), 2); err != nil {
+		return err
+	}
+	if err := s.w.WriteUBitValue(uint32(len(g.GradientRecords)), 4); err != nil {
+		return err
+	}
+	for _, record := range g.GradientRecords {
+		if err := s.w.WriteUInt8(record.Ratio); err != nil {
+			return err
+		}
+		if err := s.serializeColor(record.Color, version); err != nil {
+			return err
+		}
+	}
+	if focal {
+		return s.w.WriteFixed8(g.FocalPoint)
+	}
+	return nil
+}
+
+// SerializeLineStyleArray serializes a LineStyleArray record
+func (s *serializer) SerializeLineStyleArray(styles []LineStyle, version int) error {
+	if err := s.serializeStyleCount(len(styles), version); err != nil {
+		return err
+	}
+	for _, style := range styles {
+		if err := s.SerializeLineStyle(style, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeLineStyle serializes a LineStyle record, or a LineStyle2 record
+// in DefineShape4
+func (s *serializer) SerializeLineStyle(style LineStyle, version int) error {
+	if err := s.w.WriteUInt16(style.Width); err != nil {
+		return err
+	}
+	if version < 4 {
+		return s.serializeColor(style.Color, version)
+	}
+
+	flags := uint32(style.StartCapStyle&0x3)<<14 | uint32(style.JoinStyle&0x3)<<12 | uint32(style.EndCapStyle&0x3)
+	for _, flag := range []struct {
+		set bool
+		bit uint32
+	}{
+		{style.HasFill, 0x0800},
+		{style.NoHScale, 0x0400},
+		{style.NoVScale, 0x0200},
+		{style.PixelHinting, 0x0100},
+		{style.NoClose, 0x0004},
+	} {
+		if flag.set {
+			flags |= flag.bit
+		}
+	}
+	if err := s.w.WriteUBitValue(flags, 16); err != nil {
+		return err
+	}
+	if style.JoinStyle == JoinStyleMiter {
+		if err := s.w.WriteFixed8(style.MiterLimitFactor); err != nil {
+			return err
+		}
+	}
+	if style.HasFill {
+		return s.SerializeFillStyle(style.FillType, version)
+	}
+	return s.SerializeRGBA(style.Color)
+}
+
+// SerializeShapeRecords serializes the ShapeRecords of a shape followed by
+// an EndShapeRecord. The bit counts of the style indexes change with the
+// StyleChangeRecords defining new styles
+func (s *serializer) SerializeShapeRecords(records []ShapeRecord, version int, fillBits, lineBits uint8) error {
+	for _, record := range records {
+		var err error
+		switch r := record.(type) {
+		default:
+			err = errors.New("unknown shape record")
+		case *StyleChangeRecord:
+			fillBits, lineBits, err = s.serializeStyleChangeRecord(r, version, fillBits, lineBits)
+		case *StraightEdgeRecord:
+			err = s.serializeStraightEdgeRecord(r)
+		case *CurvedEdgeRecord:
+			err = s.serializeCurvedEdgeRecord(r)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// EndShapeRecord
+	if err := s.w.WriteUBitValue(0, 6); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+func (s *serializer) serializeStyleChangeRecord(r *StyleChangeRecord, version int, fillBits, lineBits uint8) (uint8, uint8, error) {
+	var flags uint32
+	for _, flag := range []struct {
+		set bool
+		bit uint32
+	}{
+		{r.StateNewStyles, 0x10},
+		{r.StateLineStyle, 0x08},
+		{r.StateFillStyle1, 0x04},
+		{r.StateFillStyle0, 0x02},
+		{r.StateMoveTo, 0x01},
+	} {
+		if flag.set {
+			flags |= flag.bit
+		}
+	}
+	if flags == 0 {
+		// It would be read as an EndShapeRecord
+		return 0, 0, errors.New("style change record without state")
+	}
+	if err := s.w.WriteUBitValue(flags, 6); err != nil {
+		return 0, 0, err
+	}
+
+	if r.StateMoveTo {
+		moveBits := r.MoveBits
+		if r.MoveDeltaX != 0 || r.MoveDeltaY != 0 {
+			if needed := signedBits(r.MoveDeltaX, r.MoveDeltaY); needed > moveBits {
+				moveBits = needed
+			}
+		}
+		if err := s.w.WriteUBitValue(uint32(moveBits), 5); err != nil {
+			return 0, 0, err
+		}
+		if err := s.writeBits(r.MoveDeltaX, moveBits); err != nil {
+			return 0, 0, err
+		}
+		if err := s.writeBits(r.MoveDeltaY, moveBits); err != nil {
+			return 0, 0, err
+		}
+	}
+	for _, index := range []struct {
+		set   bool
+		value uint32
+		n     uint8
+	}{
+		{r.StateFillStyle0, r.FillStyle0, fillBits},
+		{r.StateFillStyle1, r.FillStyle1, fillBits},
+		{r.StateLineStyle, r.LineStyle, lineBits},
+	} {
+		if !index.set {
+			continue
+		}
+		if err := s.writeUBits(index.value, index.n); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if !r.StateNewStyles {
+		return fillBits, lineBits, nil
+	}
+	if err := s.SerializeFillStyleArray(r.FillStyles, version); err != nil {
+		return 0, 0, err
+	}
+	if err := s.SerializeLineStyleArray(r.LineStyles, version); err != nil {
+		return 0, 0, err
+	}
+	fillBits, lineBits = styleBits(r.NumFillBits, r.NumLineBits, len(r.FillStyles), len(r.LineStyles))
+	return fillBits, lineBits, s.serializeNumBits(fillBits, lineBits)
+}
+
+// edgeBits returns the bit count of the deltas of an edge, written as 2
+// less than the number of bits
+func edgeBits(numBits uint8, deltas ...int32) uint8 {
+	n := numBits + 2
+	if needed := signedBits(deltas...); needed > n {
+		n = needed
+	}
+	return n
+}
+
+func (s *serializer) serializeStraightEdgeRecord(r *StraightEdgeRecord) error {
+	n := edgeBits(r.NumBits, r.DeltaX, r.DeltaY)
+	// TypeFlag and StraightFlag
+	if err := s.w.WriteUBitValue(0x3, 2); err != nil {
+		return err
+	}
+	if err := s.w.WriteUBitValue(uint32(n-2), 4); err != nil {
+		return err
+	}
+
+	var deltas []int32
+	switch {
+	case r.DeltaX != 0 && r.DeltaY != 0:
+		// General line
+		if err := s.w.WriteUBitValue(1, 1); err != nil {
+			return err
+		}
+		deltas = []int32{r.DeltaX, r.DeltaY}
+	case r.DeltaX == 0:
+		// Vertical line
+		if err := s.w.WriteUBitValue(0x1, 2); err != nil {
+			return err
+		}
+		deltas = []int32{r.DeltaY}
+	default:
+		// Horizontal line
+		if err := s.w.WriteUBitValue(0x0, 2); err != nil {
+			return err
+		}
+		deltas = []int32{r.DeltaX}
+	}
+	for _, delta := range deltas {
+		if err := s.w.WriteBitValue(delta, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *serializer) serializeCurvedEdgeRecord(r *CurvedEdgeRecord) error {
+	deltas := []int32{r.ControlDeltaX, r.ControlDeltaY, r.AnchorDeltaX, r.AnchorDeltaY}
+	n := edgeBits(r.NumBits, deltas...)
+	// TypeFlag and StraightFlag
+	if err := s.w.WriteUBitValue(0x2, 2); err != nil {
+		return err
+	}
+	if err := s.w.WriteUBitValue(uint32(n-2), 4); err != nil {
+		return err
+	}
+	for _, delta := range deltas {
+		if err := s.w.WriteBitValue(delta, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDefineShape(t *testing.T) {
+	tagsBytes := []byte{
+		0x9a, 0x00, // DefineShape, length 26
+		0x01, 0x00, // ShapeID
+		0x08, 0x00, // ShapeBounds
+		0x01, 0x00, 0xff, 0x00, 0x00, // FillStyles
+		0x01, 0x14, 0x00, 0x00, 0x00, 0xff, // LineStyles
+		0x11, // NumFillBits, NumLineBits
+		// ShapeRecords
+		0x2c, 0xaa, 0x57, 0x98, 0xac, 0xdb, 0x45, 0x6b, 0x76, 0x00,
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	if len(tags) != 2 {
+		t.Fatalf("expected 2, got %v", len(tags))
+	}
+
+	expected := &TagDefineShape{
+		tag:         tag{code: CodeTagDefineShape, length: 26},
+		ShapeID:     1,
+		ShapeBounds: Rect{NBits: 1},
+		Shapes: ShapeWithStyle{
+			FillStyles:  []FillStyle{{FillStyleType: FillStyleSolid, Color: RGBA{0xff, 0, 0, 0xff}}},
+			LineStyles:  []LineStyle{{Width: 20, Color: RGBA{0, 0, 0xff, 0xff}}},
+			NumFillBits: 1,
+			NumLineBits: 1,
+			ShapeRecords: []ShapeRecord{
+				&StyleChangeRecord{
+					StateLineStyle: true, StateFillStyle0: true, StateMoveTo: true,
+					MoveBits: 5, MoveDeltaX: 10, MoveDeltaY: 10,
+					FillStyle0: 1, LineStyle: 1,
+				},
+				&StraightEdgeRecord{3, 10, 0},
+				&StraightEdgeRecord{3, 0, -10},
+				&CurvedEdgeRecord{2, -5, 5, -5, -5},
+			},
+		},
+	}
+	if !reflect.DeepEqual(tags[0], expected) {
+		t.Errorf("expected %#v, got %#v", expected, tags[0])
+	}
+}
+
+func TestDefineShapeRoundTrip(t *testing.T) {
+	gradient := Matrix{
+		HasScale: true, NScaleBits: 18, ScaleX: 1.5, ScaleY: 1.5,
+		HasRotate: true, NRotateBits: 16, RotateSkew0: 0.25, RotateSkew1: -0.25,
+		NTranslateBits: 7, TranslateX: 20, TranslateY: -40,
+	}
+	records := []GradRecord{{0, RGBA{0xff, 0, 0, 0x80}}, {0xff, RGBA{0, 0, 0xff, 0xff}}}
+	manyFills := make([]FillStyle, 300)
+	for i := range manyFills {
+		manyFills[i] = FillStyle{FillStyleType: FillStyleSolid, Color: RGBA{uint8(i), 0, 0, 0xff}}
+	}
+
+	shapes := []*TagDefineShape{
+		{
+			tag:         tag{code: CodeTagDefineShape2, length: 0},
+			ShapeID:     2,
+			ShapeBounds: Rect{NBits: 11, Xmax: 400, Ymax: 400},
+			Shapes: ShapeWithStyle{
+				FillStyles:  manyFills,
+				NumFillBits: 9,
+				ShapeRecords: []ShapeRecord{
+					&StyleChangeRecord{StateFillStyle1: true, FillStyle1: 300},
+					&StraightEdgeRecord{0, 1, 1},
+				},
+			},
+		},
+		{
+			tag:         tag{code: CodeTagDefineShape3, length: 0},
+			ShapeID:     3,
+			ShapeBounds: Rect{NBits: 11, Xmax: 400, Ymax: 400},
+			Shapes: ShapeWithStyle{
+				FillStyles: []FillStyle{
+					{FillStyleType: FillStyleLinearGradient, GradientMatrix: gradient, Gradient: Gradient{GradientRecords: records}},
+					{FillStyleType: FillStyleClippedBitmap, BitmapID: 7, BitmapMatrix: Matrix{NTranslateBits: 1}},
+				},
+				LineStyles:  []LineStyle{{Width: 20, Color: RGBA{0, 0, 0xff, 0x80}}},
+				NumFillBits: 2,
+				NumLineBits: 1,
+				ShapeRecords: []ShapeRecord{
+					&StyleChangeRecord{StateFillStyle0: true, StateFillStyle1: true, FillStyle0: 1, FillStyle1: 2},
+					&CurvedEdgeRecord{6, 100, 0, 0, -100},
+				},
+			},
+		},
+		{
+			tag:                   tag{code: CodeTagDefineShape4, length: 0},
+			ShapeID:               4,
+			ShapeBounds:           Rect{NBits: 11, Xmin: -10, Xmax: 410, Ymin: -10, Ymax: 410},
+			EdgeBounds:            Rect{NBits: 11, Xmax: 400, Ymax: 400},
+			UsesNonScalingStrokes: true,
+			UsesFillWindingRule:   true,
+			Shapes: ShapeWithStyle{
+				LineStyles: []LineStyle{
+					{Width: 20, StartCapStyle: CapStyleSquare, JoinStyle: JoinStyleMiter, MiterLimitFactor: 3, NoHScale: true, EndCapStyle: CapStyleNone, Color: RGBA{1, 2, 3, 4}},
+					{Width: 40, JoinStyle: JoinStyleBevel, HasFill: true, NoClose: true, PixelHinting: true, FillType: FillStyle{
+						FillStyleType:  FillStyleFocalRadialGradient,
+						GradientMatrix: gradient,
+						Gradient:       Gradient{SpreadMode: 1, InterpolationMode: 1, GradientRecords: records, FocalPoint: 0.5},
+					}},
+				},
+				NumLineBits: 2,
+				ShapeRecords: []ShapeRecord{
+					&StyleChangeRecord{StateLineStyle: true, StateMoveTo: true, MoveBits: 6, MoveDeltaX: -10, LineStyle: 2},
+					&StraightEdgeRecord{6, 0, 100},
+					&StyleChangeRecord{
+						StateNewStyles: true,
+						FillStyles:     []FillStyle{{FillStyleType: FillStyleSolid, Color: RGBA{1, 2, 3, 4}}},
+						NumFillBits:    1,
+					},
+					&StyleChangeRecord{StateFillStyle0: true, StateLineStyle: true, FillStyle0: 1, LineStyle: 0},
+					&StraightEdgeRecord{6, -100, 0},
+				},
+			},
+		},
+	}
+
+	for _, shape := range shapes {
+		var buf bytes.Buffer
+		if err := newSerializer(&buf).SerializeTag(shape); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		tags := roundTripTags(t, append(buf.Bytes(), 0x00, 0x00))
+		shape.tag.length, shape.tag.long = tags[0].Length(), tags[0].Length() >= 0x3f
+		if !reflect.DeepEqual(tags[0], shape) {
+			t.Errorf("expected %#v, got %#v", shape, tags[0])
+		}
+	}
+}
+
+func TestDefineShapeFillStyleType(t *testing.T) {
+	tagsBytes := []byte{
+		0x87, 0x00, // DefineShape, length 7
+		0x01, 0x00, // ShapeID
+		0x08, 0x00, // ShapeBounds
+		0x01, 0x20, 0x00, // FillStyles
+	}
+	_, err := newParser(bytes.NewReader(tagsBytes)).ParseTags()
+	if !errors.Is(err, ErrFillStyleType) {
+		t.Errorf("expected %v, got %v", ErrFillStyleType, err)
+	}
+}
@@ -0,0 +1,310 @@
+package swf
+
+import (
+	"errors"
+	"io"
+)
+
+// These represent the compressions of a SoundFormat
+const (
+	SoundCompressionUncompressed             = 0  // SoundCompressionUncompressed is uncompressed, in the native byte order
+	SoundCompressionADPCM                    = 1  // SoundCompressionADPCM is ADPCM
+	SoundCompressionMP3                      = 2  // SoundCompressionMP3 is MP3
+	SoundCompressionUncompressedLittleEndian = 3  // SoundCompressionUncompressedLittleEndian is uncompressed, little endian
+	SoundCompressionNellymoser16kHz          = 4  // SoundCompressionNellymoser16kHz is Nellymoser 16 kHz
+	SoundCompressionNellymoser8kHz           = 5  // SoundCompressionNellymoser8kHz is Nellymoser 8 kHz
+	SoundCompressionNellymoser               = 6  // SoundCompressionNellymoser is Nellymoser
+	SoundCompressionSpeex                    = 11 // SoundCompressionSpeex is Speex
+)
+
+// These represent the rates of a SoundFormat
+const (
+	SoundRate5kHz  = 0 // SoundRate5kHz is 5.5 kHz
+	SoundRate11kHz = 1 // SoundRate11kHz is 11 kHz
+	SoundRate22kHz = 2 // SoundRate22kHz is 22 kHz
+	SoundRate44kHz = 3 // SoundRate44kHz is 44 kHz
+)
+
+// SampleRate returns the number of samples per second
+func (f SoundFormat) SampleRate() int {
+	return [...]int{5512, 11025, 22050, 44100}[f.Rate&0x03]
+}
+
+// SampleSize returns the number of bits of a sample. Compressed formats
+// always decompress to 16 bits samples
+func (f SoundFormat) SampleSize() int {
+	if f.Is16Bits {
+		return 16
+	}
+	return 8
+}
+
+// parseSoundFormat splits the byte holding a SoundFormat, the compression
+// being held by its high 4 bits
+func parseSoundFormat(b uint8) SoundFormat {
+	return SoundFormat{b >> 4, (b >> 2) & 0x03, b&0x02 != 0, b&0x01 != 0}
+}
+
+// soundFormatByte returns the byte holding a SoundFormat
+func soundFormatByte(f SoundFormat) uint8 {
+	b := f.Compression<<4 | (f.Rate&0x03)<<2
+	if f.Is16Bits {
+		b |= 0x02
+	}
+	if f.Stereo {
+		b |= 0x01
+	}
+	return b
+}
+
+func (p *parser) ParseTagDefineSound(length uint32) (Tag, error) {
+	t := &TagDefineSound{tag: tag{code: CodeTagDefineSound, length: length}}
+	var err error
+	if t.SoundID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "DefineSound.SoundId")
+	}
+	format, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, "DefineSound.SoundFormat")
+	}
+	t.Format = parseSoundFormat(format)
+	if t.SampleCount, err = p.r.ReadUInt32(); err != nil {
+		return nil, p.fail(err, "DefineSound.SoundSampleCount")
+	}
+	if t.SoundData, err = p.readAll("DefineSound.SoundData"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *parser) ParseTagStartSound(length uint32) (Tag, error) {
+	t := &TagStartSound{tag: tag{code: CodeTagStartSound, length: length}}
+	var err error
+	if t.SoundID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "StartSound.SoundId")
+	}
+	if t.SoundInfo, err = p.ParseSoundInfo(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *parser) ParseTagStartSound2(length uint32) (Tag, error) {
+	t := &TagStartSound{tag: tag{code: CodeTagStartSound2, length: length}}
+	var err error
+	if t.SoundClassName, err = p.r.ReadString(); err != nil {
+		return nil, p.fail(err, "StartSound2.SoundClassName")
+	}
+	if t.SoundInfo, err = p.ParseSoundInfo(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ParseSoundInfo parses a SoundInfo record
+func (p *parser) ParseSoundInfo() (info SoundInfo, err error) {
+	flags, err := p.r.ReadUInt8()
+	if err != nil {
+		return info, p.fail(err, "SOUNDINFO.Flags")
+	}
+	info.SyncStop = flags&0x20 != 0
+	info.SyncNoMultiple = flags&0x10 != 0
+	info.HasEnvelope = flags&0x08 != 0
+	info.HasLoops = flags&0x04 != 0
+	info.HasOutPoint = flags&0x02 != 0
+	info.HasInPoint = flags&0x01 != 0
+
+	if info.HasInPoint {
+		if info.InPoint, err = p.r.ReadUInt32(); err != nil {
+			return info, p.fail(err, "SOUNDINFO.InPoint")
+		}
+	}
+	if info.HasOutPoint {
+		if info.OutPoint, err = p.r.ReadUInt32(); err != nil {
+			return info, p.fail(err, "SOUNDINFO.OutPoint")
+		}
+	}
+	if info.HasLoops {
+		if info.LoopCount, err = p.r.ReadUInt16(); err != nil {
+			return info, p.fail(err, "SOUNDINFO.LoopCount")
+		}
+	}
+	if !info.HasEnvelope {
+		return info, nil
+	}
+	count, err := p.r.ReadUInt8()
+	if err != nil {
+		return info, p.fail(err, "SOUNDINFO.EnvPoints")
+	}
+	for i := uint8(0); i < count; i++ {
+		var envelope SoundEnvelope
+		if envelope.Pos44, err = p.r.ReadUInt32(); err != nil {
+			return info, p.fail(err, "SOUNDENVELOPE.Pos44")
+		}
+		if envelope.LeftLevel, err = p.r.ReadUInt16(); err != nil {
+			return info, p.fail(err, "SOUNDENVELOPE.LeftLevel")
+		}
+		if envelope.RightLevel, err = p.r.ReadUInt16(); err != nil {
+			return info, p.fail(err, "SOUNDENVELOPE.RightLevel")
+		}
+		info.EnvelopeRecords = append(info.EnvelopeRecords, envelope)
+	}
+	return info, nil
+}
+
+func (p *parser) ParseTagSoundStreamHead(length uint32) (Tag, error) {
+	return p.parseTagSoundStreamHead(CodeTagSoundStreamHead, "SoundStreamHead", length)
+}
+
+func (p *parser) ParseTagSoundStreamHead2(length uint32) (Tag, error) {
+	return p.parseTagSoundStreamHead(CodeTagSoundStreamHead2, "SoundStreamHead2", length)
+}
+
+func (p *parser) parseTagSoundStreamHead(code uint16, record string, length uint32) (Tag, error) {
+	begin, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	t := &TagSoundStreamHead{tag: tag{code: code, length: length}}
+	playback, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, record+".PlaybackSoundRate")
+	}
+	t.PlaybackFormat = parseSoundFormat(playback & 0x0f)
+	stream, err := p.r.ReadUInt8()
+	if err != nil {
+		return nil, p.fail(err, record+".StreamSoundCompression")
+	}
+	t.StreamFormat = parseSoundFormat(stream)
+	if t.StreamSampleCount, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, record+".StreamSoundSampleCount")
+	}
+
+	// Some MP3 streams omit LatencySeek, it is read when bytes remain
+	end, err := p.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	t.HasLatencySeek = t.StreamFormat.Compression == SoundCompressionMP3 && uint32(end-begin) < length
+	if t.HasLatencySeek {
+		if t.LatencySeek, err = p.r.ReadInt16(); err != nil {
+			return nil, p.fail(err, record+".LatencySeek")
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) ParseTagSoundStreamBlock(length uint32) (Tag, error) {
+	data, err := p.readAll("SoundStreamBlock.StreamSoundData")
+	if err != nil {
+		return nil, err
+	}
+	return &TagSoundStreamBlock{tag{code: CodeTagSoundStreamBlock, length: length}, data}, nil
+}
+
+func (s *serializer) SerializeTagDefineSound(t *TagDefineSound) error {
+	if err := s.w.WriteUInt16(t.SoundID); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt8(soundFormatByte(t.Format)); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt32(t.SampleCount); err != nil {
+		return err
+	}
+	_, err := s.w.Write(t.SoundData)
+	return err
+}
+
+// SerializeTagStartSound serializes a StartSound or a StartSound2 tag,
+// according to its code
+func (s *serializer) SerializeTagStartSound(t *TagStartSound) error {
+	var err error
+	if t.Code() == CodeTagStartSound2 {
+		err = s.w.WriteString(t.SoundClassName)
+	} else {
+		err = s.w.WriteUInt16(t.SoundID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.SerializeSoundInfo(t.SoundInfo)
+}
+
+// SerializeSoundInfo serializes a SoundInfo record
+func (s *serializer) SerializeSoundInfo(info SoundInfo) error {
+	var flags uint8
+	for _, flag := range []struct {
+		set bool
+		bit uint8
+	}{
+		{info.SyncStop, 0x20},
+		{info.SyncNoMultiple, 0x10},
+		{info.HasEnvelope, 0x08},
+		{info.HasLoops, 0x04},
+		{info.HasOutPoint, 0x02},
+		{info.HasInPoint, 0x01},
+	} {
+		if flag.set {
+			flags |= flag.bit
+		}
+	}
+	if err := s.w.WriteUInt8(flags); err != nil {
+		return err
+	}
+
+	if info.HasInPoint {
+		if err := s.w.WriteUInt32(info.InPoint); err != nil {
+			return err
+		}
+	}
+	if info.HasOutPoint {
+		if err := s.w.WriteUInt32(info.OutPoint); err != nil {
+			return err
+		}
+	}
+	if info.HasLoops {
+		if err := s.w.WriteUInt16(info.LoopCount); err != nil {
+			return err
+		}
+	}
+	if !info.HasEnvelope {
+		return nil
+	}
+	if len(info.EnvelopeRecords) > 0xff {
+		return errors.New("too many envelope records")
+	}
+	if err := s.w.WriteUInt8(uint8(len(info.EnvelopeRecords))); err != nil {
+		return err
+	}
+	for _, envelope := range info.EnvelopeRecords {
+		if err := s.w.WriteUInt32(envelope.Pos44); err != nil {
+			return err
+		}
+		if err := s.w.WriteUInt16(envelope.LeftLevel); err != nil {
+			return err
+		}
+		if err := s.w.WriteUInt16(envelope.RightLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeTagSoundStreamHead serializes a SoundStreamHead or a
+// SoundStreamHead2 tag, LatencySeek is written when HasLatencySeek is set
+func (s *serializer) SerializeTagSoundStreamHead(t *TagSoundStreamHead) error {
+	if err := s.w.WriteUInt8(soundFormatByte(t.PlaybackFormat) & 0x0f); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt8(soundFormatByte(t.StreamFormat)); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt16(t.StreamSampleCount); err != nil {
+		return err
+	}
+	if t.HasLatencySeek {
+		return s.w.WriteInt16(t.LatencySeek)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package swf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSoundTags(t *testing.T) {
+	tagsBytes := []byte{
+		0x89, 0x03, // DefineSound, length 9
+		0x01, 0x00, 0x2f, 0x00, 0x01, 0x00, 0x00, 0xaa, 0xbb,
+		0xd2, 0x03, // StartSound, length 18
+		0x01, 0x00, 0x2d, 0x10, 0x00, 0x00, 0x00, 0x02, 0x00, // SoundId, Flags, InPoint, LoopCount
+		0x01, 0x20, 0x00, 0x00, 0x00, 0x00, 0x80, 0xff, 0x7f, // EnvelopeRecords
+		0x43, 0x16, 0x61, 0x00, 0x10, // StartSound2, length 3
+		0x86, 0x04, 0x0e, 0x2e, 0x40, 0x02, 0xff, 0xff, // SoundStreamHead, length 6
+		0x44, 0x0b, 0x06, 0x16, 0x01, 0x00, // SoundStreamHead2, length 4
+		0xc3, 0x04, 0x01, 0x02, 0x03, // SoundStreamBlock, length 3
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	if len(tags) != 7 {
+		t.Fatalf("expected 7, got %v", len(tags))
+	}
+
+	expected := []Tag{
+		&TagDefineSound{
+			tag{code: CodeTagDefineSound, length: 9}, 1,
+			SoundFormat{SoundCompressionMP3, SoundRate44kHz, true, true},
+			256, []byte{0xaa, 0xbb},
+		},
+		&TagStartSound{tag{code: CodeTagStartSound, length: 18}, 1, "", SoundInfo{
+			SyncStop:        true,
+			HasEnvelope:     true,
+			HasLoops:        true,
+			HasInPoint:      true,
+			InPoint:         16,
+			LoopCount:       2,
+			EnvelopeRecords: []SoundEnvelope{{32, 0x8000, 0x7fff}},
+		}},
+		&TagStartSound{tag{code: CodeTagStartSound2, length: 3}, 0, "a", SoundInfo{SyncNoMultiple: true}},
+		&TagSoundStreamHead{
+			tag{code: CodeTagSoundStreamHead, length: 6},
+			SoundFormat{0, SoundRate44kHz, true, false},
+			SoundFormat{SoundCompressionMP3, SoundRate44kHz, true, false},
+			576, true, -1,
+		},
+		&TagSoundStreamHead{
+			tag{code: CodeTagSoundStreamHead2, length: 4},
+			SoundFormat{0, SoundRate11kHz, true, false},
+			SoundFormat{SoundCompressionADPCM, SoundRate11kHz, true, false},
+			1, false, 0,
+		},
+		&TagSoundStreamBlock{tag{code: CodeTagSoundStreamBlock, length: 3}, []byte{0x01, 0x02, 0x03}},
+	}
+	for i, e := range expected {
+		if !reflect.DeepEqual(tags[i], e) {
+			t.Errorf("expected %#v, got %#v", e, tags[i])
+		}
+	}
+}
+
+func TestSoundStreamHeadWithoutLatencySeek(t *testing.T) {
+	tags := roundTripTags(t, []byte{0x84, 0x04, 0x0e, 0x2e, 0x40, 0x02, 0x00, 0x00})
+	if head, ok := tags[0].(*TagSoundStreamHead); !ok || head.StreamSampleCount != 576 || head.HasLatencySeek {
+		t.Errorf("expected a SoundStreamHead without LatencySeek, got %#v", tags[0])
+	}
+}
+
+func TestSoundFormat(t *testing.T) {
+	cases := []struct {
+		format SoundFormat
+		rate   int
+		size   int
+	}{
+		{SoundFormat{SoundCompressionUncompressed, SoundRate5kHz, false, false}, 5512, 8},
+		{SoundFormat{SoundCompressionADPCM, SoundRate11kHz, true, false}, 11025, 16},
+		{SoundFormat{SoundCompressionNellymoser, SoundRate22kHz, true, true}, 22050, 16},
+		{SoundFormat{SoundCompressionSpeex, SoundRate44kHz, false, true}, 44100, 8},
+	}
+	for _, c := range cases {
+		if rate := c.format.SampleRate(); rate != c.rate {
+			t.Errorf("expected %v, got %v", c.rate, rate)
+		}
+		if size := c.format.SampleSize(); size != c.size {
+			t.Errorf("expected %v, got %v", c.size, size)
+		}
+		if f := parseSoundFormat(soundFormatByte(c.format)); f != c.format {
+			t.Errorf("expected %v, got %v", c.format, f)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package swf
+
+import "errors"
+
+// ErrSpriteTag means that a sprite holds a tag which is not a control tag
+var ErrSpriteTag = errors.New("tag not allowed in a sprite")
+
+// isControlTag returns true when the tags of the given code are allowed in
+// a sprite
+func isControlTag(code uint16) bool {
+	switch code {
+	case CodeTagEnd, CodeTagShowFrame, CodeTagFrameLabel,
+		CodeTagPlaceObject, CodeTagPlaceObject2, CodeTagPlaceObject3,
+		CodeTagRemoveObject, CodeTagRemoveObject2, CodeTagDoAction,
+		CodeTagStartSound, CodeTagStartSound2,
+		CodeTagSoundStreamHead, CodeTagSoundStreamHead2, CodeTagSoundStreamBlock,
+		CodeTagVideoFrame:
+		return true
+	}
+	return false
+}
+
+// Timeline returns the timeline of the sprite
+func (t *TagDefineSprite) Timeline() *Timeline {
+	return NewTimeline(t.Tags)
+}
+
+func (p *parser) ParseTagDefineSprite(length uint32) (Tag, error) {
+	t := &TagDefineSprite{tag: tag{code: CodeTagDefineSprite, length: length}}
+	var err error
+	if t.SpriteID, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "DefineSprite.SpriteID")
+	}
+	if t.FrameCount, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "DefineSprite.FrameCount")
+	}
+	if t.Tags, err = p.parseControlTags(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseControlTags parses the tags of a sprite up to its End tag, with the
+// decoders of the parser. A tag that is not a control tag fails with
+// ErrSpriteTag, in lenient mode it is reported as a warning and kept
+func (p *parser) parseControlTags() ([]Tag, error) {
+	// The nested tags are located relatively to the sprite, and its End
+	// tag is not the end of the file
+	tagIndex, tagCode, fileLength := p.tagIndex, p.tagCode, p.fileLength
+	p.fileLength = 0
+	defer func() { p.tagIndex, p.tagCode, p.fileLength = tagIndex, tagCode, fileLength }()
+
+	var tags []Tag
+	it := p.Tags()
+	for it.Next() {
+		if !isControlTag(it.Code()) {
+			err := p.fail(ErrSpriteTag, "DefineSprite.ControlTags")
+			if !p.lenient {
+				return nil, err
+			}
+			p.warn(err)
+		}
+		if t := it.Tag(); t != nil {
+			tags = append(tags, t)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// SerializeTagDefineSprite serializes a DefineSprite tag, its control tags
+// are serialized as is
+func (s *serializer) SerializeTagDefineSprite(t *TagDefineSprite) error {
+	if err := s.w.WriteUInt16(t.SpriteID); err != nil {
+		return err
+	}
+	if err := s.w.WriteUInt16(t.FrameCount); err != nil {
+		return err
+	}
+	return s.SerializeTags(t.Tags)
+}
@@ -0,0 +1,95 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDefineSprite(t *testing.T) {
+	tagsBytes := []byte{
+		0xcf, 0x09, // DefineSprite, length 15
+		0x01, 0x00, // SpriteID
+		0x01, 0x00, // FrameCount
+		0x85, 0x06, 0x02, 0x01, 0x00, 0x02, 0x00, // PlaceObject2
+		0x40, 0x00, // ShowFrame
+		0x00, 0x00, // End
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	if len(tags) != 2 {
+		t.Fatalf("expected 2, got %v", len(tags))
+	}
+
+	expected := &TagDefineSprite{
+		tag:        tag{code: CodeTagDefineSprite, length: 15},
+		SpriteID:   1,
+		FrameCount: 1,
+		Tags: []Tag{
+			&TagPlaceObject{tag: tag{code: CodeTagPlaceObject2, length: 5}, HasCharacter: true, Depth: 1, CharacterID: 2},
+			&tag{code: CodeTagShowFrame, length: 0},
+			&tag{code: CodeTagEnd, length: 0},
+		},
+	}
+	if !reflect.DeepEqual(tags[0], expected) {
+		t.Errorf("expected %#v, got %#v", expected, tags[0])
+	}
+
+	frame, err := expected.Timeline().Frame(0)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if objects := []DisplayObject{{Depth: 1, CharacterID: 2}}; !reflect.DeepEqual(frame, objects) {
+		t.Errorf("expected %#v, got %#v", objects, frame)
+	}
+}
+
+func TestDefineSpriteVideoFrame(t *testing.T) {
+	// Embedded videos write their frames in sprites
+	tagsBytes := []byte{
+		0xcb, 0x09, // DefineSprite, length 11
+		0x01, 0x00, // SpriteID
+		0x01, 0x00, // FrameCount
+		0x43, 0x0f, 0x02, 0x00, 0x00, // VideoFrame
+		0x00, 0x00, // End
+		0x00, 0x00, // End
+	}
+	tags := roundTripTags(t, tagsBytes)
+	sprite, ok := tags[0].(*TagDefineSprite)
+	if !ok || len(sprite.Tags) != 2 || sprite.Tags[0].Code() != CodeTagVideoFrame {
+		t.Errorf("expected a sprite with a VideoFrame, got %#v", tags[0])
+	}
+}
+
+func TestDefineSpriteControlTags(t *testing.T) {
+	tagsBytes := []byte{
+		0xcb, 0x09, // DefineSprite, length 11
+		0x01, 0x00, // SpriteID
+		0x00, 0x00, // FrameCount
+		0x43, 0x02, 0xff, 0xff, 0xff, // SetBackgroundColor
+		0x00, 0x00, // End
+		0x00, 0x00, // End
+	}
+	_, err := newParser(bytes.NewReader(tagsBytes)).ParseTags()
+	if !errors.Is(err, ErrSpriteTag) {
+		t.Errorf("expected %v, got %v", ErrSpriteTag, err)
+	}
+
+	// In lenient mode the tag is kept
+	file := lenientFile(uint32(len(lenientHeaderBytes)+len(tagsBytes)), tagsBytes)
+	s, err := Parse(bytes.NewReader(file), Lenient())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if len(s.Warnings) != 1 || !errors.Is(s.Warnings[0], ErrSpriteTag) {
+		t.Errorf("expected 1 warning, got %v", s.Warnings)
+	}
+	sprite, ok := s.Tags[0].(*TagDefineSprite)
+	if !ok || len(sprite.Tags) != 2 {
+		t.Fatalf("expected a sprite with 2 tags, got %#v", s.Tags[0])
+	}
+	if _, ok := sprite.Tags[0].(*TagSetBackgroundColor); !ok {
+		t.Errorf("expected *TagSetBackgroundColor, got %#v", sprite.Tags[0])
+	}
+}
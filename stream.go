@@ -0,0 +1,56 @@
+package swf
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// ErrSeekBackward means that a compressed Swf file body can not be seeked
+// backward. It is decompressed on the fly and never held in memory
+var ErrSeekBackward = errors.New("seek backward in a compressed stream")
+
+// streamReader provides the io.ReadSeeker expected by Reader on top of a
+// decompressed stream. It keeps track of the position, seeking forward
+// discards the bytes in between
+type streamReader struct {
+	r   *bufio.Reader
+	pos int64
+}
+
+// newStreamReader creates a streamReader whose first byte is at offset pos
+func newStreamReader(r io.Reader, pos int64) *streamReader {
+	return &streamReader{bufio.NewReader(r), pos}
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+func (s *streamReader) ReadByte() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err == nil {
+		s.pos++
+	}
+	return b, err
+}
+
+func (s *streamReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	default:
+		return s.pos, errors.New("invalid whence")
+	case io.SeekStart:
+		break
+	case io.SeekCurrent:
+		offset += s.pos
+	}
+	if offset < s.pos {
+		return s.pos, ErrSeekBackward
+	}
+	n, err := io.CopyN(ioutil.Discard, s.r, offset-s.pos)
+	s.pos += n
+	return s.pos, err
+}
@@ -0,0 +1,38 @@
+package swf
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestStreamReader(t *testing.T) {
+	s := newStreamReader(bytes.NewBufferString("abcdefgh"), 8)
+
+	if b, err := s.ReadByte(); err != nil || b != 'a' {
+		t.Errorf("expected 'a', got %v (%v)", b, err)
+	}
+	if pos, err := s.Seek(2, io.SeekCurrent); err != nil || pos != 11 {
+		t.Errorf("expected 11, got %v (%v)", pos, err)
+	}
+	if pos, err := s.Seek(12, io.SeekStart); err != nil || pos != 12 {
+		t.Errorf("expected 12, got %v (%v)", pos, err)
+	}
+	if b, err := s.ReadByte(); err != nil || b != 'e' {
+		t.Errorf("expected 'e', got %v (%v)", b, err)
+	}
+	if _, err := s.Seek(10, io.SeekStart); err != ErrSeekBackward {
+		t.Errorf("expected ErrSeekBackward, got %v", err)
+	}
+
+	buf := make([]byte, 2)
+	if n, err := s.Read(buf); err != nil || n != 2 || string(buf) != "fg" {
+		t.Errorf("expected \"fg\", got %q (%v)", buf[:n], err)
+	}
+	if pos, _ := s.Seek(0, io.SeekCurrent); pos != 15 {
+		t.Errorf("expected 15, got %v", pos)
+	}
+	if _, err := s.Seek(2, io.SeekCurrent); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// SVGOption configures the conversion of a shape to SVG
+type SVGOption func(w *svgWriter)
+
+// SVGBitmaps makes the bitmap fills embed the images of the given bitmap
+// tags, such as the tags of the Swf file. Bitmap fills whose bitmap is not
+// found are not painted
+func SVGBitmaps(tags []Tag) SVGOption {
+	return func(w *svgWriter) {
+		w.bitmaps = tags
+	}
+}
+
+// svgPoint is a point in twips
+type svgPoint struct {
+	x, y int32
+}
+
+// svgEdge is a straight edge, or a quadratic curve when curved is set
+type svgEdge struct {
+	from, control, to svgPoint
+	curved            bool
+}
+
+func (e svgEdge) reverse() svgEdge {
+	return svgEdge{e.to, e.control, e.from, e.curved}
+}
+
+// svgGroup holds the edges drawn with a set of styles. A new group starts
+// with every StyleChangeRecord defining new styles
+type svgGroup struct {
+	fillStyles []FillStyle
+	lineStyles []LineStyle
+	fills      map[uint32][]svgEdge
+	lines      map[uint32][]svgEdge
+}
+
+func newSVGGroup(fillStyles []FillStyle, lineStyles []LineStyle) *svgGroup {
+	return &svgGroup{fillStyles, lineStyles, map[uint32][]svgEdge{}, map[uint32][]svgEdge{}}
+}
+
+type svgWriter struct {
+	buf      bytes.Buffer
+	bitmaps  []Tag
+	fillRule string
+	paints   int
+}
+
+// ToSVG converts the shape to a SVG document, in pixels.
+// Each fill style becomes a closed path, rebuilt from the edges having the
+// style on either side, and each line style becomes a stroked path.
+// Gradients and bitmap fills are converted to SVG gradients and patterns,
+// clipped bitmap fills are repeated as SVG has no clipped pattern
+func (t *TagDefineShape) ToSVG(w io.Writer, opts ...SVGOption) error {
+	sw := &svgWriter{fillRule: "evenodd"}
+	if t.UsesFillWindingRule {
+		sw.fillRule = "nonzero"
+	}
+	for _, opt := range opts {
+		opt(sw)
+	}
+
+	b := t.ShapeBounds
+	fmt.Fprintf(&sw.buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%s" height="%s" viewBox="%s %s %s %s">`+"\n",
+		svgTwips(b.Xmax-b.Xmin), svgTwips(b.Ymax-b.Ymin), svgTwips(b.Xmin), svgTwips(b.Ymin), svgTwips(b.Xmax-b.Xmin), svgTwips(b.Ymax-b.Ymin))
+	for _, group := range t.Shapes.groups() {
+		if err := sw.writeGroup(group); err != nil {
+			return err
+		}
+	}
+	sw.buf.WriteString("</svg>\n")
+
+	_, err := w.Write(sw.buf.Bytes())
+	return err
+}
+
+// groups replays the shape records and sorts the edges by style.
+// Edges are added as is to their FillStyle1 and reversed to their
+// FillStyle0, so that the edges of a fill all go the same way around it
+func (shape ShapeWithStyle) groups() []*svgGroup {
+	group := newSVGGroup(shape.FillStyles, shape.LineStyles)
+	groups := []*svgGroup{group}
+
+	var pos svgPoint
+	var fill0, fill1, line uint32
+	for _, record := range shape.ShapeRecords {
+		var edge svgEdge
+		switch r := record.(type) {
+		case *StyleChangeRecord:
+			if r.StateNewStyles {
+				group = newSVGGroup(r.FillStyles, r.LineStyles)
+				groups = append(groups, group)
+				fill0, fill1, line = 0, 0, 0
+			}
+			if r.StateMoveTo {
+				pos = svgPoint{r.MoveDeltaX, r.MoveDeltaY}
+			}
+			if r.StateFillStyle0 {
+				fill0 = r.FillStyle0
+			}
+			if r.StateFillStyle1 {
+				fill1 = r.FillStyle1
+			}
+			if r.StateLineStyle {
+				line = r.LineStyle
+			}
+			continue
+		case *StraightEdgeRecord:
+			to := svgPoint{pos.x + r.DeltaX, pos.y + r.DeltaY}
+			edge = svgEdge{pos, to, to, false}
+		case *CurvedEdgeRecord:
+			control := svgPoint{pos.x + r.ControlDeltaX, pos.y + r.ControlDeltaY}
+			to := svgPoint{control.x + r.AnchorDeltaX, control.y + r.AnchorDeltaY}
+			edge = svgEdge{pos, control, to, true}
+		default:
+			continue
+		}
+		pos = edge.to
+
+		if fill0 != 0 {
+			group.fills[fill0] = append(group.fills[fill0], edge.reverse())
+		}
+		if fill1 != 0 {
+			group.fills[fill1] = append(group.fills[fill1], edge)
+		}
+		if line != 0 {
+			group.lines[line] = append(group.lines[line], edge)
+		}
+	}
+	return groups
+}
+
+// writeGroup writes the fills of a group, then its lines
+func (w *svgWriter) writeGroup(g *svgGroup) error {
+	for _, index := range sortedStyles(g.fills) {
+		if int(index) > len(g.fillStyles) {
+			continue
+		}
+		style := g.fillStyles[index-1]
+		paint, err := w.writePaint(style)
+		if err != nil {
+			return err
+		}
+		opacity := ""
+		if style.FillStyleType == FillStyleSolid && style.Color.Alpha != 0xff {
+			opacity = fmt.Sprintf(` fill-opacity="%s"`, svgOpacity(style.Color.Alpha))
+		}
+		fmt.Fprintf(&w.buf, `<path d="%s" fill-rule="%s" fill="%s"%s/>`+"\n", fillPath(g.fills[index]), w.fillRule, paint, opacity)
+	}
+
+	for _, index := range sortedStyles(g.lines) {
+		if int(index) > len(g.lineStyles) {
+			continue
+		}
+		style := g.lineStyles[index-1]
+		paint := svgColor(style.Color)
+		if style.HasFill {
+			var err error
+			if paint, err = w.writePaint(style.FillType); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(&w.buf, `<path d="%s" fill="none" stroke="%s"%s/>`+"\n", linePath(g.lines[index]), paint, svgStroke(style))
+	}
+	return nil
+}
+
+func sortedStyles(edges map[uint32][]svgEdge) []uint32 {
+	indexes := make([]uint32, 0, len(edges))
+	for index := range edges {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	return indexes
+}
+
+// fillPath chains the edges of a fill into closed paths.
+// Each path follows the edges starting where the previous one ends, until
+// it gets back to its first point
+func fillPath(edges []svgEdge) string {
+	starts := map[svgPoint][]int{}
+	for i, e := range edges {
+		starts[e.from] = append(starts[e.from], i)
+	}
+	used := make([]bool, len(edges))
+	next := func(p svgPoint) int {
+		for len(starts[p]) > 0 {
+			i := starts[p][0]
+			starts[p] = starts[p][1:]
+			if !used[i] {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var d bytes.Buffer
+	for i := range edges {
+		if used[i] {
+			continue
+		}
+		first := edges[i].from
+		fmt.Fprintf(&d, "M%s %s", svgTwips(first.x), svgTwips(first.y))
+		for cur := i; cur >= 0; {
+			used[cur] = true
+			writeEdge(&d, edges[cur])
+			if edges[cur].to == first {
+				break
+			}
+			cur = next(edges[cur].to)
+		}
+		d.WriteString("Z")
+	}
+	return d.String()
+}
+
+// linePath chains the edges of a line in drawing order, a new subpath
+// starts when an edge does not start where the previous one ends
+func linePath(edges []svgEdge) string {
+	var d bytes.Buffer
+	for i, e := range edges {
+		if i == 0 || edges[i-1].to != e.from {
+			fmt.Fprintf(&d, "M%s %s", svgTwips(e.from.x), svgTwips(e.from.y))
+		}
+		writeEdge(&d, e)
+	}
+	return d.String()
+}
+
+func writeEdge(d *bytes.Buffer, e svgEdge) {
+	if e.curved {
+		fmt.Fprintf(d, "Q%s %s %s %s", svgTwips(e.control.x), svgTwips(e.control.y), svgTwips(e.to.x), svgTwips(e.to.y))
+		return
+	}
+	fmt.Fprintf(d, "L%s %s", svgTwips(e.to.x), svgTwips(e.to.y))
+}
+
+// writePaint returns the paint of a fill style, a color or a reference to
+// a gradient or a pattern written in a defs element
+func (w *svgWriter) writePaint(style FillStyle) (string, error) {
+	switch {
+	case isGradient(style.FillStyleType):
+		w.paints++
+		id := fmt.Sprintf("paint%d", w.paints)
+		w.writeGradient(id, style)
+		return "url(#" + id + ")", nil
+	case isBitmap(style.FillStyleType):
+		img, err := w.bitmap(style.BitmapID)
+		if err != nil || img == nil {
+			return "none", err
+		}
+		w.paints++
+		id := fmt.Sprintf("paint%d", w.paints)
+		if err = w.writePattern(id, style, img); err != nil {
+			return "", err
+		}
+		return "url(#" + id + ")", nil
+	}
+	return svgColor(style.Color), nil
+}
+
+// writeGradient writes a gradient. The gradient square of Swf files spans
+// from -16384 to 16384 twips, it is written in pixels and the gradient
+// matrix maps it to the shape
+func (w *svgWriter) writeGradient(id string, style FillStyle) {
+	g := style.Gradient
+	spread := "pad"
+	switch g.SpreadMode {
+	case 1:
+		spread = "reflect"
+	case 2:
+		spread = "repeat"
+	}
+	interpolation := ""
+	if g.InterpolationMode == 1 {
+		interpolation = ` color-interpolation="linearRGB"`
+	}
+
+	w.buf.WriteString("<defs>")
+	transform := svgTransform(style.GradientMatrix, 1)
+	if style.FillStyleType == FillStyleLinearGradient {
+		fmt.Fprintf(&w.buf, `<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="-819.2" y1="0" x2="819.2" y2="0" spreadMethod="%s" gradientTransform="%s"%s>`,
+			id, spread, transform, interpolation)
+	} else {
+		fx := strconv.FormatFloat(float64(g.FocalPoint)*819.2, 'f', -1, 64)
+		fmt.Fprintf(&w.buf, `<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="0" cy="0" r="819.2" fx="%s" fy="0" spreadMethod="%s" gradientTransform="%s"%s>`,
+			id, fx, spread, transform, interpolation)
+	}
+	for _, record := range g.GradientRecords {
+		fmt.Fprintf(&w.buf, `<stop offset="%s" stop-color="%s" stop-opacity="%s"/>`,
+			strconv.FormatFloat(float64(record.Ratio)/255, 'f', -1, 64), svgColor(record.Color), svgOpacity(record.Color.Alpha))
+	}
+	if style.FillStyleType == FillStyleLinearGradient {
+		w.buf.WriteString("</linearGradient>")
+	} else {
+		w.buf.WriteString("</radialGradient>")
+	}
+	w.buf.WriteString("</defs>\n")
+}
+
+// writePattern writes a pattern embedding a bitmap as a PNG image.
+// The bitmap matrix maps the pixels of the bitmap to the shape
+func (w *svgWriter) writePattern(id string, style FillStyle, img image.Image) error {
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		return err
+	}
+	rendering := ""
+	if style.FillStyleType == FillStyleNonSmoothedRepeatingBitmap || style.FillStyleType == FillStyleNonSmoothedClippedBitmap {
+		rendering = ` image-rendering="optimizeSpeed"`
+	}
+	size := img.Bounds().Size()
+	fmt.Fprintf(&w.buf, `<defs><pattern id="%s" patternUnits="userSpaceOnUse" width="%d" height="%d" patternTransform="%s">`,
+		id, size.X, size.Y, svgTransform(style.BitmapMatrix, 20))
+	fmt.Fprintf(&w.buf, `<image width="%d" height="%d"%s xlink:href="data:image/png;base64,%s"/>`,
+		size.X, size.Y, rendering, base64.StdEncoding.EncodeToString(data.Bytes()))
+	w.buf.WriteString("</pattern></defs>\n")
+	return nil
+}
+
+// bitmap decodes the image of the bitmap tag of the given character ID.
+// It returns nil when there is no such tag
+func (w *svgWriter) bitmap(id uint16) (image.Image, error) {
+	for _, t := range w.bitmaps {
+		switch t := t.(type) {
+		case *TagDefineBits:
+			if t.CharacterID == id {
+				return t.Image()
+			}
+		case *TagDefineBitsJPEG2:
+			if t.CharacterID == id {
+				return t.Image()
+			}
+		case *TagDefineBitsJPEG3:
+			if t.CharacterID == id {
+				return t.Image()
+			}
+		case *TagDefineBitsLossless:
+			if t.CharacterID == id {
+				return t.Image()
+			}
+		}
+	}
+	return nil, nil
+}
+
+// svgTwips converts twips to pixels
+func svgTwips(v int32) string {
+	return strconv.FormatFloat(float64(v)/20, 'f', -1, 64)
+}
+
+// svgTransform converts a matrix to a SVG transform in pixels. The scale
+// divides the coefficients, it is 20 when the matrix maps twips, such as
+// for bitmaps, and 1 when the mapped space is already in pixels
+func svgTransform(m Matrix, scale float64) string {
+	a, b, c, d := m.coefficients()
+	values := []float64{a / scale, b / scale, c / scale, d / scale, float64(m.TranslateX) / 20, float64(m.TranslateY) / 20}
+	s := "matrix("
+	for i, v := range values {
+		if i > 0 {
+			s += " "
+		}
+		s += strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return s + ")"
+}
+
+func svgColor(c RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.Red, c.Green, c.Blue)
+}
+
+func svgOpacity(alpha uint8) string {
+	return strconv.FormatFloat(float64(alpha)/255, 'f', -1, 64)
+}
+
+// svgStroke returns the attributes of a line style. The zero values of the
+// cap and join styles are round, as the lines of DefineShape to DefineShape3
+func svgStroke(style LineStyle) string {
+	caps := map[uint8]string{CapStyleRound: "round", CapStyleNone: "butt", CapStyleSquare: "square"}
+	joins := map[uint8]string{JoinStyleRound: "round", JoinStyleBevel: "bevel", JoinStyleMiter: "miter"}
+
+	s := ""
+	if style.Width == 0 {
+		// Hairlines are one pixel wide at any scale
+		s += ` stroke-width="1" vector-effect="non-scaling-stroke"`
+	} else {
+		s += fmt.Sprintf(` stroke-width="%s"`, svgTwips(int32(style.Width)))
+		if style.NoHScale || style.NoVScale {
+			s += ` vector-effect="non-scaling-stroke"`
+		}
+	}
+	if !style.HasFill && style.Color.Alpha != 0xff {
+		s += fmt.Sprintf(` stroke-opacity="%s"`, svgOpacity(style.Color.Alpha))
+	}
+	s += fmt.Sprintf(` stroke-linecap="%s" stroke-linejoin="%s"`, caps[style.StartCapStyle], joins[style.JoinStyle])
+	if style.JoinStyle == JoinStyleMiter {
+		s += fmt.Sprintf(` stroke-miterlimit="%s"`, strconv.FormatFloat(float64(style.MiterLimitFactor), 'f', -1, 32))
+	}
+	return s
+}
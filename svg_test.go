@@ -0,0 +1,110 @@
+package swf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToSVG(t *testing.T) {
+	// Two squares sharing an edge, filled on either side of it
+	shape := &TagDefineShape{
+		tag:         tag{code: CodeTagDefineShape3, length: 0},
+		ShapeBounds: Rect{Xmax: 400, Ymax: 200},
+		Shapes: ShapeWithStyle{
+			FillStyles: []FillStyle{
+				{FillStyleType: FillStyleSolid, Color: RGBA{0xff, 0, 0, 0xff}},
+				{FillStyleType: FillStyleSolid, Color: RGBA{0, 0, 0xff, 0x80}},
+			},
+			LineStyles: []LineStyle{{Width: 20, Color: RGBA{0, 0, 0, 0xff}}},
+			ShapeRecords: []ShapeRecord{
+				&StyleChangeRecord{StateMoveTo: true, StateFillStyle1: true, StateLineStyle: true, FillStyle1: 1, LineStyle: 1},
+				&StraightEdgeRecord{DeltaX: 200},
+				&StyleChangeRecord{StateFillStyle0: true, FillStyle0: 2},
+				&StraightEdgeRecord{DeltaY: 200},
+				&StyleChangeRecord{StateFillStyle0: true},
+				&StraightEdgeRecord{DeltaX: -200},
+				&StraightEdgeRecord{DeltaY: -200},
+				&StyleChangeRecord{StateMoveTo: true, StateFillStyle0: true, StateFillStyle1: true, MoveDeltaX: 200, MoveDeltaY: 200, FillStyle0: 2},
+				&StraightEdgeRecord{DeltaX: 200},
+				&StraightEdgeRecord{DeltaY: -200},
+				&StraightEdgeRecord{DeltaX: -200},
+			},
+		},
+	}
+
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="20" height="10" viewBox="0 0 20 10">
+<path d="M0 0L10 0L10 10L0 10L0 0Z" fill-rule="evenodd" fill="#ff0000"/>
+<path d="M10 10L10 0L20 0L20 10L10 10Z" fill-rule="evenodd" fill="#0000ff" fill-opacity="0.5019607843137255"/>
+<path d="M0 0L10 0L10 10L0 10L0 0M10 10L20 10L20 0L10 0" fill="none" stroke="#000000" stroke-width="1" stroke-linecap="round" stroke-linejoin="round"/>
+</svg>
+`
+	var buf bytes.Buffer
+	if err := shape.ToSVG(&buf); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+}
+
+func TestToSVGPaints(t *testing.T) {
+	shape := &TagDefineShape{
+		tag:         tag{code: CodeTagDefineShape4, length: 0},
+		ShapeBounds: Rect{Xmax: 200, Ymax: 200},
+		Shapes: ShapeWithStyle{
+			FillStyles: []FillStyle{
+				{
+					FillStyleType:  FillStyleLinearGradient,
+					GradientMatrix: Matrix{TranslateX: 200, TranslateY: 200},
+					Gradient:       Gradient{SpreadMode: 1, GradientRecords: []GradRecord{{0, RGBA{0xff, 0, 0, 0xff}}, {0xff, RGBA{0, 0, 0xff, 0}}}},
+				},
+				{FillStyleType: FillStyleRepeatingBitmap, BitmapID: 5, BitmapMatrix: Matrix{HasScale: true, ScaleX: 20, ScaleY: 20}},
+			},
+			LineStyles: []LineStyle{
+				{Width: 40, JoinStyle: JoinStyleMiter, MiterLimitFactor: 2.5, StartCapStyle: CapStyleSquare, HasFill: true, FillType: FillStyle{
+					FillStyleType: FillStyleFocalRadialGradient,
+					Gradient:      Gradient{FocalPoint: 0.5},
+				}},
+			},
+			ShapeRecords: []ShapeRecord{
+				&StyleChangeRecord{StateFillStyle1: true, StateLineStyle: true, FillStyle1: 1, LineStyle: 1},
+				&CurvedEdgeRecord{ControlDeltaX: 200, AnchorDeltaY: 200},
+				&StraightEdgeRecord{DeltaX: -200, DeltaY: -200},
+				&StyleChangeRecord{StateFillStyle1: true, StateLineStyle: true, FillStyle1: 2},
+				&StraightEdgeRecord{DeltaX: 200},
+				&StraightEdgeRecord{DeltaX: -200, DeltaY: 200},
+				&StraightEdgeRecord{DeltaY: -200},
+			},
+		},
+	}
+	bitmap := &TagDefineBitsLossless{tag{code: CodeTagDefineBitsLossless, length: 0}, 5, BitmapFormatRGB24, 1, 1, 0, compressZlib(t, []byte{0, 0xff, 0, 0})}
+
+	var buf bytes.Buffer
+	if err := shape.ToSVG(&buf, SVGBitmaps([]Tag{bitmap})); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	svg := buf.String()
+	for _, s := range []string{
+		`<linearGradient id="paint1" gradientUnits="userSpaceOnUse" x1="-819.2" y1="0" x2="819.2" y2="0" spreadMethod="reflect" gradientTransform="matrix(1 0 0 1 10 10)">`,
+		`<stop offset="1" stop-color="#0000ff" stop-opacity="0"/>`,
+		`<path d="M0 0Q10 0 10 10L0 0Z" fill-rule="evenodd" fill="url(#paint1)"/>`,
+		`<pattern id="paint2" patternUnits="userSpaceOnUse" width="1" height="1" patternTransform="matrix(1 0 0 1 0 0)">`,
+		`xlink:href="data:image/png;base64,`,
+		`<radialGradient id="paint3" gradientUnits="userSpaceOnUse" cx="0" cy="0" r="819.2" fx="409.6" fy="0" spreadMethod="pad"`,
+		`<path d="M0 0Q10 0 10 10L0 0" fill="none" stroke="url(#paint3)" stroke-width="2" stroke-linecap="square" stroke-linejoin="miter" stroke-miterlimit="2.5"/>`,
+	} {
+		if !strings.Contains(svg, s) {
+			t.Errorf("expected %v in %v", s, svg)
+		}
+	}
+
+	// The bitmap fill is not painted without its bitmap
+	buf.Reset()
+	if err := shape.ToSVG(&buf); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if s := `fill="none"/>`; !strings.Contains(buf.String(), s) {
+		t.Errorf("expected %v in %v", s, buf.String())
+	}
+}
@@ -0,0 +1,130 @@
+package swf
+
+import "errors"
+
+// ErrTooManySymbols means that a tag can not be serialized.
+// It holds more symbols than its uint16 count allows
+var ErrTooManySymbols = errors.New("too many symbols")
+
+// Symbols returns the names bound to character IDs by the SymbolClass,
+// ExportAssets, ImportAssets and ImportAssets2 tags. SymbolClass names are
+// ActionScript 3 class names, ID 0 being the document class.
+// When several tags name the same ID, the last one wins
+func (s Swf) Symbols() map[uint16]string {
+	symbols := make(map[uint16]string)
+	for _, t := range s.Tags {
+		var list []Symbol
+		switch t := t.(type) {
+		case *TagSymbolClass:
+			list = t.Symbols
+		case *TagExportAssets:
+			list = t.Symbols
+		case *TagImportAssets:
+			list = t.Symbols
+		}
+		for _, symbol := range list {
+			symbols[symbol.CharacterID] = symbol.Name
+		}
+	}
+	return symbols
+}
+
+// ParseSymbols parses a count followed by as many tag/name pairs
+func (p *parser) ParseSymbols(record string) ([]Symbol, error) {
+	count, err := p.r.ReadUInt16()
+	if err != nil {
+		return nil, p.fail(err, record+".NumSymbols")
+	}
+	var symbols []Symbol
+	for i := uint16(0); i < count; i++ {
+		id, err := p.r.ReadUInt16()
+		if err != nil {
+			return nil, p.fail(err, record+".Tag")
+		}
+		name, err := p.r.ReadString()
+		if err != nil {
+			return nil, p.fail(err, record+".Name")
+		}
+		symbols = append(symbols, Symbol{id, name})
+	}
+	return symbols, nil
+}
+
+func (p *parser) ParseTagSymbolClass(length uint32) (Tag, error) {
+	symbols, err := p.ParseSymbols("SymbolClass")
+	if err != nil {
+		return nil, err
+	}
+	return &TagSymbolClass{tag{code: CodeTagSymbolClass, length: length}, symbols}, nil
+}
+
+func (p *parser) ParseTagExportAssets(length uint32) (Tag, error) {
+	symbols, err := p.ParseSymbols("ExportAssets")
+	if err != nil {
+		return nil, err
+	}
+	return &TagExportAssets{tag{code: CodeTagExportAssets, length: length}, symbols}, nil
+}
+
+func (p *parser) ParseTagImportAssets(length uint32) (Tag, error) {
+	url, err := p.r.ReadString()
+	if err != nil {
+		return nil, p.fail(err, "ImportAssets.URL")
+	}
+	symbols, err := p.ParseSymbols("ImportAssets")
+	if err != nil {
+		return nil, err
+	}
+	return &TagImportAssets{tag{code: CodeTagImportAssets, length: length}, url, symbols}, nil
+}
+
+// ParseTagImportAssets2 parses an ImportAssets2 tag, which only adds two
+// reserved bytes to ImportAssets
+func (p *parser) ParseTagImportAssets2(length uint32) (Tag, error) {
+	url, err := p.r.ReadString()
+	if err != nil {
+		return nil, p.fail(err, "ImportAssets2.URL")
+	}
+	if _, err = p.r.ReadUInt16(); err != nil {
+		return nil, p.fail(err, "ImportAssets2.Reserved")
+	}
+	symbols, err := p.ParseSymbols("ImportAssets2")
+	if err != nil {
+		return nil, err
+	}
+	return &TagImportAssets{tag{code: CodeTagImportAssets2, length: length}, url, symbols}, nil
+}
+
+// SerializeSymbols serializes a count followed by as many tag/name pairs
+func (s *serializer) SerializeSymbols(symbols []Symbol) error {
+	if len(symbols) > 0xffff {
+		return ErrTooManySymbols
+	}
+	if err := s.w.WriteUInt16(uint16(len(symbols))); err != nil {
+		return err
+	}
+	for _, symbol := range symbols {
+		if err := s.w.WriteUInt16(symbol.CharacterID); err != nil {
+			return err
+		}
+		if err := s.w.WriteString(symbol.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeTagImportAssets serializes an ImportAssets or ImportAssets2 tag,
+// according to its code
+func (s *serializer) SerializeTagImportAssets(t *TagImportAssets) error {
+	if err := s.w.WriteString(t.URL); err != nil {
+		return err
+	}
+	if t.Code() == CodeTagImportAssets2 {
+		// Reserved, first byte must be 1
+		if err := s.w.WriteUInt16(1); err != nil {
+			return err
+		}
+	}
+	return s.SerializeSymbols(t.Symbols)
+}
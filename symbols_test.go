@@ -0,0 +1,36 @@
+package swf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSymbolTags(t *testing.T) {
+	tagsBytes := []byte{
+		0x08, 0x0e, 0x01, 0x00, 0x05, 0x00, 0x45, 0x78, 0x70, 0x00, // ExportAssets
+		0x48, 0x0e, 0x61, 0x00, 0x01, 0x00, 0x06, 0x00, 0x49, 0x00, // ImportAssets
+		0xca, 0x11, 0x61, 0x00, 0x01, 0x00, 0x01, 0x00, 0x07, 0x00, 0x4a, 0x00, // ImportAssets2
+		0x0d, 0x13, 0x02, 0x00,
+		0x00, 0x00, 0x4d, 0x61, 0x69, 0x6e, 0x00,
+		0x05, 0x00, 0x43, 0x00, // SymbolClass
+		0x00, 0x00,
+	}
+	tags := roundTripTags(t, tagsBytes)
+
+	correct := []Tag{
+		&TagExportAssets{tag{code: CodeTagExportAssets, length: 8}, []Symbol{{5, "Exp"}}},
+		&TagImportAssets{tag{code: CodeTagImportAssets, length: 8}, "a", []Symbol{{6, "I"}}},
+		&TagImportAssets{tag{code: CodeTagImportAssets2, length: 10}, "a", []Symbol{{7, "J"}}},
+		&TagSymbolClass{tag{code: CodeTagSymbolClass, length: 13}, []Symbol{{0, "Main"}, {5, "C"}}},
+		&tag{code: CodeTagEnd, length: 0},
+	}
+	if !reflect.DeepEqual(tags, correct) {
+		t.Errorf("expected %v, got %v", correct, tags)
+	}
+
+	symbols := Swf{Tags: tags}.Symbols()
+	correctSymbols := map[uint16]string{0: "Main", 5: "C", 6: "I", 7: "J"}
+	if !reflect.DeepEqual(symbols, correctSymbols) {
+		t.Errorf("expected %v, got %v", correctSymbols, symbols)
+	}
+}
@@ -0,0 +1,123 @@
+package swf
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrFrameRange means that a frame is not part of a timeline
+var ErrFrameRange = errors.New("frame out of range")
+
+// DisplayObject is a character placed at a depth of the display list.
+// The zero Matrix and ColorTransform are the identity
+type DisplayObject struct {
+	Depth          uint16
+	CharacterID    uint16
+	Matrix         Matrix
+	ColorTransform ColorTransformWithAlpha
+	Name           string
+	ClipDepth      uint16
+}
+
+// Timeline replays the display list tags of a timeline.
+// Frames are numbered from 0, a frame ends with a ShowFrame tag
+type Timeline struct {
+	tags   []Tag
+	frames []int // frames holds the index of the ShowFrame tag ending each frame
+}
+
+// NewTimeline creates a timeline from the tags of a Swf file or a sprite
+func NewTimeline(tags []Tag) *Timeline {
+	t := &Timeline{tags: tags}
+	for i, tag := range tags {
+		if tag.Code() == CodeTagShowFrame {
+			t.frames = append(t.frames, i)
+		}
+	}
+	return t
+}
+
+// Timeline returns the main timeline of the file
+func (s Swf) Timeline() *Timeline {
+	return NewTimeline(s.Tags)
+}
+
+// FrameCount returns the number of frames of the timeline
+func (t *Timeline) FrameCount() int {
+	return len(t.frames)
+}
+
+// FrameLabels returns the frames labelled by the FrameLabel tags of the
+// timeline. When a label is used twice, the first frame wins
+func (t *Timeline) FrameLabels() map[string]int {
+	labels := make(map[string]int)
+	frame := 0
+	for _, tag := range t.tags {
+		switch tag := tag.(type) {
+		case *TagFrameLabel:
+			if _, found := labels[tag.Name]; !found {
+				labels[tag.Name] = frame
+			}
+		default:
+			if tag.Code() == CodeTagShowFrame {
+				frame++
+			}
+		}
+	}
+	return labels
+}
+
+// Frame returns the display list shown by frame n, ordered by depth
+func (t *Timeline) Frame(n int) ([]DisplayObject, error) {
+	if n < 0 || n >= len(t.frames) {
+		return nil, ErrFrameRange
+	}
+	list := make(map[uint16]DisplayObject)
+	for _, tag := range t.tags[:t.frames[n]] {
+		switch tag := tag.(type) {
+		case *TagPlaceObject:
+			placeObject(list, tag)
+		case *TagRemoveObject:
+			delete(list, tag.Depth)
+		}
+	}
+
+	objects := make([]DisplayObject, 0, len(list))
+	for _, o := range list {
+		objects = append(objects, o)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Depth < objects[j].Depth })
+	return objects, nil
+}
+
+// placeObject applies a PlaceObject tag to a display list. A moved
+// character keeps the properties the tag does not set, a new character
+// replaces the one at its depth
+func placeObject(list map[uint16]DisplayObject, t *TagPlaceObject) {
+	o, ok := list[t.Depth]
+	if !t.Move {
+		if !t.HasCharacter {
+			return
+		}
+		o = DisplayObject{Depth: t.Depth}
+	} else if !ok {
+		return
+	}
+
+	if t.HasCharacter {
+		o.CharacterID = t.CharacterID
+	}
+	if t.HasMatrix {
+		o.Matrix = t.Matrix
+	}
+	if t.HasColorTransform {
+		o.ColorTransform = t.ColorTransform
+	}
+	if t.HasName {
+		o.Name = t.Name
+	}
+	if t.HasClipDepth {
+		o.ClipDepth = t.ClipDepth
+	}
+	list[t.Depth] = o
+}
@@ -0,0 +1,48 @@
+package swf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTimeline(t *testing.T) {
+	moved := Matrix{NTranslateBits: 8, TranslateX: 100, TranslateY: 100}
+	tags := []Tag{
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject2, length: 0}, HasCharacter: true, HasName: true, Depth: 2, CharacterID: 1, Name: "a"},
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject, length: 0}, HasCharacter: true, HasMatrix: true, Depth: 1, CharacterID: 2},
+		&tag{code: CodeTagShowFrame, length: 0},
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject2, length: 0}, Move: true, HasMatrix: true, Depth: 2, Matrix: moved},
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject2, length: 0}, HasCharacter: true, HasClipDepth: true, Depth: 3, CharacterID: 3, ClipDepth: 5},
+		&TagRemoveObject{tag{code: CodeTagRemoveObject2, length: 0}, 0, 1},
+		&tag{code: CodeTagShowFrame, length: 0},
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject2, length: 0}, Move: true, HasCharacter: true, Depth: 2, CharacterID: 4},
+		&TagPlaceObject{tag: tag{code: CodeTagPlaceObject2, length: 0}, Move: true, HasMatrix: true, Depth: 7, Matrix: moved},
+		&tag{code: CodeTagShowFrame, length: 0},
+		&TagRemoveObject{tag{code: CodeTagRemoveObject2, length: 0}, 0, 2},
+	}
+	timeline := Swf{Tags: tags}.Timeline()
+	if timeline.FrameCount() != 3 {
+		t.Fatalf("expected 3, got %v", timeline.FrameCount())
+	}
+
+	expected := [][]DisplayObject{
+		{{Depth: 1, CharacterID: 2}, {Depth: 2, CharacterID: 1, Name: "a"}},
+		{{Depth: 2, CharacterID: 1, Matrix: moved, Name: "a"}, {Depth: 3, CharacterID: 3, ClipDepth: 5}},
+		{{Depth: 2, CharacterID: 4, Matrix: moved, Name: "a"}, {Depth: 3, CharacterID: 3, ClipDepth: 5}},
+	}
+	for i, e := range expected {
+		frame, err := timeline.Frame(i)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if !reflect.DeepEqual(frame, e) {
+			t.Errorf("expected %#v, got %#v", e, frame)
+		}
+	}
+
+	for _, n := range []int{-1, 3} {
+		if _, err := timeline.Frame(n); err != ErrFrameRange {
+			t.Errorf("expected %v, got %v", ErrFrameRange, err)
+		}
+	}
+}
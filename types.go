@@ -4,19 +4,59 @@ package swf
 const (
 	CompressionNone = iota
 	CompressionZlib
-	CompressionLZMA // Currently not implemented
+	CompressionLZMA
 )
 
 // These represent code of handled Swf tags
 const (
-	CodeTagEnd   = 0  // CodeTagEnd is the code representing a Tag of type End
-	CodeTagDoABC = 82 // CodeTagDoABC is the code representing a Tag of type DoABC
+	CodeTagEnd                          = 0  // CodeTagEnd is the code representing a Tag of type End
+	CodeTagShowFrame                    = 1  // CodeTagShowFrame is the code representing a Tag of type ShowFrame
+	CodeTagDefineShape                  = 2  // CodeTagDefineShape is the code representing a Tag of type DefineShape
+	CodeTagPlaceObject                  = 4  // CodeTagPlaceObject is the code representing a Tag of type PlaceObject
+	CodeTagRemoveObject                 = 5  // CodeTagRemoveObject is the code representing a Tag of type RemoveObject
+	CodeTagDefineBits                   = 6  // CodeTagDefineBits is the code representing a Tag of type DefineBits
+	CodeTagJPEGTables                   = 8  // CodeTagJPEGTables is the code representing a Tag of type JPEGTables
+	CodeTagSetBackgroundColor           = 9  // CodeTagSetBackgroundColor is the code representing a Tag of type SetBackgroundColor
+	CodeTagDoAction                     = 12 // CodeTagDoAction is the code representing a Tag of type DoAction
+	CodeTagDefineSound                  = 14 // CodeTagDefineSound is the code representing a Tag of type DefineSound
+	CodeTagStartSound                   = 15 // CodeTagStartSound is the code representing a Tag of type StartSound
+	CodeTagSoundStreamHead              = 18 // CodeTagSoundStreamHead is the code representing a Tag of type SoundStreamHead
+	CodeTagSoundStreamBlock             = 19 // CodeTagSoundStreamBlock is the code representing a Tag of type SoundStreamBlock
+	CodeTagDefineBitsLossless           = 20 // CodeTagDefineBitsLossless is the code representing a Tag of type DefineBitsLossless
+	CodeTagDefineBitsJPEG2              = 21 // CodeTagDefineBitsJPEG2 is the code representing a Tag of type DefineBitsJPEG2
+	CodeTagDefineShape2                 = 22 // CodeTagDefineShape2 is the code representing a Tag of type DefineShape2
+	CodeTagPlaceObject2                 = 26 // CodeTagPlaceObject2 is the code representing a Tag of type PlaceObject2
+	CodeTagRemoveObject2                = 28 // CodeTagRemoveObject2 is the code representing a Tag of type RemoveObject2
+	CodeTagDefineShape3                 = 32 // CodeTagDefineShape3 is the code representing a Tag of type DefineShape3
+	CodeTagDefineBitsJPEG3              = 35 // CodeTagDefineBitsJPEG3 is the code representing a Tag of type DefineBitsJPEG3
+	CodeTagDefineBitsLossless2          = 36 // CodeTagDefineBitsLossless2 is the code representing a Tag of type DefineBitsLossless2
+	CodeTagDefineSprite                 = 39 // CodeTagDefineSprite is the code representing a Tag of type DefineSprite
+	CodeTagFrameLabel                   = 43 // CodeTagFrameLabel is the code representing a Tag of type FrameLabel
+	CodeTagSoundStreamHead2             = 45 // CodeTagSoundStreamHead2 is the code representing a Tag of type SoundStreamHead2
+	CodeTagExportAssets                 = 56 // CodeTagExportAssets is the code representing a Tag of type ExportAssets
+	CodeTagImportAssets                 = 57 // CodeTagImportAssets is the code representing a Tag of type ImportAssets
+	CodeTagDoInitAction                 = 59 // CodeTagDoInitAction is the code representing a Tag of type DoInitAction
+	CodeTagVideoFrame                   = 61 // CodeTagVideoFrame is the code representing a Tag of type VideoFrame
+	CodeTagScriptLimits                 = 65 // CodeTagScriptLimits is the code representing a Tag of type ScriptLimits
+	CodeTagFileAttributes               = 69 // CodeTagFileAttributes is the code representing a Tag of type FileAttributes
+	CodeTagPlaceObject3                 = 70 // CodeTagPlaceObject3 is the code representing a Tag of type PlaceObject3
+	CodeTagImportAssets2                = 71 // CodeTagImportAssets2 is the code representing a Tag of type ImportAssets2
+	CodeTagSymbolClass                  = 76 // CodeTagSymbolClass is the code representing a Tag of type SymbolClass
+	CodeTagMetadata                     = 77 // CodeTagMetadata is the code representing a Tag of type Metadata
+	CodeTagDoABC                        = 82 // CodeTagDoABC is the code representing a Tag of type DoABC
+	CodeTagDefineShape4                 = 83 // CodeTagDefineShape4 is the code representing a Tag of type DefineShape4
+	CodeTagDefineSceneAndFrameLabelData = 86 // CodeTagDefineSceneAndFrameLabelData is the code representing a Tag of type DefineSceneAndFrameLabelData
+	CodeTagDefineBinaryData             = 87 // CodeTagDefineBinaryData is the code representing a Tag of type DefineBinaryData
+	CodeTagStartSound2                  = 89 // CodeTagStartSound2 is the code representing a Tag of type StartSound2
+	CodeTagDefineBitsJPEG4              = 90 // CodeTagDefineBitsJPEG4 is the code representing a Tag of type DefineBitsJPEG4
 )
 
-// Swf represents a Swf file deserialized
+// Swf represents a Swf file deserialized.
+// Warnings holds the errors recovered from in lenient mode
 type Swf struct {
-	Header Header
-	Tags   []Tag
+	Header   Header
+	Tags     []Tag
+	Warnings []error
 }
 
 // Header represents a Swf file's header
@@ -38,6 +78,7 @@ type Tag interface {
 type tag struct {
 	code   uint16
 	length uint32
+	long   bool // long is true when the tag was stored with a long RECORDHEADER
 }
 
 // TagDoABC represents a DoABC Tag
@@ -48,9 +89,477 @@ type TagDoABC struct {
 	ABCData []byte
 }
 
+// TagFileAttributes represents a FileAttributes Tag
+type TagFileAttributes struct {
+	tag
+	UseDirectBlit bool
+	UseGPU        bool
+	HasMetadata   bool
+	ActionScript3 bool
+	UseNetwork    bool
+	ReservedFlags uint32 // ReservedFlags holds the other bits of the 32 bits flags, written back as is
+}
+
+// TagSetBackgroundColor represents a SetBackgroundColor Tag
+type TagSetBackgroundColor struct {
+	tag
+	BackgroundColor RGB
+}
+
+// TagMetadata represents a Metadata Tag, Metadata is an XML document
+type TagMetadata struct {
+	tag
+	Metadata string
+}
+
+// TagScriptLimits represents a ScriptLimits Tag
+type TagScriptLimits struct {
+	tag
+	MaxRecursionDepth    uint16
+	ScriptTimeoutSeconds uint16
+}
+
+// Symbol binds a character ID to a name
+type Symbol struct {
+	CharacterID uint16
+	Name        string
+}
+
+// TagSymbolClass represents a SymbolClass Tag.
+// It binds characters to ActionScript 3 classes
+type TagSymbolClass struct {
+	tag
+	Symbols []Symbol
+}
+
+// TagExportAssets represents an ExportAssets Tag
+type TagExportAssets struct {
+	tag
+	Symbols []Symbol
+}
+
+// TagImportAssets represents an ImportAssets or an ImportAssets2 Tag,
+// depending on its code. Symbols are imported from the Swf file at URL
+type TagImportAssets struct {
+	tag
+	URL     string
+	Symbols []Symbol
+}
+
+// TagDefineBinaryData represents a DefineBinaryData Tag.
+// Swf is the embedded Swf file held by Data, it is only parsed with the
+// ParseEmbedded option, and up to 8 nested files. Data is written as is:
+// use Write to update it after modifying Swf
+type TagDefineBinaryData struct {
+	tag
+	CharacterID uint16
+	Data        []byte
+	Swf         *Swf
+}
+
+// TagDefineBits represents a DefineBits Tag.
+// JPEGTables holds the data of the JPEGTables tag of the file, it is
+// required to decode JPEGData and is not serialized with the tag
+type TagDefineBits struct {
+	tag
+	CharacterID uint16
+	JPEGData    []byte
+	JPEGTables  []byte
+}
+
+// TagJPEGTables represents a JPEGTables Tag.
+// It holds the encoding tables shared by the DefineBits tags of the file
+type TagJPEGTables struct {
+	tag
+	JPEGData []byte
+}
+
+// TagDefineBitsJPEG2 represents a DefineBitsJPEG2 Tag
+type TagDefineBitsJPEG2 struct {
+	tag
+	CharacterID uint16
+	ImageData   []byte
+}
+
+// TagDefineBitsJPEG3 represents a DefineBitsJPEG3 or a DefineBitsJPEG4 Tag,
+// depending on its code. BitmapAlphaData is the zlib compressed alpha
+// channel of the JPEG data. DeblockParam is only used by DefineBitsJPEG4
+type TagDefineBitsJPEG3 struct {
+	tag
+	CharacterID     uint16
+	DeblockParam    float32
+	ImageData       []byte
+	BitmapAlphaData []byte
+}
+
+// TagDefineBitsLossless represents a DefineBitsLossless or a
+// DefineBitsLossless2 Tag, depending on its code.
+// BitmapColorTableSize is the number of colors minus one, it is only used
+// by colormapped bitmaps
+type TagDefineBitsLossless struct {
+	tag
+	CharacterID          uint16
+	BitmapFormat         uint8
+	BitmapWidth          uint16
+	BitmapHeight         uint16
+	BitmapColorTableSize uint8
+	ZlibBitmapData       []byte
+}
+
+// TagDefineShape represents a DefineShape, DefineShape2, DefineShape3 or
+// DefineShape4 Tag, depending on its code. EdgeBounds and the stroke flags
+// are only used by DefineShape4
+type TagDefineShape struct {
+	tag
+	ShapeID               uint16
+	ShapeBounds           Rect
+	EdgeBounds            Rect
+	UsesFillWindingRule   bool
+	UsesNonScalingStrokes bool
+	UsesScalingStrokes    bool
+	Shapes                ShapeWithStyle
+}
+
+// TagPlaceObject represents a PlaceObject, PlaceObject2 or PlaceObject3 Tag,
+// depending on its code. The Has flags tell which fields are used, a field
+// that is not set is left unchanged on the display list.
+// PlaceObject always has a character and a matrix, its color transform has
+// no alpha. The flags and fields following ClipDepth are only used by
+// PlaceObject3, ClipActions is only used since PlaceObject2
+type TagPlaceObject struct {
+	tag
+	Move              bool // Move is true when the tag modifies the character at Depth
+	HasCharacter      bool
+	HasMatrix         bool
+	HasColorTransform bool
+	HasRatio          bool
+	HasName           bool
+	HasClipDepth      bool
+	HasClipActions    bool
+	HasClassName      bool
+	HasImage          bool
+	HasFilterList     bool
+	HasBlendMode      bool
+	HasCacheAsBitmap  bool
+	HasVisible        bool
+	OpaqueBackground  bool
+	Depth             uint16
+	ClassName         string
+	CharacterID       uint16
+	Matrix            Matrix
+	ColorTransform    ColorTransformWithAlpha
+	Ratio             uint16
+	Name              string
+	ClipDepth         uint16
+	SurfaceFilterList []Filter
+	BlendMode         uint8
+	BitmapCache       uint8
+	Visible           uint8
+	BackgroundColor   RGBA
+	ClipActions       ClipActions
+}
+
+// TagRemoveObject represents a RemoveObject or a RemoveObject2 Tag,
+// depending on its code. CharacterID is only used by RemoveObject
+type TagRemoveObject struct {
+	tag
+	CharacterID uint16
+	Depth       uint16
+}
+
+// TagFrameLabel represents a FrameLabel Tag.
+// NamedAnchor is only stored since Swf 6
+type TagFrameLabel struct {
+	tag
+	Name        string
+	NamedAnchor bool
+}
+
+// TagDefineSceneAndFrameLabelData represents a DefineSceneAndFrameLabelData Tag
+type TagDefineSceneAndFrameLabelData struct {
+	tag
+	Scenes      []Scene
+	FrameLabels []FrameLabel
+}
+
+// Scene is a scene of a DefineSceneAndFrameLabelData tag.
+// Offset is the first frame of the scene, numbered from 0
+type Scene struct {
+	Offset uint32
+	Name   string
+}
+
+// FrameLabel is a label of a DefineSceneAndFrameLabelData tag.
+// FrameNum is the labelled frame, numbered from 0
+type FrameLabel struct {
+	FrameNum uint32
+	Name     string
+}
+
+// TagDoAction represents a DoAction Tag.
+// ActionData holds the action records run by the frame, see Actions
+type TagDoAction struct {
+	tag
+	ActionData []byte
+}
+
+// TagDoInitAction represents a DoInitAction Tag.
+// ActionData holds the action records initializing the sprite, see Actions
+type TagDoInitAction struct {
+	tag
+	SpriteID   uint16
+	ActionData []byte
+}
+
+// Action represents an ACTIONRECORD. Data holds the payload of the actions
+// whose code is 0x80 or more, Payload is its decoded form for the actions
+// that have one
+type Action struct {
+	Offset  uint32 // Offset is the byte offset of the action in the code
+	Code    uint8
+	Data    []byte
+	Payload ActionPayload
+}
+
+// ActionPayload is implemented by the decoded payloads of the actions
+type ActionPayload interface {
+	actionPayload()
+}
+
+// ActionGotoFrame represents the payload of an ActionGotoFrame
+type ActionGotoFrame struct {
+	Frame uint16
+}
+
+// ActionGetURL represents the payload of an ActionGetURL
+type ActionGetURL struct {
+	URL    string
+	Target string
+}
+
+// ActionStoreRegister represents the payload of an ActionStoreRegister
+type ActionStoreRegister struct {
+	Register uint8
+}
+
+// ActionConstantPool represents the payload of an ActionConstantPool.
+// The constants are referenced by the Constant values of ActionPush
+type ActionConstantPool struct {
+	Constants []string
+}
+
+// ActionWaitForFrame represents the payload of an ActionWaitForFrame
+type ActionWaitForFrame struct {
+	Frame     uint16
+	SkipCount uint8
+}
+
+// ActionSetTarget represents the payload of an ActionSetTarget
+type ActionSetTarget struct {
+	TargetName string
+}
+
+// ActionGoToLabel represents the payload of an ActionGoToLabel
+type ActionGoToLabel struct {
+	Label string
+}
+
+// ActionWaitForFrame2 represents the payload of an ActionWaitForFrame2
+type ActionWaitForFrame2 struct {
+	SkipCount uint8
+}
+
+// ActionDefineFunction2 represents the payload of an ActionDefineFunction2.
+// Flags holds Function values. The body of the function is made of the
+// CodeSize bytes that follow the action
+type ActionDefineFunction2 struct {
+	FunctionName  string
+	RegisterCount uint8
+	Flags         uint16
+	Parameters    []RegisterParam
+	CodeSize      uint16
+}
+
+// RegisterParam is a parameter of an ActionDefineFunction2. Register is the
+// register the parameter is stored in, 0 when it is only a variable
+type RegisterParam struct {
+	Register  uint8
+	ParamName string
+}
+
+// ActionTry represents the payload of an ActionTry. The try, catch and
+// finally blocks are made of the bytes that follow the action, in order.
+// CatchName is used unless CatchInRegister is set
+type ActionTry struct {
+	CatchInRegister bool
+	HasFinallyBlock bool
+	HasCatchBlock   bool
+	TrySize         uint16
+	CatchSize       uint16
+	FinallySize     uint16
+	CatchName       string
+	CatchRegister   uint8
+}
+
+// ActionWith represents the payload of an ActionWith. The block of the
+// action is made of the Size bytes that follow it
+type ActionWith struct {
+	Size uint16
+}
+
+// ActionPush represents the payload of an ActionPush
+type ActionPush struct {
+	Values []PushValue
+}
+
+// PushValue is a value of an ActionPush. The field used depends on Type,
+// Constant is an index in the last ActionConstantPool
+type PushValue struct {
+	Type     uint8
+	String   string
+	Float    float32
+	Register uint8
+	Boolean  bool
+	Double   float64
+	Integer  int32
+	Constant uint16
+}
+
+// ActionBranch represents the payload of an ActionJump or an ActionIf.
+// Target is the offset of the action BranchOffset jumps to
+type ActionBranch struct {
+	BranchOffset int16
+	Target       uint32
+}
+
+// ActionGetURL2 represents the payload of an ActionGetURL2
+type ActionGetURL2 struct {
+	SendVarsMethod uint8
+	LoadTarget     bool
+	LoadVariables  bool
+}
+
+// ActionDefineFunction represents the payload of an ActionDefineFunction.
+// The body of the function is made of the CodeSize bytes that follow the
+// action
+type ActionDefineFunction struct {
+	FunctionName string
+	Params       []string
+	CodeSize     uint16
+}
+
+// ActionGotoFrame2 represents the payload of an ActionGotoFrame2
+type ActionGotoFrame2 struct {
+	SceneBiasFlag bool
+	Play          bool
+	SceneBias     uint16
+}
+
+// SoundFormat describes the samples of a sound. Compression is a
+// SoundCompression value and Rate a SoundRate value
+type SoundFormat struct {
+	Compression uint8
+	Rate        uint8
+	Is16Bits    bool
+	Stereo      bool
+}
+
+// TagDefineSound represents a DefineSound Tag.
+// SampleCount is the number of samples, pairs of samples when stereo
+type TagDefineSound struct {
+	tag
+	SoundID     uint16
+	Format      SoundFormat
+	SampleCount uint32
+	SoundData   []byte
+}
+
+// TagStartSound represents a StartSound or a StartSound2 Tag, depending on
+// its code. SoundID is only used by StartSound, SoundClassName by
+// StartSound2
+type TagStartSound struct {
+	tag
+	SoundID        uint16
+	SoundClassName string
+	SoundInfo      SoundInfo
+}
+
+// SoundInfo represents a SoundInfo record.
+// InPoint, OutPoint, LoopCount and EnvelopeRecords are only used when
+// their flag is set
+type SoundInfo struct {
+	SyncStop        bool
+	SyncNoMultiple  bool
+	HasEnvelope     bool
+	HasLoops        bool
+	HasOutPoint     bool
+	HasInPoint      bool
+	InPoint         uint32
+	OutPoint        uint32
+	LoopCount       uint16
+	EnvelopeRecords []SoundEnvelope
+}
+
+// SoundEnvelope represents a SoundEnvelope record.
+// Pos44 is a position in 44kHz samples
+type SoundEnvelope struct {
+	Pos44      uint32
+	LeftLevel  uint16
+	RightLevel uint16
+}
+
+// TagSoundStreamHead represents a SoundStreamHead or a SoundStreamHead2 Tag,
+// depending on its code. The Compression of PlaybackFormat is not stored.
+// StreamSampleCount is the average number of samples of each
+// SoundStreamBlock, LatencySeek is only used by MP3 streams and some of them
+// omit it
+type TagSoundStreamHead struct {
+	tag
+	PlaybackFormat    SoundFormat
+	StreamFormat      SoundFormat
+	StreamSampleCount uint16
+	HasLatencySeek    bool
+	LatencySeek       int16
+}
+
+// TagSoundStreamBlock represents a SoundStreamBlock Tag.
+// It holds the samples of the stream for one frame
+type TagSoundStreamBlock struct {
+	tag
+	StreamSoundData []byte
+}
+
+// TagDefineSprite represents a DefineSprite Tag.
+// Tags holds the control tags of the sprite, ending with its End tag
+type TagDefineSprite struct {
+	tag
+	SpriteID   uint16
+	FrameCount uint16
+	Tags       []Tag
+}
+
+// UnknownTag represents a Tag that is not decoded by the library.
+// Its payload is kept untouched, so it can be handled by the caller
+// and written back as is
+type UnknownTag struct {
+	tag
+	LongHeader bool // LongHeader is true when the tag was stored with a long RECORDHEADER
+	Data       []byte
+}
+
 func (t *tag) Code() uint16   { return t.code }
 func (t *tag) Length() uint32 { return t.length }
 
+// longHeaderTag is implemented by the tags embedding tag, whose RECORDHEADER
+// form is kept
+type longHeaderTag interface {
+	longHeader() bool
+	setLongHeader(long bool)
+}
+
+func (t *tag) longHeader() bool        { return t.long }
+func (t *tag) setLongHeader(long bool) { t.long = long }
+
 // Rect represents a Rectangle record
 type Rect struct {
 	NBits uint8
@@ -59,3 +568,283 @@ type Rect struct {
 	Ymin  int32
 	Ymax  int32
 }
+
+// RGB represents a RGB color record
+type RGB struct {
+	Red   uint8
+	Green uint8
+	Blue  uint8
+}
+
+// RGBA represents a RGBA color record
+type RGBA struct {
+	Red   uint8
+	Green uint8
+	Blue  uint8
+	Alpha uint8
+}
+
+// Matrix represents a Matrix record.
+// The scale and rotate values are 16.16 fixed point numbers, they are only
+// written when HasScale and HasRotate are set, so the zero Matrix is the
+// identity matrix. The bit counts are increased when they are too small to
+// hold the values
+type Matrix struct {
+	HasScale       bool
+	NScaleBits     uint8
+	ScaleX         float64
+	ScaleY         float64
+	HasRotate      bool
+	NRotateBits    uint8
+	RotateSkew0    float64
+	RotateSkew1    float64
+	NTranslateBits uint8
+	TranslateX     int32
+	TranslateY     int32
+}
+
+// ColorTransform represents a ColorTransform record.
+// The multiplication terms are 8.8 fixed point numbers stored as is, 256 is
+// 1.0. The terms are only written when HasMultTerms and HasAddTerms are set.
+// NBits is increased when it is too small to hold the terms
+type ColorTransform struct {
+	HasAddTerms   bool
+	HasMultTerms  bool
+	NBits         uint8
+	RedMultTerm   int16
+	GreenMultTerm int16
+	BlueMultTerm  int16
+	RedAddTerm    int16
+	GreenAddTerm  int16
+	BlueAddTerm   int16
+}
+
+// ColorTransformWithAlpha represents a ColorTransformWithAlpha record, a
+// ColorTransform that also transforms the alpha channel
+type ColorTransformWithAlpha struct {
+	HasAddTerms   bool
+	HasMultTerms  bool
+	NBits         uint8
+	RedMultTerm   int16
+	GreenMultTerm int16
+	BlueMultTerm  int16
+	AlphaMultTerm int16
+	RedAddTerm    int16
+	GreenAddTerm  int16
+	BlueAddTerm   int16
+	AlphaAddTerm  int16
+}
+
+// FillStyle represents a FillStyle record.
+// Colors of DefineShape and DefineShape2 have no alpha, it is set to 0xff
+type FillStyle struct {
+	FillStyleType  uint8
+	Color          RGBA
+	GradientMatrix Matrix
+	Gradient       Gradient
+	BitmapID       uint16
+	BitmapMatrix   Matrix
+}
+
+// Gradient represents a Gradient or a FocalGradient record.
+// FocalPoint is only used by focal radial gradients
+type Gradient struct {
+	SpreadMode        uint8
+	InterpolationMode uint8
+	GradientRecords   []GradRecord
+	FocalPoint        float32
+}
+
+// GradRecord represents a GradRecord record
+type GradRecord struct {
+	Ratio uint8
+	Color RGBA
+}
+
+// LineStyle represents a LineStyle or a LineStyle2 record.
+// LineStyle2 is used by DefineShape4, its line is filled with FillType
+// instead of Color when HasFill is set
+type LineStyle struct {
+	Width            uint16
+	Color            RGBA
+	StartCapStyle    uint8
+	JoinStyle        uint8
+	HasFill          bool
+	NoHScale         bool
+	NoVScale         bool
+	PixelHinting     bool
+	NoClose          bool
+	EndCapStyle      uint8
+	MiterLimitFactor float32
+	FillType         FillStyle
+}
+
+// ShapeWithStyle represents a ShapeWithStyle record.
+// The style indexes of the records are 1-based, 0 means no style
+type ShapeWithStyle struct {
+	FillStyles   []FillStyle
+	LineStyles   []LineStyle
+	NumFillBits  uint8
+	NumLineBits  uint8
+	ShapeRecords []ShapeRecord
+}
+
+// ShapeRecord is implemented by StyleChangeRecord, StraightEdgeRecord and
+// CurvedEdgeRecord. The EndShapeRecord is implied by the end of the list
+type ShapeRecord interface {
+	shapeRecord()
+}
+
+// StyleChangeRecord represents a StyleChangeRecord record.
+// The State flags tell which fields are used. FillStyles and LineStyles
+// replace the styles of the shape when StateNewStyles is set, NumFillBits and
+// NumLineBits then apply to the following records
+type StyleChangeRecord struct {
+	StateNewStyles  bool
+	StateLineStyle  bool
+	StateFillStyle1 bool
+	StateFillStyle0 bool
+	StateMoveTo     bool
+	MoveBits        uint8
+	MoveDeltaX      int32
+	MoveDeltaY      int32
+	FillStyle0      uint32
+	FillStyle1      uint32
+	LineStyle       uint32
+	FillStyles      []FillStyle
+	LineStyles      []LineStyle
+	NumFillBits     uint8
+	NumLineBits     uint8
+}
+
+// StraightEdgeRecord represents a StraightEdgeRecord record.
+// NumBits is 2 less than the number of bits of the deltas. The general and
+// vertical line flags are computed from the deltas
+type StraightEdgeRecord struct {
+	NumBits uint8
+	DeltaX  int32
+	DeltaY  int32
+}
+
+// CurvedEdgeRecord represents a CurvedEdgeRecord record, a quadratic Bezier
+// curve. NumBits is 2 less than the number of bits of the deltas
+type CurvedEdgeRecord struct {
+	NumBits       uint8
+	ControlDeltaX int32
+	ControlDeltaY int32
+	AnchorDeltaX  int32
+	AnchorDeltaY  int32
+}
+
+func (*StyleChangeRecord) shapeRecord()  {}
+func (*StraightEdgeRecord) shapeRecord() {}
+func (*CurvedEdgeRecord) shapeRecord()   {}
+
+// ClipActions represents a ClipActions record.
+// The event flags are ClipEvent values
+type ClipActions struct {
+	AllEventFlags     uint32
+	ClipActionRecords []ClipActionRecord
+}
+
+// ClipActionRecord represents a ClipActionRecord record. KeyCode is only
+// used by the ClipEventKeyPress event. Actions holds the action records
+// run on the events, they are decoded by DecodeActions
+type ClipActionRecord struct {
+	EventFlags uint32
+	KeyCode    uint8
+	Actions    []byte
+}
+
+// Filter is implemented by the filters of a FilterList record
+type Filter interface {
+	FilterID() uint8
+}
+
+// DropShadowFilter represents a DropShadowFilter record
+type DropShadowFilter struct {
+	DropShadowColor RGBA
+	BlurX           float32
+	BlurY           float32
+	Angle           float32
+	Distance        float32
+	Strength        float32
+	InnerShadow     bool
+	Knockout        bool
+	CompositeSource bool
+	Passes          uint8
+}
+
+// BlurFilter represents a BlurFilter record
+type BlurFilter struct {
+	BlurX  float32
+	BlurY  float32
+	Passes uint8
+}
+
+// GlowFilter represents a GlowFilter record
+type GlowFilter struct {
+	GlowColor       RGBA
+	BlurX           float32
+	BlurY           float32
+	Strength        float32
+	InnerGlow       bool
+	Knockout        bool
+	CompositeSource bool
+	Passes          uint8
+}
+
+// BevelFilter represents a BevelFilter record
+type BevelFilter struct {
+	ShadowColor     RGBA
+	HighlightColor  RGBA
+	BlurX           float32
+	BlurY           float32
+	Angle           float32
+	Distance        float32
+	Strength        float32
+	InnerShadow     bool
+	Knockout        bool
+	CompositeSource bool
+	OnTop           bool
+	Passes          uint8
+}
+
+// GradientGlowFilter represents a GradientGlowFilter record.
+// GradientColors and GradientRatio have the same length
+type GradientGlowFilter struct {
+	GradientColors  []RGBA
+	GradientRatio   []uint8
+	BlurX           float32
+	BlurY           float32
+	Angle           float32
+	Distance        float32
+	Strength        float32
+	InnerShadow     bool
+	Knockout        bool
+	CompositeSource bool
+	OnTop           bool
+	Passes          uint8
+}
+
+// GradientBevelFilter represents a GradientBevelFilter record, it has the
+// fields of a GradientGlowFilter
+type GradientBevelFilter GradientGlowFilter
+
+// ConvolutionFilter represents a ConvolutionFilter record.
+// Matrix holds MatrixX * MatrixY values, row by row
+type ConvolutionFilter struct {
+	MatrixX       uint8
+	MatrixY       uint8
+	Divisor       float32
+	Bias          float32
+	Matrix        []float32
+	DefaultColor  RGBA
+	Clamp         bool
+	PreserveAlpha bool
+}
+
+// ColorMatrixFilter represents a ColorMatrixFilter record
+type ColorMatrixFilter struct {
+	Matrix [20]float32
+}
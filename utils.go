@@ -1,6 +1,7 @@
 package swf
 
 import (
+	"bytes"
 	"io"
 )
 
@@ -19,3 +20,18 @@ func (r *simpleByteReadSeeker) ReadByte() (byte, error) {
 	}
 	return b[0], nil
 }
+
+// payloadReader reads the payload of a tag. Its positions are the offsets
+// of the payload in the file rather than in the payload
+type payloadReader struct {
+	*bytes.Reader
+	base int64
+}
+
+func (r *payloadReader) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekStart {
+		offset -= r.base
+	}
+	pos, err := r.Reader.Seek(offset, whence)
+	return pos + r.base, err
+}
@@ -0,0 +1,215 @@
+package swf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/icza/bitio"
+)
+
+// Writer is the minimal interface required to write a swf
+type Writer interface {
+	io.Writer
+	WriteByte(c byte) error
+	WriteBits(v uint32, n uint) error
+	WriteInt8(v int8) error
+	WriteInt16(v int16) error
+	WriteInt32(v int32) error
+	WriteUInt8(v uint8) error
+	WriteUInt16(v uint16) error
+	WriteUInt32(v uint32) error
+	WriteEUInt32(v uint32) error
+	WriteBitValue(v int32, n uint8) error
+	WriteUBitValue(v uint32, n uint8) error
+	WriteFixed(v float32) error
+	WriteFixed8(v float32) error
+	WriteString(s string) error
+	WriteRect(r Rect) error
+	Flush() error
+}
+
+type writer struct {
+	bitio.Writer
+}
+
+// NewWriter provides a simple way to create a Writer from a given io.Writer
+func NewWriter(w io.Writer) Writer {
+	return &writer{bitio.NewWriter(w)}
+}
+
+// WriteBits writes the n least significant bits of v
+func (w *writer) WriteBits(v uint32, n uint) error {
+	return w.Writer.WriteBits(uint64(v), byte(n))
+}
+
+// Flush pads the pending bits with zeros up to the next byte boundary
+func (w *writer) Flush() error {
+	_, err := w.Writer.Align()
+	return err
+}
+
+func (w *writer) write(d interface{}) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, d)
+}
+
+// WriteInt8 writes a signed int 8 to a io.Writer
+func (w *writer) WriteInt8(v int8) error {
+	return w.write(v)
+}
+
+// WriteInt16 writes a signed int 16 to a io.Writer
+func (w *writer) WriteInt16(v int16) error {
+	return w.write(v)
+}
+
+// WriteInt32 writes a signed int 32 to a io.Writer
+func (w *writer) WriteInt32(v int32) error {
+	return w.write(v)
+}
+
+// WriteUInt8 writes a single unsigned byte to a io.Writer
+func (w *writer) WriteUInt8(v uint8) error {
+	return w.write(v)
+}
+
+// WriteUInt16 writes an unsigned int 16 to a io.Writer
+func (w *writer) WriteUInt16(v uint16) error {
+	return w.write(v)
+}
+
+// WriteUInt32 writes an unsigned int 32 to a io.Writer
+func (w *writer) WriteUInt32(v uint32) error {
+	return w.write(v)
+}
+
+// WriteEUInt32 writes a swf encoded unsigned int 32 to a io.Writer
+// Each byte holds 7 bits of the value, the most significant bit is set
+// while more bytes follow
+func (w *writer) WriteEUInt32(v uint32) error {
+	for {
+		b := uint8(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		if err := w.WriteUInt8(b); err != nil {
+			return err
+		}
+		if v == 0 {
+			return nil
+		}
+	}
+}
+
+// WriteUBitValue writes a swf encoded unsigned bit value with n bits
+// to a swf.Writer
+func (w *writer) WriteUBitValue(v uint32, n uint8) error {
+	if n > 32 || n == 0 {
+		return errors.New("bit value is 1-32 bits")
+	}
+	if n < 32 && v>>n != 0 {
+		return errors.New("bit value overflows its bit count")
+	}
+	return w.WriteBits(v, uint(n))
+}
+
+// WriteBitValue writes a swf encoded signed bit value with n bits
+// to a swf.Writer
+func (w *writer) WriteBitValue(v int32, n uint8) error {
+	if n > 32 || n == 0 {
+		return errors.New("bit value is 1-32 bits")
+	}
+	if signedBits(v) > n {
+		return errors.New("bit value overflows its bit count")
+	}
+	return w.WriteBits(uint32(v)&(0xffffffff>>(32-n)), uint(n))
+}
+
+// WriteFixed writes a swf encoded fixed point number to a io.Writer
+// Each part of the fixed point number is 16 bits
+func (w *writer) WriteFixed(v float32) error {
+	return w.WriteUInt32(uint32(int64(math.Floor(float64(v) * 65536))))
+}
+
+// WriteFixed8 writes a swf encoded fixed point number to a io.Writer.
+// Each part of the fixed point number is 8 bits
+func (w *writer) WriteFixed8(v float32) error {
+	before := int8(v)
+	after := float64(v) - float64(before)
+	if after < 0 {
+		after = -after
+	}
+	if err := w.WriteUInt8(uint8(math.Floor(after * 256))); err != nil {
+		return err
+	}
+	return w.WriteInt8(before)
+}
+
+// WriteString writes a null terminated string to a io.Writer
+func (w *writer) WriteString(s string) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		return err
+	}
+	return w.WriteUInt8(0)
+}
+
+// WriteRect writes a Rectangle record.
+// NBits is increased when it is too small to hold the coordinates
+func (w *writer) WriteRect(r Rect) error {
+	nBits := signedBits(r.Xmin, r.Xmax, r.Ymin, r.Ymax)
+	if r.NBits > nBits {
+		nBits = r.NBits
+	}
+	if err := w.WriteUBitValue(uint32(nBits), 5); err != nil {
+		return err
+	}
+	for _, v := range []int32{r.Xmin, r.Xmax, r.Ymin, r.Ymax} {
+		if err := w.WriteBitValue(v, nBits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signedBits returns the minimum number of bits required to write
+// every given value as a signed bit value
+func signedBits(values ...int32) uint8 {
+	n := uint8(1)
+	for _, v := range values {
+		if v < 0 {
+			v = ^v
+		}
+		bits := uint8(1)
+		for ; v != 0; v >>= 1 {
+			bits++
+		}
+		if bits > n {
+			n = bits
+		}
+	}
+	return n
+}
+
+// unsignedBits returns the minimum number of bits required to write
+// every given value as an unsigned bit value
+func unsignedBits(values ...uint32) uint8 {
+	n := uint8(0)
+	for _, v := range values {
+		bits := uint8(0)
+		for ; v != 0; v >>= 1 {
+			bits++
+		}
+		if bits > n {
+			n = bits
+		}
+	}
+	return n
+}
@@ -0,0 +1,193 @@
+package swf
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeBytes(t *testing.T, f func(w Writer) error) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := f(w); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewWriter(t *testing.T) {
+	if writer := NewWriter(&bytes.Buffer{}); writer == nil {
+		t.Error("expected non-nil, got nil")
+	}
+}
+
+func TestWriteBits(t *testing.T) {
+	b := writeBytes(t, func(w Writer) error {
+		if err := w.WriteBits(2, 3); err != nil {
+			return err
+		}
+		return w.WriteBits(0x1f, 5)
+	})
+	if !bytes.Equal(b, []byte{0x5f}) {
+		t.Errorf("expected [0x5f], got %#v", b)
+	}
+
+	b = writeBytes(t, func(w Writer) error { return w.WriteBits(1, 1) })
+	if !bytes.Equal(b, []byte{0x80}) {
+		t.Errorf("expected [0x80], got %#v", b)
+	}
+}
+
+func TestWriteIntegers(t *testing.T) {
+	b := writeBytes(t, func(w Writer) error {
+		if err := w.WriteBits(1, 1); err != nil {
+			return err
+		}
+		if err := w.WriteInt8(0x03); err != nil {
+			return err
+		}
+		if err := w.WriteInt16(0x7203); err != nil {
+			return err
+		}
+		if err := w.WriteInt32(0x04127203); err != nil {
+			return err
+		}
+		if err := w.WriteUInt8(0xf3); err != nil {
+			return err
+		}
+		if err := w.WriteUInt16(0xf203); err != nil {
+			return err
+		}
+		return w.WriteUInt32(0xf4127203)
+	})
+	expected := []byte{
+		0x80,
+		0x03,
+		0x03, 0x72,
+		0x03, 0x72, 0x12, 0x04,
+		0xf3,
+		0x03, 0xf2,
+		0x03, 0x72, 0x12, 0xf4,
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("expected %#v, got %#v", expected, b)
+	}
+}
+
+func TestWriteEUInt32(t *testing.T) {
+	values := []uint32{0x5f, 0x448a, 0, 0xffffffff}
+	b := writeBytes(t, func(w Writer) error {
+		for _, v := range values {
+			if err := w.WriteEUInt32(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	expected := []byte{0x5f, 0x8a, 0x89, 0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0x0f}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("expected %#v, got %#v", expected, b)
+	}
+
+	reader := NewReader(bytes.NewReader(b))
+	for _, v := range values[:3] {
+		if got, err := reader.ReadEUInt32(); err != nil || got != v {
+			t.Errorf("expected %#x, got %#x (%v)", v, got, err)
+		}
+	}
+}
+
+func TestWriteUBitValue(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{})
+	if err := w.WriteUBitValue(1, 33); err == nil || !strings.Contains(err.Error(), "1-32 bits") {
+		t.Errorf("expected containing '1-32 bits', got %v", err)
+	}
+	if err := w.WriteUBitValue(1, 0); err == nil || !strings.Contains(err.Error(), "1-32 bits") {
+		t.Errorf("expected containing '1-32 bits', got %v", err)
+	}
+	if err := w.WriteUBitValue(8, 3); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("expected containing 'overflows', got %v", err)
+	}
+
+	b := writeBytes(t, func(w Writer) error {
+		if err := w.WriteUBitValue(19, 5); err != nil {
+			return err
+		}
+		return w.WriteUBitValue(2, 3)
+	})
+	if !bytes.Equal(b, []byte{0x9a}) {
+		t.Errorf("expected [0x9a], got %#v", b)
+	}
+}
+
+func TestWriteBitValue(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{})
+	if err := w.WriteBitValue(1, 33); err == nil || !strings.Contains(err.Error(), "1-32 bits") {
+		t.Errorf("expected containing '1-32 bits', got %v", err)
+	}
+	if err := w.WriteBitValue(4, 3); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("expected containing 'overflows', got %v", err)
+	}
+	if err := w.WriteBitValue(-5, 3); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("expected containing 'overflows', got %v", err)
+	}
+
+	b := writeBytes(t, func(w Writer) error {
+		if err := w.WriteBitValue(-13, 5); err != nil {
+			return err
+		}
+		return w.WriteBitValue(2, 3)
+	})
+	if !bytes.Equal(b, []byte{0x9a}) {
+		t.Errorf("expected [0x9a], got %#v", b)
+	}
+}
+
+func TestWriteFixed(t *testing.T) {
+	b := writeBytes(t, func(w Writer) error { return w.WriteFixed(7.5) })
+	if !bytes.Equal(b, []byte{0x00, 0x80, 0x07, 0x00}) {
+		t.Errorf("expected [0x00 0x80 0x07 0x00], got %#v", b)
+	}
+}
+
+func TestWriteFixed8(t *testing.T) {
+	b := writeBytes(t, func(w Writer) error {
+		if err := w.WriteFixed8(9.5); err != nil {
+			return err
+		}
+		return w.WriteFixed8(-9.5)
+	})
+	if !bytes.Equal(b, []byte{0x80, 0x09, 0x80, 0xf7}) {
+		t.Errorf("expected [0x80 0x09 0x80 0xf7], got %#v", b)
+	}
+}
+
+func TestWriteString(t *testing.T) {
+	b := writeBytes(t, func(w Writer) error { return w.WriteString("ABC") })
+	if !bytes.Equal(b, []byte{'A', 'B', 'C', 0x0}) {
+		t.Errorf("expected 'ABC\\x00', got %#v", b)
+	}
+}
+
+func TestWriteRect(t *testing.T) {
+	rect := Rect{16, 0, 25600, 0, 20480}
+	b := writeBytes(t, func(w Writer) error { return w.WriteRect(rect) })
+	expected := []byte{0x80, 0x00, 0x03, 0x20, 0x00, 0x00, 0x02, 0x80, 0x00}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("expected %#v, got %#v", expected, b)
+	}
+
+	// NBits is too small to hold the coordinates
+	b = writeBytes(t, func(w Writer) error { return w.WriteRect(Rect{2, -1, 300, 0, 20}) })
+	got, err := newParser(bytes.NewReader(b)).ParseRect()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if correct := (Rect{10, -1, 300, 0, 20}); !reflect.DeepEqual(got, correct) {
+		t.Errorf("expected %v, got %v", correct, got)
+	}
+}